@@ -3,17 +3,44 @@ package config
 import (
 	"fmt"
 	"github.com/ilyakaznacheev/cleanenv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	HTTP HTTP
-	Log  Log
-	PG   PG
-	JWT  JWT
+	HTTP      HTTP
+	Public    Public
+	GRPC      GRPC
+	Log       Log
+	PG        PG
+	JWT       JWT
+	Operator  Operator
+	RateLimit RateLimit
+	GraphQL   GraphQL
+	Webhooks  Webhooks
+	Outbox    Outbox
+	Cache     Cache
+	HTTPCache HTTPCache
+	TxRetry   TxRetry
+	Tracing   Tracing
+	Health    Health
 }
 
 type HTTP struct {
 	Port string `env-required:"true" env:"HTTP_PORT"`
+	// служебный порт: /metrics; наружу не публикуется
+	AdminPort string `env:"HTTP_ADMIN_PORT" env-default:"9100"`
+}
+
+// Public - адрес, по которому сервис доступен снаружи; из него строятся ссылки в sitemap и лентах
+type Public struct {
+	BaseURL string `env:"PUBLIC_BASE_URL" env-default:"http://localhost:8000"`
+	// NewsURL - шаблон публичной страницы новости, {id} заменяется id; путь без хоста строится от BaseURL
+	NewsURL string `env:"PUBLIC_NEWS_URL" env-default:"/news/{id}"`
+}
+
+type GRPC struct {
+	Port string `env:"GRPC_PORT" env-default:"9000"`
 }
 
 type Log struct {
@@ -23,16 +50,115 @@ type Log struct {
 
 type PG struct {
 	Url string `env-required:"true" env:"PG_URL"`
+	// пул; 0 - значение по умолчанию (PG_MAX_CONNS - число CPU)
+	MinConns          int32         `env:"PG_MIN_CONNS" env-default:"0"`
+	MaxConns          int32         `env:"PG_MAX_CONNS" env-default:"0"`
+	MaxConnLifetime   time.Duration `env:"PG_MAX_CONN_LIFETIME" env-default:"1h"`
+	MaxConnIdleTime   time.Duration `env:"PG_MAX_CONN_IDLE_TIME" env-default:"30m"`
+	HealthCheckPeriod time.Duration `env:"PG_HEALTH_CHECK_PERIOD" env-default:"1m"`
+	ConnAttempts      int           `env:"PG_CONN_ATTEMPTS" env-default:"10"`
+	ConnTimeout       time.Duration `env:"PG_CONN_TIMEOUT" env-default:"2s"`
+	// 0 - без ограничения
+	StatementTimeout time.Duration `env:"PG_STATEMENT_TIMEOUT" env-default:"30s"`
+	// запросы дольше пишутся в лог; 0 - не писать
+	SlowQueryThreshold time.Duration `env:"PG_SLOW_QUERY_THRESHOLD" env-default:"200ms"`
+	// реплики для чтения через запятую; пусто - все запросы в primary
+	ReplicaUrls          []string      `env:"PG_REPLICA_URLS" env-separator:","`
+	ReplicaMaxLag        time.Duration `env:"PG_REPLICA_MAX_LAG" env-default:"5s"`
+	ReplicaCheckInterval time.Duration `env:"PG_REPLICA_CHECK_INTERVAL" env-default:"5s"`
 }
 
 type JWT struct {
 	Key string `env-required:"true" env:"JWT_KEY"`
 }
 
+// Operator - ключ оператора (заголовок X-API-Key) со всеми scope, включая admin; пусто - выключен
+type Operator struct {
+	Key string `env:"OPERATOR_API_KEY"`
+}
+
+type RateLimit struct {
+	Store string `env:"RATE_LIMIT_STORE" env-default:"memory"`
+	Auth  Limit  `env-prefix:"RATE_LIMIT_AUTH_"`
+	News  Limit  `env-prefix:"RATE_LIMIT_NEWS_"`
+	Admin Limit  `env-prefix:"RATE_LIMIT_ADMIN_"`
+}
+
+type Limit struct {
+	Rate  float64 `env:"RATE" env-default:"10"`
+	Burst int     `env:"BURST" env-default:"20"`
+}
+
+type GraphQL struct {
+	MaxDepth      int `env:"GRAPHQL_MAX_DEPTH" env-default:"5"`
+	MaxComplexity int `env:"GRAPHQL_MAX_COMPLEXITY" env-default:"500"`
+}
+
+type Webhooks struct {
+	MaxAttempts  int           `env:"WEBHOOK_MAX_ATTEMPTS" env-default:"8"`
+	BackoffBase  time.Duration `env:"WEBHOOK_BACKOFF_BASE" env-default:"30s"`
+	BackoffMax   time.Duration `env:"WEBHOOK_BACKOFF_MAX" env-default:"6h"`
+	PollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" env-default:"5s"`
+	Timeout      time.Duration `env:"WEBHOOK_TIMEOUT" env-default:"10s"`
+	BatchSize    int           `env:"WEBHOOK_BATCH_SIZE" env-default:"20"`
+}
+
+type Outbox struct {
+	// log | memory
+	Publisher    string        `env:"OUTBOX_PUBLISHER" env-default:"log"`
+	PollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" env-default:"1s"`
+	BatchSize    int           `env:"OUTBOX_BATCH_SIZE" env-default:"100"`
+	MaxAttempts  int           `env:"OUTBOX_MAX_ATTEMPTS" env-default:"20"`
+	BackoffBase  time.Duration `env:"OUTBOX_BACKOFF_BASE" env-default:"1s"`
+	BackoffMax   time.Duration `env:"OUTBOX_BACKOFF_MAX" env-default:"10m"`
+	Retention    time.Duration `env:"OUTBOX_RETENTION" env-default:"168h"`
+}
+
+// Cache - кэш чтения новостей; CACHE_SIZE=0 выключает его
+type Cache struct {
+	Size int           `env:"CACHE_SIZE" env-default:"1000"`
+	TTL  time.Duration `env:"CACHE_TTL" env-default:"30s"`
+}
+
+// HTTPCache - Cache-Control по маршрутам; у авторизованных ответов public заменяется на private
+type HTTPCache struct {
+	NewsList string `env:"HTTP_CACHE_NEWS_LIST" env-default:"no-cache"`
+	NewsGet  string `env:"HTTP_CACHE_NEWS_GET" env-default:"no-cache"`
+	Feeds    string `env:"HTTP_CACHE_FEEDS" env-default:"public, max-age=300"`
+	Sitemap  string `env:"HTTP_CACHE_SITEMAP" env-default:"public, max-age=3600"`
+}
+
 func NewConfig() (Config, error) {
 	c := Config{}
 	if err := cleanenv.ReadEnv(&c); err != nil {
 		return Config{}, fmt.Errorf("error reading config env: %w", err)
 	}
+	if !strings.Contains(c.Public.NewsURL, "{id}") {
+		return Config{}, fmt.Errorf("PUBLIC_NEWS_URL must contain {id}: %q", c.Public.NewsURL)
+	}
 	return c, nil
 }
+
+// TxRetry - повтор транзакций при serialization failure и deadlock; TX_RETRY_MAX_ATTEMPTS=1 выключает повторы
+type TxRetry struct {
+	MaxAttempts int           `env:"TX_RETRY_MAX_ATTEMPTS" env-default:"3"`
+	BaseDelay   time.Duration `env:"TX_RETRY_BASE_DELAY" env-default:"10ms"`
+	MaxDelay    time.Duration `env:"TX_RETRY_MAX_DELAY" env-default:"200ms"`
+}
+
+// Tracing - OpenTelemetry; TRACING_EXPORTER: none | stdout | otlp
+type Tracing struct {
+	Exporter    string `env:"TRACING_EXPORTER" env-default:"none"`
+	ServiceName string `env:"TRACING_SERVICE_NAME" env-default:"news"`
+	// host:port OTLP gRPC коллектора; пусто - OTEL_EXPORTER_OTLP_ENDPOINT или localhost:4317
+	Endpoint    string  `env:"TRACING_OTLP_ENDPOINT"`
+	Insecure    bool    `env:"TRACING_OTLP_INSECURE" env-default:"true"`
+	SampleRatio float64 `env:"TRACING_SAMPLE_RATIO" env-default:"1"`
+}
+
+type Health struct {
+	// каждая проверка /readyz не дольше
+	CheckTimeout time.Duration `env:"HEALTH_CHECK_TIMEOUT" env-default:"2s"`
+	// между переходом /readyz в 503 и остановкой сервера: балансировщик успевает снять сервис
+	DrainDelay time.Duration `env:"HEALTH_DRAIN_DELAY" env-default:"5s"`
+}
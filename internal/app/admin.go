@@ -0,0 +1,15 @@
+package app
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// newAdminServer - служебный http сервер на отдельном порту, чтобы метрики не были доступны снаружи
+func newAdminServer(reg *prometheus.Registry) *fiber.App {
+	admin := fiber.New()
+	admin.Get("/metrics", adaptor.HTTPHandler(promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg})))
+	return admin
+}
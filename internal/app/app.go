@@ -1,20 +1,39 @@
 package app
 
 import (
+	"context"
 	"github.com/gofiber/fiber/v3"
 	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
 	"net"
 	"os"
 	"os/signal"
 	"syscall"
 	"test_news/config"
+	grpcserver "test_news/internal/controller/grpc"
+	"test_news/internal/controller/http/feeds"
+	"test_news/internal/controller/http/gql"
+	"test_news/internal/controller/http/middleware"
+	"test_news/internal/controller/http/probes"
 	httpv1 "test_news/internal/controller/http/v1"
+	httpv2 "test_news/internal/controller/http/v2"
+	"test_news/internal/metrics"
 	"test_news/internal/repo"
 	"test_news/internal/repo/txmanager"
 	"test_news/internal/service"
+	"test_news/pkg/events"
 	"test_news/pkg/postgres"
+	"test_news/pkg/ratelimit"
+	"test_news/pkg/tracing"
 	"test_news/pkg/validator"
+	"time"
+)
+
+const (
+	grpcShutdownTimeout    = 5 * time.Second
+	tracingShutdownTimeout = 5 * time.Second
 )
 
 func Run() {
@@ -24,25 +43,153 @@ func Run() {
 	}
 	setLogger(cfg.Log.Level, cfg.Log.Output)
 
+	// TRACING
+	shutdownTracing, err := tracing.New(context.Background(), tracing.Config(cfg.Tracing))
+	if err != nil {
+		log.Fatal().Err(err).Msg("init tracing error")
+	}
+
 	// POSTGRESQL
-	pg, err := postgres.NewPG(cfg.PG.Url)
+	queryTracer := postgres.NewQueryTracer(log.Logger, postgres.TracerConfig{
+		SlowThreshold: cfg.PG.SlowQueryThreshold,
+		// операцией считается метод репозитория, а не обертка над пулом
+		SkipPackages: []string{"test_news/internal/repo/txmanager."},
+	})
+	pg, err := postgres.NewPG(cfg.PG.Url,
+		postgres.WithTracer(queryTracer),
+		postgres.WithPoolConfig(postgres.PoolConfig{
+			MinConns:          cfg.PG.MinConns,
+			MaxConns:          cfg.PG.MaxConns,
+			MaxConnLifetime:   cfg.PG.MaxConnLifetime,
+			MaxConnIdleTime:   cfg.PG.MaxConnIdleTime,
+			HealthCheckPeriod: cfg.PG.HealthCheckPeriod,
+			ConnAttempts:      cfg.PG.ConnAttempts,
+			ConnTimeout:       cfg.PG.ConnTimeout,
+			StatementTimeout:  cfg.PG.StatementTimeout,
+		}),
+		postgres.WithReplicas(postgres.ReplicaConfig{
+			URLs:          cfg.PG.ReplicaUrls,
+			MaxLag:        cfg.PG.ReplicaMaxLag,
+			CheckInterval: cfg.PG.ReplicaCheckInterval,
+		}),
+	)
 	if err != nil {
 		log.Fatal().Err(err).Msg("init postgres error")
 	}
 	defer pg.Close()
 
+	txManager := txmanager.NewManager(pg, txmanager.WithRetry(txmanager.RetryPolicy(cfg.TxRetry)))
 	d := &service.ServicesDependencies{
 		NewsRepo:       repo.NewNewsRepo(),
 		CategoriesRepo: repo.NewCategoriesRepo(),
-		TxManager:      txmanager.NewManager(pg),
+		APIKeysRepo:    repo.NewAPIKeysRepo(),
+		AuditRepo:      repo.NewAuditRepo(),
+		NewsEventsRepo: repo.NewNewsEventsRepo(pg),
+		WebhooksRepo:   repo.NewWebhooksRepo(),
+		OutboxRepo:     repo.NewOutboxRepo(),
+		TxManager:      txManager,
+		Publisher:      newPublisher(cfg.Outbox.Publisher),
 		JWTKey:         cfg.JWT.Key,
+		OperatorKey:    cfg.Operator.Key,
+		Webhooks:       service.WebhookConfig(cfg.Webhooks),
+		Outbox: service.OutboxConfig{
+			PollInterval: cfg.Outbox.PollInterval,
+			BatchSize:    cfg.Outbox.BatchSize,
+			MaxAttempts:  cfg.Outbox.MaxAttempts,
+			BackoffBase:  cfg.Outbox.BackoffBase,
+			BackoffMax:   cfg.Outbox.BackoffMax,
+			Retention:    cfg.Outbox.Retention,
+		},
+		Cache: service.CacheConfig(cfg.Cache),
 	}
 	services := service.NewServices(d)
 
+	// события изменений новостей со всех реплик (LISTEN/NOTIFY) для SSE и gRPC Watch
+	listenCtx, stopListen := context.WithCancel(context.Background())
+	listenDone := make(chan struct{})
+	go func() {
+		defer close(listenDone)
+		if err := services.News.Listen(listenCtx); err != nil {
+			log.Err(err).Msg("news events listen error")
+		}
+	}()
+
+	// доставка webhook'ов из outbox; незавершенная попытка повторится после lease
+	webhooksDone := make(chan struct{})
+	go func() {
+		defer close(webhooksDone)
+		if err := services.Webhooks.Run(listenCtx); err != nil {
+			log.Err(err).Msg("webhooks worker error")
+		}
+	}()
+
+	// публикация событий изменений из outbox
+	outboxDone := make(chan struct{})
+	go func() {
+		defer close(outboxDone)
+		if err := services.Outbox.Run(listenCtx); err != nil {
+			log.Err(err).Msg("outbox relay error")
+		}
+	}()
+
+	checker, err := newHealthChecker(cfg.Health.CheckTimeout, pg, map[string]<-chan struct{}{
+		"news_listener":   listenDone,
+		"webhooks_worker": webhooksDone,
+		"outbox_relay":    outboxDone,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("init health checker error")
+	}
+
 	h := fiber.New(fiber.Config{
 		StructValidator: validator.New(),
 	})
-	httpv1.NewRouter(h, services)
+	h.Use(middleware.Tracing, middleware.RequestID, middleware.AccessLog, middleware.Metrics, middleware.ReadYourWrites)
+	probes.NewRouter(h, checker)
+	limiter := newRateLimitStore(cfg.RateLimit.Store, pg)
+	httpv1.NewRouter(h, services,
+		httpv1.WithRateLimit(limiter, httpv1.RateLimits{
+			Auth:  ratelimit.Limit(cfg.RateLimit.Auth),
+			News:  ratelimit.Limit(cfg.RateLimit.News),
+			Admin: ratelimit.Limit(cfg.RateLimit.Admin),
+		}),
+		httpv1.WithOpenAPIRoutes(probes.OpenAPIRoutes()...),
+		httpv1.WithOpenAPIRoutes(httpv2.OpenAPIRoutes()...),
+		httpv1.WithOpenAPIRoutes(gql.OpenAPIRoutes()...),
+		httpv1.WithOpenAPIRoutes(feeds.OpenAPIRoutes()...),
+		httpv1.WithCachePolicies(httpv1.CachePolicies{NewsList: cfg.HTTPCache.NewsList}),
+	)
+	httpv2.NewRouter(h, services,
+		httpv2.WithRateLimit(limiter, ratelimit.Limit(cfg.RateLimit.News)),
+		httpv2.WithCachePolicy(cfg.HTTPCache.NewsGet),
+	)
+	gql.NewRouter(h, services,
+		gql.WithRateLimit(limiter, ratelimit.Limit(cfg.RateLimit.News)),
+		gql.WithLimits(cfg.GraphQL.MaxDepth, cfg.GraphQL.MaxComplexity),
+	)
+	feeds.NewRouter(h, services,
+		feeds.WithRateLimit(limiter, ratelimit.Limit(cfg.RateLimit.News)),
+		feeds.WithBaseURL(cfg.Public.BaseURL),
+		feeds.WithNewsURL(cfg.Public.NewsURL),
+		feeds.WithCachePolicies(feeds.CachePolicies{Feeds: cfg.HTTPCache.Feeds, Sitemap: cfg.HTTPCache.Sitemap}),
+	)
+
+	// METRICS
+	collectors := []prometheus.Collector{
+		metrics.NewPostgresCollector(pg, queryTracer),
+		metrics.NewTxCollector(txManager),
+	}
+	if services.Cache != nil {
+		collectors = append(collectors, metrics.NewCacheCollector(services.Cache))
+	}
+	admin := newAdminServer(metrics.NewRegistry(collectors...))
+
+	// GRPC
+	grpcServer := grpcserver.NewServer(services)
+	lis, err := net.Listen("tcp", net.JoinHostPort("", cfg.GRPC.Port))
+	if err != nil {
+		log.Fatal().Err(err).Msg("grpc listen error")
+	}
 
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
@@ -52,22 +199,89 @@ func Run() {
 		handlerCh <- h.Listen(net.JoinHostPort("", cfg.HTTP.Port))
 	}()
 
-	log.Info().Msgf("app started, listen port %s", cfg.HTTP.Port)
+	adminCh := make(chan error, 1)
+	go func() {
+		adminCh <- admin.Listen(net.JoinHostPort("", cfg.HTTP.AdminPort), fiber.ListenConfig{DisableStartupMessage: true})
+	}()
+
+	grpcCh := make(chan error, 1)
+	go func() {
+		grpcCh <- grpcServer.Serve(lis)
+	}()
+
+	log.Info().Msgf("app started, listen port %s, grpc port %s, admin port %s", cfg.HTTP.Port, cfg.GRPC.Port, cfg.HTTP.AdminPort)
 
 	select {
 	case s := <-interrupt:
 		log.Info().Msgf("app signal %s", s.String())
 	case err = <-handlerCh:
 		log.Err(err).Msg("http server error")
+	case err = <-adminCh:
+		log.Err(err).Msg("admin http server error")
+	case err = <-grpcCh:
+		log.Err(err).Msg("grpc server error")
 	}
 
+	// сначала /readyz отвечает 503, и только после того, как балансировщик это заметит, сервер
+	// перестает принимать соединения: запросы не попадают на уже остановленный экземпляр
+	checker.Drain()
+	log.Info().Msgf("app draining for %s", cfg.Health.DrainDelay)
+	time.Sleep(cfg.Health.DrainDelay)
+
+	// открытые SSE потоки и Watch стримы завершаются вместе с подпиской на события
+	stopListen()
+	<-listenDone
+	<-webhooksDone
+	<-outboxDone
+
 	if err = h.Shutdown(); err != nil {
 		log.Err(err).Msg("http server shutdown error")
 	}
+	if err = admin.Shutdown(); err != nil {
+		log.Err(err).Msg("admin http server shutdown error")
+	}
+	stopGRPC(grpcServer)
+
+	// span'ы последних запросов еще в буфере экспортера
+	tracingCtx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	if err = shutdownTracing(tracingCtx); err != nil {
+		log.Err(err).Msg("tracing shutdown error")
+	}
 
 	log.Info().Msg("app shutdown with exit code 0")
 }
 
+// Watch стримы не завершаются сами, поэтому после таймаута соединения рвутся принудительно
+func stopGRPC(s *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(grpcShutdownTimeout):
+		log.Warn().Msg("grpc graceful stop timeout, force stop")
+		s.Stop()
+	}
+}
+
+func newRateLimitStore(store string, pg postgres.Postgres) ratelimit.Store {
+	if store == "postgres" {
+		return ratelimit.NewPostgresStore(pg)
+	}
+	return ratelimit.NewMemoryStore()
+}
+
+func newPublisher(publisher string) events.Publisher {
+	if publisher == "memory" {
+		return events.NewMemoryPublisher()
+	}
+	return events.NewLogPublisher(log.Logger)
+}
+
 func init() {
 	if _, ok := os.LookupEnv("HTTP_PORT"); !ok {
 		if err := godotenv.Load(); err != nil {
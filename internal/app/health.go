@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang-migrate/migrate/v4/source"
+	"os"
+	"test_news/pkg/health"
+	"test_news/pkg/postgres"
+	"time"
+)
+
+var (
+	errWorkerStopped    = errors.New("worker stopped")
+	errReplicaUnhealthy = errors.New("replica unavailable or lagging, reads go to primary")
+)
+
+// newHealthChecker - зависимости, без которых сервис не может отвечать: primary, схема БД не старее
+// миграций из образа и фоновые воркеры (закрытый done - воркер завершился). Реплики только отражаются в отчете
+func newHealthChecker(timeout time.Duration, pg postgres.Postgres, workers map[string]<-chan struct{}) (*health.Checker, error) {
+	expected, err := latestMigration(migrationsDir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations error: %w", err)
+	}
+
+	checker := health.NewChecker(timeout)
+	checker.Add("postgres", func(ctx context.Context) error {
+		return pg.GetPool().Ping(ctx)
+	})
+	checker.Add("migrations", func(ctx context.Context) error {
+		return checkMigrations(ctx, pg, expected)
+	})
+	for _, s := range pg.Stats() {
+		if s.Name == postgres.PoolPrimary {
+			continue
+		}
+		name := s.Name
+		checker.AddOptional("replica "+name, func(context.Context) error {
+			for _, s := range pg.Stats() {
+				if s.Name == name && !s.Healthy {
+					return errReplicaUnhealthy
+				}
+			}
+			return nil
+		})
+	}
+	for name, done := range workers {
+		checker.Add(name, func(context.Context) error {
+			select {
+			case <-done:
+				return errWorkerStopped
+			default:
+				return nil
+			}
+		})
+	}
+	return checker, nil
+}
+
+// checkMigrations - версия схемы из таблицы golang-migrate: не dirty и не меньше последней миграции образа.
+// Версия больше допустима: при выкатке новая версия сервиса могла уже применить свои миграции
+func checkMigrations(ctx context.Context, pg postgres.Postgres, expected uint) error {
+	var (
+		version uint
+		dirty   bool
+	)
+	if err := pg.GetPool().QueryRow(ctx, "select version, dirty from schema_migrations").Scan(&version, &dirty); err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version < expected {
+		return fmt.Errorf("schema version %d, expected %d", version, expected)
+	}
+	return nil
+}
+
+func latestMigration(dir string) (uint, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	var latest uint
+	for _, e := range entries {
+		m, err := source.Parse(e.Name())
+		if err != nil {
+			continue
+		}
+		latest = max(latest, m.Version)
+	}
+	return latest, nil
+}
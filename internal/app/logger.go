@@ -23,4 +23,6 @@ func setLogger(level, output string) {
 		out = file
 	}
 	log.Logger = zerolog.New(out).Level(logLevel).With().Timestamp().Logger()
+	// zerolog.Ctx(ctx) без логгера запроса в ctx (фоновые воркеры) пишет в общий логгер, а не в никуда
+	zerolog.DefaultContextLogger = &log.Logger
 }
@@ -13,6 +13,7 @@ import (
 const (
 	defaultAttempts = 20
 	defaultTimeout  = time.Second
+	migrationsDir   = "migrations"
 )
 
 func init() {
@@ -27,7 +28,7 @@ func init() {
 		m        *migrate.Migrate
 	)
 	for attempts > 0 {
-		m, err = migrate.New("file://migrations", dbUrl)
+		m, err = migrate.New("file://"+migrationsDir, dbUrl)
 		if err == nil {
 			break
 		}
@@ -0,0 +1,126 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net"
+	"strings"
+	"test_news/internal/service"
+	newsv1 "test_news/pkg/pb/news/v1"
+)
+
+const (
+	metadataAuthorization = "authorization"
+	metadataAPIKey        = "x-api-key"
+)
+
+// методы без записи в таблице требуют только аутентификации
+var methodScopes = map[string]string{
+	newsv1.NewsService_Create_FullMethodName: service.ScopeNewsWrite,
+	newsv1.NewsService_Update_FullMethodName: service.ScopeNewsWrite,
+	newsv1.NewsService_Get_FullMethodName:    service.ScopeNewsRead,
+	newsv1.NewsService_List_FullMethodName:   service.ScopeNewsRead,
+	newsv1.NewsService_Watch_FullMethodName:  service.ScopeNewsRead,
+}
+
+type authInterceptor struct {
+	auth service.Auth
+	keys service.APIKeys
+}
+
+func (a *authInterceptor) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authInterceptor) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// та же логика, что у http middleware.Auth: api ключ проверяется первым, jwt дает только service.JWTScopes
+func (a *authInterceptor) authorize(ctx context.Context, method string) (context.Context, error) {
+	ip := peerIP(ctx)
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var p service.Principal
+	if key := first(md, metadataAPIKey); key != "" {
+		var err error
+		p, err = a.keys.Validate(ctx, key)
+		if err != nil {
+			if errors.Is(err, service.ErrAPIKeyInvalid) {
+				zerolog.Ctx(ctx).Warn().Str("ip", ip).Msg("grpc auth invalid api key")
+				return nil, status.Error(codes.PermissionDenied, codes.PermissionDenied.String())
+			}
+			zerolog.Ctx(ctx).Err(err).Str("ip", ip).Msg("grpc auth validate api key error")
+			return nil, status.Error(codes.Internal, codes.Internal.String())
+		}
+	} else {
+		token, ok := strings.CutPrefix(first(md, metadataAuthorization), "Bearer ")
+		if !ok || token == "" {
+			zerolog.Ctx(ctx).Warn().Str("ip", ip).Msg("grpc auth unauthenticated access")
+			return nil, status.Error(codes.Unauthenticated, codes.Unauthenticated.String())
+		}
+		subject, ok := a.auth.Validate(token)
+		if !ok {
+			zerolog.Ctx(ctx).Warn().Str("ip", ip).Msg("grpc auth invalid token")
+			return nil, status.Error(codes.PermissionDenied, codes.PermissionDenied.String())
+		}
+		p = service.Principal{Scopes: service.JWTScopes}
+		if subject != "" {
+			p.Subject = "jwt:" + subject
+		}
+	}
+
+	if scope, ok := methodScopes[method]; ok && !p.HasScope(scope) {
+		zerolog.Ctx(ctx).Warn().Str("ip", ip).Str("scope", scope).Msg("grpc auth access denied")
+		return nil, status.Error(codes.PermissionDenied, codes.PermissionDenied.String())
+	}
+
+	return service.ContextWithActor(ctx, service.Actor{
+		Subject: p.Subject,
+		IP:      ip,
+		Route:   method,
+	}), nil
+}
+
+// serverStream подменяет контекст стрима, чтобы обработчик видел actor и логгер запроса
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) != 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
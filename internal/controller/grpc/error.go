@@ -0,0 +1,27 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"test_news/internal/service"
+)
+
+// toStatus повторяет http middleware.Error: ошибки сервиса отдаются клиенту, остальные скрываются
+func toStatus(ctx context.Context, op string, err error) error {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return status.Error(codes.NotFound, service.ClientMessage(err))
+
+	case errors.Is(err, service.ErrAlreadyExists):
+		return status.Error(codes.AlreadyExists, service.ClientMessage(err))
+
+	case errors.Is(err, service.ErrInvalidInput):
+		return status.Error(codes.InvalidArgument, service.ClientMessage(err))
+	}
+
+	zerolog.Ctx(ctx).Err(err).Str("method", op).Msg("grpc handler error")
+	return status.Error(codes.Internal, codes.Internal.String())
+}
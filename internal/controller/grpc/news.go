@@ -0,0 +1,146 @@
+package grpc
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	newsv1 "test_news/pkg/pb/news/v1"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 20
+)
+
+type newsServer struct {
+	newsv1.UnimplementedNewsServiceServer
+	news service.News
+}
+
+func newNewsServer(news service.News) *newsServer {
+	return &newsServer{
+		news: news,
+	}
+}
+
+func (s *newsServer) Create(ctx context.Context, req *newsv1.CreateRequest) (*newsv1.CreateResponse, error) {
+	const op = "grpc.news.Create"
+
+	if req.GetTitle() == "" || req.GetContent() == "" || !validCategories(req.GetCategories()) {
+		return nil, status.Error(codes.InvalidArgument, "title and content are required, categories must be positive")
+	}
+
+	id, err := s.news.Create(ctx, model.News{
+		Title:      req.GetTitle(),
+		Content:    req.GetContent(),
+		Categories: req.GetCategories(),
+	})
+	if err != nil {
+		return nil, toStatus(ctx, op, err)
+	}
+	return &newsv1.CreateResponse{Id: id}, nil
+}
+
+func (s *newsServer) Update(ctx context.Context, req *newsv1.UpdateRequest) (*newsv1.UpdateResponse, error) {
+	const op = "grpc.news.Update"
+
+	if (req.Title != nil && req.GetTitle() == "") || (req.Content != nil && req.GetContent() == "") || !validCategories(req.GetCategories()) {
+		return nil, status.Error(codes.InvalidArgument, "title and content cannot be empty, categories must be positive")
+	}
+
+	err := s.news.Update(ctx, service.NewsUpdate{
+		Id:                req.GetId(),
+		Title:             req.Title,
+		Content:           req.Content,
+		Categories:        req.GetCategories(),
+		ReplaceCategories: req.GetReplaceCategories(),
+	})
+	if err != nil {
+		return nil, toStatus(ctx, op, err)
+	}
+	return &newsv1.UpdateResponse{}, nil
+}
+
+func (s *newsServer) Get(ctx context.Context, req *newsv1.GetRequest) (*newsv1.GetResponse, error) {
+	const op = "grpc.news.Get"
+
+	news, err := s.news.Get(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(ctx, op, err)
+	}
+	return &newsv1.GetResponse{News: toProtoNews(news)}, nil
+}
+
+func (s *newsServer) List(ctx context.Context, req *newsv1.ListRequest) (*newsv1.ListResponse, error) {
+	const op = "grpc.news.List"
+
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultListLimit
+	}
+	if limit < 0 || limit > maxListLimit || req.GetOffset() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be in [0, %d], offset must be non-negative", maxListLimit)
+	}
+
+	news, err := s.news.FindWithCategories(ctx, limit, int(req.GetOffset()))
+	if err != nil {
+		return nil, toStatus(ctx, op, err)
+	}
+	resp := &newsv1.ListResponse{News: make([]*newsv1.News, 0, len(news))}
+	for _, n := range news {
+		resp.News = append(resp.News, toProtoNews(n))
+	}
+	return resp, nil
+}
+
+func (s *newsServer) Watch(_ *newsv1.WatchRequest, stream grpc.ServerStreamingServer[newsv1.WatchResponse]) error {
+	ctx := stream.Context()
+	events := s.news.Watch(ctx, 0)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return status.Error(codes.ResourceExhausted, "subscriber is too slow, resubscribe")
+			}
+			err := stream.Send(&newsv1.WatchResponse{
+				Type: eventTypes[event.Type],
+				News: toProtoNews(event.News),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+var eventTypes = map[string]newsv1.EventType{
+	service.NewsEventCreated: newsv1.EventType_EVENT_TYPE_CREATED,
+	service.NewsEventUpdated: newsv1.EventType_EVENT_TYPE_UPDATED,
+	service.NewsEventDeleted: newsv1.EventType_EVENT_TYPE_DELETED,
+}
+
+func toProtoNews(news model.News) *newsv1.News {
+	return &newsv1.News{
+		Id:         news.Id,
+		Title:      news.Title,
+		Content:    news.Content,
+		Categories: news.Categories,
+	}
+}
+
+func validCategories(categories []int64) bool {
+	for _, c := range categories {
+		if c <= 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,271 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"test_news/internal/mocks/servicemocks"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	newsv1 "test_news/pkg/pb/news/v1"
+	"testing"
+)
+
+func newTestClient(t *testing.T, services *service.Services) newsv1.NewsServiceClient {
+	lis := bufconn.Listen(1024 * 1024)
+
+	s := NewServer(services)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return newsv1.NewNewsServiceClient(conn)
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), metadataAuthorization, "Bearer "+token)
+}
+
+func TestNewsServer_Create(t *testing.T) {
+	type mockBehaviour func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews)
+
+	testCases := []struct {
+		testName      string
+		mockBehaviour mockBehaviour
+		ctx           context.Context
+		input         *newsv1.CreateRequest
+		expectOutput  *newsv1.CreateResponse
+		expectCode    codes.Code
+	}{
+		{
+			testName: "correct test",
+			mockBehaviour: func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews) {
+				a.EXPECT().Validate("TOKEN").Return("subject", true)
+				n.EXPECT().Create(gomock.Any(), model.News{
+					Title:      "hello world",
+					Content:    "my content",
+					Categories: []int64{1, 2},
+				}).Return(int64(1), nil)
+			},
+			ctx: withToken("TOKEN"),
+			input: &newsv1.CreateRequest{
+				Title:      "hello world",
+				Content:    "my content",
+				Categories: []int64{1, 2},
+			},
+			expectOutput: &newsv1.CreateResponse{Id: 1},
+			expectCode:   codes.OK,
+		},
+		{
+			testName: "missing title",
+			mockBehaviour: func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews) {
+				a.EXPECT().Validate("TOKEN").Return("subject", true)
+			},
+			ctx:        withToken("TOKEN"),
+			input:      &newsv1.CreateRequest{Content: "my content"},
+			expectCode: codes.InvalidArgument,
+		},
+		{
+			testName: "categories already exists",
+			mockBehaviour: func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews) {
+				a.EXPECT().Validate("TOKEN").Return("subject", true)
+				n.EXPECT().Create(gomock.Any(), gomock.Any()).Return(int64(0), service.ErrCategoriesAlreadyExists)
+			},
+			ctx: withToken("TOKEN"),
+			input: &newsv1.CreateRequest{
+				Title:      "hello world",
+				Content:    "my content",
+				Categories: []int64{1, 1},
+			},
+			expectCode: codes.AlreadyExists,
+		},
+		{
+			testName:      "missing token",
+			mockBehaviour: func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews) {},
+			ctx:           context.Background(),
+			input:         &newsv1.CreateRequest{Title: "hello world", Content: "my content"},
+			expectCode:    codes.Unauthenticated,
+		},
+		{
+			testName: "invalid token",
+			mockBehaviour: func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews) {
+				a.EXPECT().Validate("FOOBAR").Return("", false)
+			},
+			ctx:        withToken("FOOBAR"),
+			input:      &newsv1.CreateRequest{Title: "hello world", Content: "my content"},
+			expectCode: codes.PermissionDenied,
+		},
+		{
+			testName: "api key without write scope",
+			mockBehaviour: func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews) {
+				k.EXPECT().Validate(gomock.Any(), "BOT KEY").Return(service.Principal{
+					Subject: "apikey:2",
+					Scopes:  []string{service.ScopeNewsRead},
+				}, nil)
+			},
+			ctx:        metadata.AppendToOutgoingContext(context.Background(), metadataAPIKey, "BOT KEY"),
+			input:      &newsv1.CreateRequest{Title: "hello world", Content: "my content"},
+			expectCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			a := servicemocks.NewMockAuth(ctrl)
+			k := servicemocks.NewMockAPIKeys(ctrl)
+			n := servicemocks.NewMockNews(ctrl)
+
+			tc.mockBehaviour(a, k, n)
+
+			client := newTestClient(t, &service.Services{
+				Auth:    a,
+				APIKeys: k,
+				News:    n,
+			})
+
+			actual, err := client.Create(tc.ctx, tc.input)
+
+			assert.Equal(t, tc.expectCode, status.Code(err))
+			assert.Equal(t, tc.expectOutput.GetId(), actual.GetId())
+		})
+	}
+}
+
+func TestNewsServer_Get(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	a := servicemocks.NewMockAuth(ctrl)
+	n := servicemocks.NewMockNews(ctrl)
+
+	a.EXPECT().Validate("TOKEN").Return("subject", true).Times(2)
+	n.EXPECT().Get(gomock.Any(), int64(1)).Return(model.News{
+		Id:         1,
+		Title:      "hello world",
+		Content:    "my content",
+		Categories: []int64{1},
+	}, nil)
+	n.EXPECT().Get(gomock.Any(), int64(2)).Return(model.News{}, service.ErrNewsNotFound)
+
+	client := newTestClient(t, &service.Services{
+		Auth: a,
+		News: n,
+	})
+
+	resp, err := client.Get(withToken("TOKEN"), &newsv1.GetRequest{Id: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", resp.GetNews().GetTitle())
+	assert.Equal(t, []int64{1}, resp.GetNews().GetCategories())
+
+	_, err = client.Get(withToken("TOKEN"), &newsv1.GetRequest{Id: 2})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestNewsServer_Update(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	a := servicemocks.NewMockAuth(ctrl)
+	n := servicemocks.NewMockNews(ctrl)
+
+	a.EXPECT().Validate("TOKEN").Return("subject", true)
+	n.EXPECT().Update(gomock.Any(), service.NewsUpdate{
+		Id:    2,
+		Title: ptr("hello world"),
+	}).Return(fmt.Errorf("txmanager.TxFunc exec func error: %w", service.ErrNewsNotFound))
+
+	client := newTestClient(t, &service.Services{
+		Auth: a,
+		News: n,
+	})
+
+	_, err := client.Update(withToken("TOKEN"), &newsv1.UpdateRequest{Id: 2, Title: ptr("hello world")})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, service.ErrNewsNotFound.Error(), status.Convert(err).Message())
+}
+
+func TestNewsServer_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	a := servicemocks.NewMockAuth(ctrl)
+	n := servicemocks.NewMockNews(ctrl)
+
+	a.EXPECT().Validate("TOKEN").Return("subject", true).Times(2)
+	n.EXPECT().FindWithCategories(gomock.Any(), defaultListLimit, 0).Return([]model.News{
+		{Id: 1, Title: "Title 1"},
+		{Id: 2, Title: "Title 2"},
+	}, nil)
+
+	client := newTestClient(t, &service.Services{
+		Auth: a,
+		News: n,
+	})
+
+	resp, err := client.List(withToken("TOKEN"), &newsv1.ListRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, resp.GetNews(), 2)
+
+	_, err = client.List(withToken("TOKEN"), &newsv1.ListRequest{Limit: maxListLimit + 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestNewsServer_Watch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	a := servicemocks.NewMockAuth(ctrl)
+	n := servicemocks.NewMockNews(ctrl)
+
+	events := make(chan service.NewsEvent, 2)
+	events <- service.NewsEvent{Type: service.NewsEventCreated, News: model.News{Id: 1, Title: "created"}}
+	events <- service.NewsEvent{Type: service.NewsEventDeleted, News: model.News{Id: 1, Title: "created"}}
+	close(events)
+
+	a.EXPECT().Validate("TOKEN").Return("subject", true)
+	n.EXPECT().Watch(gomock.Any(), int64(0)).Return(events)
+
+	client := newTestClient(t, &service.Services{
+		Auth: a,
+		News: n,
+	})
+
+	stream, err := client.Watch(withToken("TOKEN"), &newsv1.WatchRequest{})
+	assert.NoError(t, err)
+
+	resp, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, newsv1.EventType_EVENT_TYPE_CREATED, resp.GetType())
+	assert.Equal(t, int64(1), resp.GetNews().GetId())
+
+	resp, err = stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, newsv1.EventType_EVENT_TYPE_DELETED, resp.GetType())
+
+	// канал закрыт без отмены контекста - подписчик отстал
+	_, err = stream.Recv()
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func ptr[T any](t T) *T {
+	return &t
+}
@@ -0,0 +1,55 @@
+package grpc
+
+import (
+	"context"
+	"crypto/rand"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	metadataRequestID = "x-request-id"
+	// входящий x-request-id длиннее или с непечатаемыми символами заменяется новым: он попадает в логи
+	maxRequestIDLength = 128
+)
+
+// requestIDUnary - аналог http middleware.RequestID: кладет в ctx логгер с request_id,
+// zerolog.Ctx(ctx) в интерцепторах, обработчиках и сервисах пишет с тем же id
+func requestIDUnary(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(withRequestID(ctx), req)
+}
+
+func requestIDStream(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &serverStream{ServerStream: ss, ctx: withRequestID(ss.Context())})
+}
+
+// withRequestID берет id из метаданных или генерирует новый и возвращает его в заголовках ответа
+func withRequestID(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	id := first(md, metadataRequestID)
+	if !validRequestID(id) {
+		id = rand.Text()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, id))
+
+	lc := log.Logger.With().Str("request_id", id)
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		lc = lc.Str("trace_id", sc.TraceID().String())
+	}
+	logger := lc.Logger()
+	return logger.WithContext(ctx)
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
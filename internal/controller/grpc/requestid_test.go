@@ -0,0 +1,132 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+	"test_news/internal/mocks/servicemocks"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	newsv1 "test_news/pkg/pb/news/v1"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	prev := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { log.Logger = prev })
+
+	ctrl := gomock.NewController(t)
+
+	a := servicemocks.NewMockAuth(ctrl)
+	n := servicemocks.NewMockNews(ctrl)
+
+	a.EXPECT().Validate("TOKEN").Return("subject", true).AnyTimes()
+	n.EXPECT().Get(gomock.Any(), int64(1)).DoAndReturn(func(ctx context.Context, _ int64) (model.News, error) {
+		// логгер из ctx сервиса - логгер вызова
+		zerolog.Ctx(ctx).Info().Msg("from service")
+		return model.News{}, errors.New("db is down")
+	}).Times(3)
+
+	client := newTestClient(t, &service.Services{
+		Auth: a,
+		News: n,
+	})
+
+	testCases := []struct {
+		testName  string
+		requestId string
+		expectId  string
+	}{
+		{
+			testName:  "incoming id",
+			requestId: "req-1",
+			expectId:  "req-1",
+		},
+		{
+			testName:  "generated id",
+			requestId: "",
+		},
+		{
+			testName:  "invalid incoming id",
+			requestId: strings.Repeat("a", 129),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			buf.Reset()
+
+			ctx := withToken("TOKEN")
+			if tc.requestId != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, metadataRequestID, tc.requestId)
+			}
+			var header metadata.MD
+			_, err := client.Get(ctx, &newsv1.GetRequest{Id: 1}, grpc.Header(&header))
+			assert.Equal(t, codes.Internal, status.Code(err))
+
+			id := first(header, metadataRequestID)
+			if tc.expectId != "" {
+				assert.Equal(t, tc.expectId, id)
+			} else {
+				assert.NotEmpty(t, id)
+				assert.NotEqual(t, tc.requestId, id)
+			}
+
+			// запись сервиса и ошибка обработчика с одним request_id
+			entries := logEntries(t, &buf)
+			if len(entries) != 2 {
+				t.Fatalf("expected 2 log lines, got %d: %s", len(entries), buf.String())
+			}
+			assert.Equal(t, "from service", entries[0]["message"])
+			assert.Equal(t, id, entries[0]["request_id"])
+			assert.Equal(t, "grpc handler error", entries[1]["message"])
+			assert.Equal(t, id, entries[1]["request_id"])
+			assert.Equal(t, "grpc.news.Get", entries[1]["method"])
+		})
+	}
+
+	t.Run("unauthenticated stream", func(t *testing.T) {
+		buf.Reset()
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), metadataRequestID, "req-2")
+		stream, err := client.Watch(ctx, &newsv1.WatchRequest{})
+		assert.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		header, err := stream.Header()
+		assert.NoError(t, err)
+		assert.Equal(t, "req-2", first(header, metadataRequestID))
+
+		entries := logEntries(t, &buf)
+		if len(entries) != 1 {
+			t.Fatalf("expected 1 log line, got %d: %s", len(entries), buf.String())
+		}
+		assert.Equal(t, "grpc auth unauthenticated access", entries[0]["message"])
+		assert.Equal(t, "req-2", entries[0]["request_id"])
+	})
+}
+
+func logEntries(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var entries []map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var entry map[string]any
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,29 @@
+package grpc
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"test_news/internal/repo/txmanager"
+	"test_news/internal/service"
+	newsv1 "test_news/pkg/pb/news/v1"
+)
+
+func NewServer(services *service.Services, opts ...grpc.ServerOption) *grpc.Server {
+	a := &authInterceptor{
+		auth: services.Auth,
+		keys: services.APIKeys,
+	}
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(requestIDUnary, readYourWrites, a.unary),
+		grpc.ChainStreamInterceptor(requestIDStream, a.stream),
+	)
+
+	s := grpc.NewServer(opts...)
+	newsv1.RegisterNewsServiceServer(s, newNewsServer(services.News))
+	return s
+}
+
+// после записи в рамках вызова чтения идут в primary, а не в реплику
+func readYourWrites(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	return handler(txmanager.ReadYourWrites(ctx), req)
+}
@@ -0,0 +1,105 @@
+package feeds
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gofiber/fiber/v3"
+	"strconv"
+	"test_news/internal/controller/http/middleware"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	"test_news/pkg/feed"
+)
+
+// feedLimit - сколько последних новостей попадает в ленту
+const feedLimit = 50
+
+type newsRouter struct {
+	news    service.News
+	baseURL string
+	newsURL string
+}
+
+func newNewsRouter(g fiber.Router, news service.News, baseURL, newsURL string) {
+	r := &newsRouter{
+		news:    news,
+		baseURL: baseURL,
+		newsURL: newsURL,
+	}
+
+	g.Get("/news.rss", r.rss)
+	g.Get("/news.atom", r.atom)
+}
+
+func (r *newsRouter) rss(c fiber.Ctx) error {
+	return r.send(c, feed.RSS, feed.MIMERSS)
+}
+
+func (r *newsRouter) atom(c fiber.Ctx) error {
+	return r.send(c, feed.Atom, feed.MIMEAtom)
+}
+
+func (r *newsRouter) send(c fiber.Ctx, render func(feed.Feed) ([]byte, error), contentType string) error {
+	var categoryId int64
+	if q := c.Query("category"); q != "" {
+		id, err := strconv.ParseInt(q, 10, 64)
+		if err != nil || id <= 0 {
+			return c.SendStatus(fiber.StatusBadRequest)
+		}
+		categoryId = id
+	}
+
+	news, err := r.news.FindLatest(c.Context(), categoryId, feedLimit)
+	if err != nil {
+		return err
+	}
+
+	f := newsFeed(baseURL(c, r.baseURL), r.newsURL, c.OriginalURL(), categoryId, news)
+	body, err := render(f)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	if middleware.Conditional(c, etag, f.Updated) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Send(body)
+}
+
+// ссылки ведут на публичный сайт: маршруты /api требуют авторизации, и читатель ленты получил бы 401
+func newsFeed(baseURL, newsURLTemplate, self string, categoryId int64, news []model.News) feed.Feed {
+	f := feed.Feed{
+		Id:          "urn:test_news:feed:news",
+		Title:       "test_news",
+		Description: "Последние новости",
+		Link:        baseURL + "/",
+		Self:        baseURL + self,
+	}
+	if categoryId != 0 {
+		id := strconv.FormatInt(categoryId, 10)
+		f.Id += ":category:" + id
+		f.Title += ": категория " + id
+		f.Description += " категории " + id
+	}
+
+	for _, n := range news {
+		id := strconv.FormatInt(n.Id, 10)
+		f.Items = append(f.Items, feed.Item{
+			Id:        "urn:test_news:news:" + id,
+			Title:     n.Title,
+			Content:   n.Content,
+			Link:      newsURL(baseURL, newsURLTemplate, n.Id),
+			Published: n.CreatedAt,
+			Updated:   n.UpdatedAt,
+		})
+		if n.UpdatedAt.After(f.Updated) {
+			f.Updated = n.UpdatedAt
+		}
+	}
+	return f
+}
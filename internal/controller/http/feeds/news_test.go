@@ -0,0 +1,200 @@
+package feeds
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"test_news/internal/controller/http/middleware"
+	"test_news/internal/mocks/servicemocks"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	"testing"
+	"time"
+)
+
+func TestNewsRouter(t *testing.T) {
+	type mockBehaviour func(n *servicemocks.MockNews)
+
+	created := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	updated := created.Add(time.Hour)
+	news := []model.News{
+		{Id: 2, Title: "second", Content: "content", CreatedAt: created, UpdatedAt: updated},
+		{Id: 1, Title: "first", Content: "content", CreatedAt: created, UpdatedAt: created},
+	}
+
+	// etag ленты зависит только от содержимого, поэтому его можно получить обычным запросом
+	h := fiber.New()
+	ctrl := gomock.NewController(t)
+	n := servicemocks.NewMockNews(ctrl)
+	n.EXPECT().FindLatest(gomock.Any(), int64(0), feedLimit).Return(news, nil)
+	NewRouter(h, &service.Services{News: n})
+	resp, err := h.Test(httptest.NewRequest(fiber.MethodGet, "/feeds/news.rss", nil))
+	assert.NoError(t, err)
+	etag := resp.Header.Get(fiber.HeaderETag)
+	assert.NotEmpty(t, etag)
+
+	testCases := []struct {
+		testName          string
+		mockBehaviour     mockBehaviour
+		path              string
+		headers           map[string]string
+		expectCode        int
+		expectContentType string
+		expectContains    []string
+	}{
+		{
+			testName: "rss",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().FindLatest(gomock.Any(), int64(0), feedLimit).Return(news, nil)
+			},
+			path:              "/feeds/news.rss",
+			expectCode:        fiber.StatusOK,
+			expectContentType: "application/rss+xml; charset=utf-8",
+			expectContains: []string{
+				`<guid isPermaLink="false">urn:test_news:news:2</guid>`,
+				`<pubDate>Wed, 01 Oct 2025 12:00:00 +0000</pubDate>`,
+				`<link>http://example.com/</link>`,
+				`<link>http://example.com/news/2</link>`,
+				`<lastBuildDate>Wed, 01 Oct 2025 13:00:00 +0000</lastBuildDate>`,
+			},
+		},
+		{
+			testName: "atom by category",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().FindLatest(gomock.Any(), int64(3), feedLimit).Return(news, nil)
+			},
+			path:              "/feeds/news.atom?category=3",
+			expectCode:        fiber.StatusOK,
+			expectContentType: "application/atom+xml; charset=utf-8",
+			expectContains: []string{
+				`<id>urn:test_news:feed:news:category:3</id>`,
+				`<link href="http://example.com/feeds/news.atom?category=3" rel="self" type="application/atom+xml"></link>`,
+				`<entry><id>urn:test_news:news:2</id><title>second</title><updated>2025-10-01T13:00:00Z</updated><published>2025-10-01T12:00:00Z</published>`,
+			},
+		},
+		{
+			testName:      "incorrect category",
+			mockBehaviour: func(n *servicemocks.MockNews) {},
+			path:          "/feeds/news.rss?category=foobar",
+			expectCode:    fiber.StatusBadRequest,
+		},
+		{
+			testName: "if-none-match",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().FindLatest(gomock.Any(), int64(0), feedLimit).Return(news, nil)
+			},
+			path:       "/feeds/news.rss",
+			headers:    map[string]string{fiber.HeaderIfNoneMatch: `"foobar", ` + etag},
+			expectCode: fiber.StatusNotModified,
+		},
+		{
+			testName: "if-none-match changed feed",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().FindLatest(gomock.Any(), int64(0), feedLimit).Return(news, nil)
+			},
+			path: "/feeds/news.rss",
+			headers: map[string]string{
+				fiber.HeaderIfNoneMatch:     `"foobar"`,
+				fiber.HeaderIfModifiedSince: updated.Format(http.TimeFormat),
+			},
+			expectCode:        fiber.StatusOK,
+			expectContentType: "application/rss+xml; charset=utf-8",
+		},
+		{
+			testName: "if-modified-since",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().FindLatest(gomock.Any(), int64(0), feedLimit).Return(news, nil)
+			},
+			path:       "/feeds/news.atom",
+			headers:    map[string]string{fiber.HeaderIfModifiedSince: updated.Format(http.TimeFormat)},
+			expectCode: fiber.StatusNotModified,
+		},
+		{
+			testName: "modified since",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().FindLatest(gomock.Any(), int64(0), feedLimit).Return(news, nil)
+			},
+			path:              "/feeds/news.atom",
+			headers:           map[string]string{fiber.HeaderIfModifiedSince: created.Format(http.TimeFormat)},
+			expectCode:        fiber.StatusOK,
+			expectContentType: "application/atom+xml; charset=utf-8",
+		},
+		{
+			testName: "unexpected error",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().FindLatest(gomock.Any(), int64(0), feedLimit).Return(nil, errors.New("some error"))
+			},
+			path:       "/feeds/news.rss",
+			expectCode: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			n := servicemocks.NewMockNews(ctrl)
+
+			tc.mockBehaviour(n)
+
+			h := fiber.New()
+			NewRouter(h, &service.Services{News: n})
+
+			r := httptest.NewRequest(fiber.MethodGet, tc.path, nil)
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+
+			resp, err := h.Test(r)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectCode, resp.StatusCode)
+			if tc.expectContentType != "" {
+				assert.Equal(t, tc.expectContentType, resp.Header.Get(fiber.HeaderContentType))
+			}
+			if tc.expectCode == fiber.StatusOK || tc.expectCode == fiber.StatusNotModified {
+				assert.NotEmpty(t, resp.Header.Get(fiber.HeaderETag))
+				assert.Equal(t, defaultFeedsCachePolicy, resp.Header.Get(fiber.HeaderCacheControl))
+				assert.Equal(t, updated.Format(http.TimeFormat), resp.Header.Get(fiber.HeaderLastModified))
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			for _, s := range tc.expectContains {
+				assert.True(t, strings.Contains(string(body), s), "body does not contain %s", s)
+			}
+		})
+	}
+}
+
+func TestNewsRouter_cacheControl(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	n := servicemocks.NewMockNews(ctrl)
+	n.EXPECT().FindLatest(gomock.Any(), int64(0), feedLimit).Return(nil, nil).Times(3)
+
+	h := fiber.New()
+	NewRouter(h, &service.Services{News: n}, WithCachePolicies(CachePolicies{Feeds: "public, max-age=300, s-maxage=600"}))
+
+	request := func(headers map[string]string) string {
+		r := httptest.NewRequest(fiber.MethodGet, "/feeds/news.rss", nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		resp, err := h.Test(r)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		return resp.Header.Get(fiber.HeaderCacheControl)
+	}
+
+	assert.Equal(t, "private, max-age=300", request(map[string]string{fiber.HeaderAuthorization: "Bearer TOKEN"}))
+	// ответ авторизованному клиенту не меняет политику следующих анонимных запросов
+	assert.Equal(t, "public, max-age=300, s-maxage=600", request(nil))
+	assert.Equal(t, "private, max-age=300", request(map[string]string{middleware.HeaderAPIKey: "KEY"}))
+}
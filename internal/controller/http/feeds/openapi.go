@@ -0,0 +1,54 @@
+package feeds
+
+import (
+	"net/http"
+	"test_news/pkg/feed"
+	"test_news/pkg/openapi"
+	"test_news/pkg/sitemap"
+)
+
+// feedInput - параметры ленты; send разбирает их вручную, тип нужен только спецификации
+type feedInput struct {
+	Category int64 `query:"category" validate:"omitempty,gt=0"`
+}
+
+// OpenAPIRoutes описывает публичные ленты и sitemap, спецификацию отдает роутер v1
+func OpenAPIRoutes() []openapi.Route {
+	feedErrors := []int{http.StatusBadRequest, http.StatusTooManyRequests, http.StatusInternalServerError}
+	return []openapi.Route{
+		{
+			Method:       http.MethodGet,
+			Path:         "/feeds/news.rss",
+			Summary:      "RSS 2.0 feed of the latest news, optionally of one category",
+			Tags:         []string{"feeds"},
+			Query:        feedInput{},
+			ResponseType: feed.MIMERSS,
+			Errors:       feedErrors,
+		},
+		{
+			Method:       http.MethodGet,
+			Path:         "/feeds/news.atom",
+			Summary:      "Atom feed of the latest news, optionally of one category",
+			Tags:         []string{"feeds"},
+			Query:        feedInput{},
+			ResponseType: feed.MIMEAtom,
+			Errors:       feedErrors,
+		},
+		{
+			Method:       http.MethodGet,
+			Path:         "/sitemap.xml",
+			Summary:      "Sitemap index with a link per chunk of news",
+			Tags:         []string{"feeds"},
+			ResponseType: sitemap.MIMEXML,
+			Errors:       []int{http.StatusTooManyRequests, http.StatusInternalServerError},
+		},
+		{
+			Method:       http.MethodGet,
+			Path:         "/sitemaps/news-:chunk.xml",
+			Summary:      "Sitemap chunk with news urls and their last modification time",
+			Tags:         []string{"feeds"},
+			ResponseType: sitemap.MIMEXML,
+			Errors:       []int{http.StatusNotFound, http.StatusTooManyRequests, http.StatusInternalServerError},
+		},
+	}
+}
@@ -0,0 +1,102 @@
+package feeds
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"strconv"
+	"strings"
+	"test_news/internal/controller/http/middleware"
+	"test_news/internal/service"
+	"test_news/pkg/ratelimit"
+)
+
+const (
+	defaultFeedsCachePolicy   = "public, max-age=300"
+	defaultSitemapCachePolicy = "public, max-age=3600"
+	defaultNewsURL            = "/news/{id}"
+)
+
+// CachePolicies - значения Cache-Control для лент и sitemap
+type CachePolicies struct {
+	Feeds   string
+	Sitemap string
+}
+
+type options struct {
+	limiter ratelimit.Store
+	limit   ratelimit.Limit
+	baseURL string
+	newsURL string
+	cache   CachePolicies
+}
+
+type Option func(o *options)
+
+// WithRateLimit ограничивает частоту опроса лент; ленты публичные, поэтому ключ клиента - ip
+func WithRateLimit(store ratelimit.Store, limit ratelimit.Limit) Option {
+	return func(o *options) {
+		o.limiter = store
+		o.limit = limit
+	}
+}
+
+// WithBaseURL задает публичный адрес для ссылок; без него используется адрес из запроса
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) {
+		o.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithNewsURL задает шаблон публичной ссылки на новость: {id} заменяется id новости,
+// путь без хоста дополняется базовым адресом. Маршруты /api требуют авторизации и для ссылок не подходят
+func WithNewsURL(template string) Option {
+	return func(o *options) {
+		if template != "" {
+			o.newsURL = template
+		}
+	}
+}
+
+func WithCachePolicies(policies CachePolicies) Option {
+	return func(o *options) {
+		o.cache = policies
+	}
+}
+
+func NewRouter(g fiber.Router, services *service.Services, opts ...Option) {
+	o := &options{
+		newsURL: defaultNewsURL,
+		cache: CachePolicies{
+			Feeds:   defaultFeedsCachePolicy,
+			Sitemap: defaultSitemapCachePolicy,
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	rateLimit := middleware.RateLimit(o.limiter, "news", o.limit)
+
+	sitemapCache := middleware.CacheControl(o.cache.Sitemap)
+
+	newNewsRouter(g.Group("/feeds", middleware.Error, rateLimit, middleware.CacheControl(o.cache.Feeds)), services.News, o.baseURL, o.newsURL)
+	newSitemapRouter(
+		g.Group("/sitemap.xml", middleware.Error, rateLimit, sitemapCache),
+		g.Group("/sitemaps", middleware.Error, rateLimit, sitemapCache),
+		services.News, o.baseURL, o.newsURL,
+	)
+}
+
+func baseURL(c fiber.Ctx, configured string) string {
+	if configured != "" {
+		return configured
+	}
+	return c.BaseURL()
+}
+
+func newsURL(baseURL, template string, id int64) string {
+	url := strings.ReplaceAll(template, "{id}", strconv.FormatInt(id, 10))
+	if strings.HasPrefix(url, "/") {
+		return baseURL + url
+	}
+	return url
+}
@@ -0,0 +1,92 @@
+package feeds
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"strconv"
+	"test_news/internal/service"
+	"test_news/pkg/sitemap"
+	"time"
+)
+
+type sitemapRouter struct {
+	news    service.News
+	baseURL string
+	newsURL string
+}
+
+func newSitemapRouter(index, chunks fiber.Router, news service.News, baseURL, newsURL string) {
+	r := &sitemapRouter{
+		news:    news,
+		baseURL: baseURL,
+		newsURL: newsURL,
+	}
+
+	index.Get("", r.index)
+	chunks.Get("/news-:chunk.xml", r.chunk)
+}
+
+// index - индекс sitemap: по ссылке на каждые sitemap.MaxURLs новостей
+func (r *sitemapRouter) index(c fiber.Ctx) error {
+	chunks, err := r.news.SitemapIndex(c.Context())
+	if err != nil {
+		return err
+	}
+	// даже для пустой БД индекс ссылается на одну (пустую) часть
+	if len(chunks) == 0 {
+		chunks = []time.Time{{}}
+	}
+
+	base := baseURL(c, r.baseURL)
+	w, err := sitemap.NewIndex(c.Response().BodyWriter())
+	if err != nil {
+		return err
+	}
+	for i, lastMod := range chunks {
+		err = w.Add(sitemap.URL{
+			Loc:     base + "/sitemaps/news-" + strconv.Itoa(i) + ".xml",
+			LastMod: lastMod,
+		})
+		if err != nil {
+			c.Response().ResetBody()
+			return err
+		}
+	}
+	if err = w.Close(); err != nil {
+		c.Response().ResetBody()
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, sitemap.MIMEXML)
+	return nil
+}
+
+// chunk пишет url новостей в буфер ответа по мере чтения строк из БД: выборка не собирается в слайс,
+// но тело части (до sitemap.MaxURLs url) целиком в памяти. Зато ошибка чтения отдается как 500, а не обрезанный sitemap
+func (r *sitemapRouter) chunk(c fiber.Ctx) error {
+	chunk, err := strconv.Atoi(c.Params("chunk"))
+	if err != nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	base := baseURL(c, r.baseURL)
+	w, err := sitemap.NewURLSet(c.Response().BodyWriter())
+	if err != nil {
+		return err
+	}
+	err = r.news.StreamSitemap(c.Context(), chunk, func(id int64, updatedAt time.Time) error {
+		return w.Add(sitemap.URL{
+			Loc:     newsURL(base, r.newsURL, id),
+			LastMod: updatedAt,
+		})
+	})
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		c.Response().ResetBody()
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, sitemap.MIMEXML)
+	return nil
+}
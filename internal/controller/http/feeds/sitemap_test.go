@@ -0,0 +1,143 @@
+package feeds
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http/httptest"
+	"test_news/internal/mocks/servicemocks"
+	"test_news/internal/service"
+	"testing"
+	"time"
+)
+
+func TestSitemapRouter(t *testing.T) {
+	type mockBehaviour func(n *servicemocks.MockNews)
+
+	updatedAt := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	streamNews := func(ids ...int64) func(_ any, _ int, fn func(int64, time.Time) error) error {
+		return func(_ any, _ int, fn func(int64, time.Time) error) error {
+			for _, id := range ids {
+				if err := fn(id, updatedAt); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	testCases := []struct {
+		testName      string
+		mockBehaviour mockBehaviour
+		options       []Option
+		path          string
+		expectCode    int
+		expectBody    string
+	}{
+		{
+			testName: "index",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().SitemapIndex(gomock.Any()).Return([]time.Time{updatedAt, updatedAt.Add(time.Hour)}, nil)
+			},
+			path:       "/sitemap.xml",
+			expectCode: fiber.StatusOK,
+			expectBody: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` +
+				`<sitemap><loc>https://news.example.com/sitemaps/news-0.xml</loc><lastmod>2025-10-01T12:00:00Z</lastmod></sitemap>` +
+				`<sitemap><loc>https://news.example.com/sitemaps/news-1.xml</loc><lastmod>2025-10-01T13:00:00Z</lastmod></sitemap>` +
+				`</sitemapindex>`,
+		},
+		{
+			testName: "empty index",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().SitemapIndex(gomock.Any()).Return(nil, nil)
+			},
+			path:       "/sitemap.xml",
+			expectCode: fiber.StatusOK,
+			expectBody: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` +
+				`<sitemap><loc>https://news.example.com/sitemaps/news-0.xml</loc></sitemap>` +
+				`</sitemapindex>`,
+		},
+		{
+			testName: "chunk",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().StreamSitemap(gomock.Any(), 1, gomock.Any()).DoAndReturn(streamNews(50001, 50002))
+			},
+			path:       "/sitemaps/news-1.xml",
+			expectCode: fiber.StatusOK,
+			expectBody: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` +
+				`<url><loc>https://news.example.com/news/50001</loc><lastmod>2025-10-01T12:00:00Z</lastmod></url>` +
+				`<url><loc>https://news.example.com/news/50002</loc><lastmod>2025-10-01T12:00:00Z</lastmod></url>` +
+				`</urlset>`,
+		},
+		{
+			testName: "chunk with absolute news url",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().StreamSitemap(gomock.Any(), 0, gomock.Any()).DoAndReturn(streamNews(1))
+			},
+			options:    []Option{WithNewsURL("https://www.example.com/articles/{id}.html")},
+			path:       "/sitemaps/news-0.xml",
+			expectCode: fiber.StatusOK,
+			expectBody: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` +
+				`<url><loc>https://www.example.com/articles/1.html</loc><lastmod>2025-10-01T12:00:00Z</lastmod></url>` +
+				`</urlset>`,
+		},
+		{
+			testName: "chunk not found",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().StreamSitemap(gomock.Any(), 5, gomock.Any()).Return(service.ErrSitemapNotFound)
+			},
+			path:       "/sitemaps/news-5.xml",
+			expectCode: fiber.StatusNotFound,
+			expectBody: service.ErrSitemapNotFound.Error(),
+		},
+		{
+			testName:      "incorrect chunk",
+			mockBehaviour: func(n *servicemocks.MockNews) {},
+			path:          "/sitemaps/news-foobar.xml",
+			expectCode:    fiber.StatusNotFound,
+			expectBody:    fiber.ErrNotFound.Message,
+		},
+		{
+			testName: "error while streaming",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().StreamSitemap(gomock.Any(), 0, gomock.Any()).DoAndReturn(
+					func(_ any, _ int, fn func(int64, time.Time) error) error {
+						_ = fn(1, updatedAt)
+						return errors.New("some error")
+					})
+			},
+			path:       "/sitemaps/news-0.xml",
+			expectCode: fiber.StatusInternalServerError,
+			expectBody: fiber.ErrInternalServerError.Message,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			n := servicemocks.NewMockNews(ctrl)
+
+			tc.mockBehaviour(n)
+
+			h := fiber.New()
+			NewRouter(h, &service.Services{News: n}, append([]Option{WithBaseURL("https://news.example.com/")}, tc.options...)...)
+
+			resp, err := h.Test(httptest.NewRequest(fiber.MethodGet, tc.path, nil))
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectBody, string(body))
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package gql
+
+import (
+	"github.com/graphql-go/graphql/language/ast"
+	"strconv"
+	"strings"
+)
+
+// поля-списки без аргумента limit оцениваются по размеру страницы по умолчанию
+var listMultipliers = map[string]int{
+	"newsList": defaultListLimit,
+}
+
+type cost struct {
+	depth      int
+	complexity int
+}
+
+// analyzer оценивает глубину и сложность операции до выполнения.
+// Сложность поля - 1 плюс сложность вложенных полей, умноженная на limit для списков.
+// Служебные поля (__schema, __typename) не учитываются
+type analyzer struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+	memo      map[string]cost
+	visiting  map[string]bool
+}
+
+func analyze(doc *ast.Document, op *ast.OperationDefinition, variables map[string]any) cost {
+	a := &analyzer{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: variables,
+		memo:      make(map[string]cost),
+		visiting:  make(map[string]bool),
+	}
+	for _, def := range doc.Definitions {
+		if f, ok := def.(*ast.FragmentDefinition); ok && f.Name != nil {
+			a.fragments[f.Name.Value] = f
+		}
+	}
+	return a.selectionSet(op.SelectionSet)
+}
+
+func (a *analyzer) selectionSet(set *ast.SelectionSet) cost {
+	var result cost
+	if set == nil {
+		return result
+	}
+	for _, selection := range set.Selections {
+		var c cost
+		switch s := selection.(type) {
+		case *ast.Field:
+			if s.Name == nil || strings.HasPrefix(s.Name.Value, "__") {
+				continue
+			}
+			child := a.selectionSet(s.SelectionSet)
+			c = cost{
+				depth:      child.depth + 1,
+				complexity: 1 + a.multiplier(s)*child.complexity,
+			}
+		case *ast.InlineFragment:
+			c = a.selectionSet(s.SelectionSet)
+		case *ast.FragmentSpread:
+			c = a.fragment(s.Name.Value)
+		}
+		result.depth = max(result.depth, c.depth)
+		result.complexity += c.complexity
+	}
+	return result
+}
+
+// результат фрагмента кешируется, иначе вложенные повторные фрагменты дают экспоненциальный обход
+func (a *analyzer) fragment(name string) cost {
+	if c, ok := a.memo[name]; ok {
+		return c
+	}
+	f, ok := a.fragments[name]
+	// циклы и неизвестные фрагменты отклонит валидация схемы
+	if !ok || a.visiting[name] {
+		return cost{}
+	}
+	a.visiting[name] = true
+	c := a.selectionSet(f.SelectionSet)
+	delete(a.visiting, name)
+
+	a.memo[name] = c
+	return c
+}
+
+func (a *analyzer) multiplier(field *ast.Field) int {
+	for _, arg := range field.Arguments {
+		if arg.Name == nil || arg.Name.Value != "limit" {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil {
+				return max(n, 0)
+			}
+		case *ast.Variable:
+			// переменные приходят из json, числа - float64
+			if n, ok := a.variables[v.Name.Value].(float64); ok {
+				return max(int(n), 0)
+			}
+		}
+	}
+	if n, ok := listMultipliers[field.Name.Value]; ok {
+		return n
+	}
+	return 1
+}
@@ -0,0 +1,53 @@
+package gql
+
+import (
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	testCases := []struct {
+		testName   string
+		query      string
+		variables  map[string]any
+		expectCost cost
+	}{
+		{
+			testName:   "default list limit",
+			query:      `{ newsList { id categories { id } } }`,
+			expectCost: cost{depth: 3, complexity: 1 + defaultListLimit*(1+2)},
+		},
+		{
+			testName:   "limit from variable",
+			query:      `query($n: Int) { newsList(limit: $n) { id } }`,
+			variables:  map[string]any{"n": float64(5)},
+			expectCost: cost{depth: 2, complexity: 1 + 5},
+		},
+		{
+			testName: "fragments",
+			query: `
+				query { news(id: 1) { ...A ... on News { title } } }
+				fragment A on News { id categories { ...B } }
+				fragment B on Category { id }`,
+			expectCost: cost{depth: 3, complexity: 1 + (1 + 2) + 1},
+		},
+		{
+			testName:   "introspection is free",
+			query:      `{ __schema { types { name fields { name type { ofType { ofType { name } } } } } } }`,
+			expectCost: cost{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: tc.query})
+			assert.NoError(t, err)
+
+			op := findOperation(doc, "")
+			assert.NotNil(t, op)
+
+			assert.Equal(t, tc.expectCost, analyze(doc, op, tc.variables))
+		})
+	}
+}
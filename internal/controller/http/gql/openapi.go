@@ -0,0 +1,30 @@
+package gql
+
+import (
+	"github.com/graphql-go/graphql"
+	"net/http"
+	"test_news/internal/controller/http/middleware"
+	"test_news/pkg/openapi"
+)
+
+// OpenAPIRoutes описывает эндпоинт GraphQL, спецификацию отдает роутер v1
+func OpenAPIRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method:   http.MethodPost,
+			Path:     "/graphql",
+			Summary:  "GraphQL over news and categories; queries are anonymous, mutations require scope news:write",
+			Tags:     []string{"graphql"},
+			Security: []string{middleware.SecurityBearer, middleware.SecurityAPIKey},
+			Body:     graphqlRequest{},
+			Response: graphql.Result{},
+			Errors: []int{
+				http.StatusBadRequest,
+				http.StatusUnauthorized,
+				http.StatusForbidden,
+				http.StatusTooManyRequests,
+				http.StatusInternalServerError,
+			},
+		},
+	}
+}
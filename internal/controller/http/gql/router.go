@@ -0,0 +1,207 @@
+package gql
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v3"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"test_news/internal/controller/http/middleware"
+	"test_news/internal/service"
+	"test_news/pkg/ratelimit"
+)
+
+const (
+	defaultMaxDepth      = 5
+	defaultMaxComplexity = 500
+)
+
+type options struct {
+	limiter       ratelimit.Store
+	limit         ratelimit.Limit
+	maxDepth      int
+	maxComplexity int
+}
+
+type Option func(o *options)
+
+// WithRateLimit ограничивает частоту запросов в группе news, общей с rest api
+func WithRateLimit(store ratelimit.Store, limit ratelimit.Limit) Option {
+	return func(o *options) {
+		o.limiter = store
+		o.limit = limit
+	}
+}
+
+// WithLimits задает максимальную глубину и сложность операции, 0 оставляет значение по умолчанию
+func WithLimits(maxDepth, maxComplexity int) Option {
+	return func(o *options) {
+		if maxDepth > 0 {
+			o.maxDepth = maxDepth
+		}
+		if maxComplexity > 0 {
+			o.maxComplexity = maxComplexity
+		}
+	}
+}
+
+type graphqlRouter struct {
+	schema        graphql.Schema
+	news          service.News
+	auth          fiber.Handler
+	writeScope    fiber.Handler
+	maxDepth      int
+	maxComplexity int
+}
+
+type operationKey struct{}
+
+type operation struct {
+	request graphqlRequest
+	doc     *ast.Document
+	op      *ast.OperationDefinition
+}
+
+func (o *operation) isMutation() bool {
+	return o.op.Operation == ast.OperationTypeMutation
+}
+
+func NewRouter(g fiber.Router, services *service.Services, opts ...Option) {
+	o := &options{
+		maxDepth:      defaultMaxDepth,
+		maxComplexity: defaultMaxComplexity,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	schema, err := newSchema(services.News)
+	if err != nil {
+		// схема строится из статических типов, ошибка здесь - ошибка программиста
+		panic(err)
+	}
+	r := &graphqlRouter{
+		schema:        schema,
+		news:          services.News,
+		auth:          middleware.Auth(services.Auth, services.APIKeys),
+		writeScope:    middleware.Scope(service.ScopeNewsWrite),
+		maxDepth:      o.maxDepth,
+		maxComplexity: o.maxComplexity,
+	}
+
+	g.Post("/graphql",
+		middleware.Error,
+		r.parse,
+		r.authorize,
+		r.mutationScope,
+		middleware.RateLimit(o.limiter, "news", o.limit),
+		r.execute,
+	)
+}
+
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// parse разбирает запрос и проверяет лимиты до авторизации, чтобы знать тип операции
+func (r *graphqlRouter) parse(c fiber.Ctx) error {
+	var req graphqlRequest
+
+	if err := c.Bind().JSON(&req); err != nil || req.Query == "" {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(&graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+	}
+	op := findOperation(doc, req.OperationName)
+	if op == nil {
+		return badRequest(c, "operation %q not found", req.OperationName)
+	}
+
+	cost := analyze(doc, op, req.Variables)
+	if cost.depth > r.maxDepth {
+		return badRequest(c, "query depth %d exceeds limit %d", cost.depth, r.maxDepth)
+	}
+	if cost.complexity > r.maxComplexity {
+		return badRequest(c, "query complexity %d exceeds limit %d", cost.complexity, r.maxComplexity)
+	}
+
+	c.Locals(operationKey{}, &operation{
+		request: req,
+		doc:     doc,
+		op:      op,
+	})
+	return c.Next()
+}
+
+// мутации проходят через middleware.Auth и требуют scope news:write, запросы доступны анонимно.
+// Переданные с запросом учетные данные проверяются всегда, чтобы лимит считался по клиенту
+func (r *graphqlRouter) authorize(c fiber.Ctx) error {
+	op := c.Locals(operationKey{}).(*operation)
+
+	if op.isMutation() {
+		return r.auth(c)
+	}
+	if c.Get(fiber.HeaderAuthorization) != "" || c.Get(middleware.HeaderAPIKey) != "" {
+		return r.auth(c)
+	}
+	return c.Next()
+}
+
+func (r *graphqlRouter) mutationScope(c fiber.Ctx) error {
+	if c.Locals(operationKey{}).(*operation).isMutation() {
+		return r.writeScope(c)
+	}
+	return c.Next()
+}
+
+func (r *graphqlRouter) execute(c fiber.Ctx) error {
+	op := c.Locals(operationKey{}).(*operation)
+
+	validation := graphql.ValidateDocument(&r.schema, op.doc, nil)
+	if !validation.IsValid {
+		return c.Status(fiber.StatusBadRequest).JSON(&graphql.Result{Errors: validation.Errors})
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        r.schema,
+		AST:           op.doc,
+		OperationName: op.request.OperationName,
+		Args:          op.request.Variables,
+		Context:       contextWithLoader(c.Context(), r.news),
+	})
+	return c.JSON(result)
+}
+
+func findOperation(doc *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			// без имени операции документ должен содержать ровно одну операцию
+			if found != nil {
+				return nil
+			}
+			found = op
+			continue
+		}
+		if op.Name != nil && op.Name.Value == name {
+			return op
+		}
+	}
+	return found
+}
+
+func badRequest(c fiber.Ctx, format string, args ...any) error {
+	return c.Status(fiber.StatusBadRequest).JSON(&graphql.Result{
+		Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError(fmt.Sprintf(format, args...))},
+	})
+}
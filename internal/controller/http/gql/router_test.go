@@ -0,0 +1,170 @@
+package gql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http/httptest"
+	"test_news/internal/controller/http/middleware"
+	"test_news/internal/mocks/servicemocks"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	"testing"
+)
+
+func TestGraphqlRouter(t *testing.T) {
+	type mockBehaviour func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews)
+
+	testCases := []struct {
+		testName      string
+		mockBehaviour mockBehaviour
+		opts          []Option
+		headers       map[string]string
+		query         string
+		variables     map[string]any
+		expectCode    int
+		expectBody    string
+	}{
+		{
+			testName: "list with categories in one batch",
+			mockBehaviour: func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews) {
+				n.EXPECT().List(gomock.Any(), 3, 0).Return([]model.News{
+					{Id: 1, Title: "Title 1"},
+					{Id: 2, Title: "Title 2"},
+					{Id: 3, Title: "Title 3"},
+				}, nil)
+				n.EXPECT().FindCategories(gomock.Any(), []int64{1, 2, 3}).Return(map[int64][]int64{
+					1: {1, 2},
+					3: {5},
+				}, nil)
+			},
+			query:      `query($limit: Int) { newsList(limit: $limit) { id title categories { id } } }`,
+			variables:  map[string]any{"limit": 3},
+			expectCode: fiber.StatusOK,
+			expectBody: `{"data":{"newsList":[` +
+				`{"categories":[{"id":"1"},{"id":"2"}],"id":"1","title":"Title 1"},` +
+				`{"categories":[],"id":"2","title":"Title 2"},` +
+				`{"categories":[{"id":"5"}],"id":"3","title":"Title 3"}]}}`,
+		},
+		{
+			testName: "news not found",
+			mockBehaviour: func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews) {
+				n.EXPECT().Get(gomock.Any(), int64(2)).Return(model.News{}, service.ErrNewsNotFound)
+			},
+			query:      `{ news(id: 2) { id } }`,
+			expectCode: fiber.StatusOK,
+			expectBody: `{"data":{"news":null}}`,
+		},
+		{
+			testName: "create",
+			mockBehaviour: func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews) {
+				a.EXPECT().Validate("TOKEN").Return("subject", true)
+				n.EXPECT().Create(gomock.Any(), model.News{
+					Title:      "hello world",
+					Content:    "my content",
+					Categories: []int64{1},
+				}).Return(int64(7), nil)
+			},
+			headers:    map[string]string{fiber.HeaderAuthorization: "Bearer TOKEN"},
+			query:      `mutation { createNews(title: "hello world", content: "my content", categories: [1]) { id title } }`,
+			expectCode: fiber.StatusOK,
+			expectBody: `{"data":{"createNews":{"id":"7","title":"hello world"}}}`,
+		},
+		{
+			testName: "delete not found wrapped",
+			mockBehaviour: func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews) {
+				a.EXPECT().Validate("TOKEN").Return("subject", true)
+				n.EXPECT().Delete(gomock.Any(), int64(2)).Return(fmt.Errorf("txmanager.TxFunc exec func error: %w", service.ErrNewsNotFound))
+			},
+			headers:    map[string]string{fiber.HeaderAuthorization: "Bearer TOKEN"},
+			query:      `mutation { deleteNews(id: 2) }`,
+			expectCode: fiber.StatusOK,
+			expectBody: `{"data":null,"errors":[{"message":"news not found","locations":[{"line":1,"column":12}],"path":["deleteNews"]}]}`,
+		},
+		{
+			testName:      "mutation without token",
+			mockBehaviour: func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews) {},
+			query:         `mutation { deleteNews(id: 1) }`,
+			expectCode:    fiber.StatusUnauthorized,
+			expectBody:    fiber.ErrUnauthorized.Message,
+		},
+		{
+			testName: "mutation without write scope",
+			mockBehaviour: func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews) {
+				k.EXPECT().Validate(gomock.Any(), "BOT KEY").Return(service.Principal{
+					Subject: "apikey:2",
+					Scopes:  []string{service.ScopeNewsRead},
+				}, nil)
+			},
+			headers:    map[string]string{middleware.HeaderAPIKey: "BOT KEY"},
+			query:      `mutation { deleteNews(id: 1) }`,
+			expectCode: fiber.StatusForbidden,
+			expectBody: fiber.ErrForbidden.Message,
+		},
+		{
+			testName:      "depth limit",
+			mockBehaviour: func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews) {},
+			opts:          []Option{WithLimits(2, 0)},
+			query:         `{ newsList { categories { id } } }`,
+			expectCode:    fiber.StatusBadRequest,
+			expectBody:    `{"data":null,"errors":[{"message":"query depth 3 exceeds limit 2","locations":[]}]}`,
+		},
+		{
+			testName:      "complexity limit",
+			mockBehaviour: func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews) {},
+			opts:          []Option{WithLimits(0, 40)},
+			query:         `{ newsList(limit: 20) { id title } }`,
+			expectCode:    fiber.StatusBadRequest,
+			expectBody:    `{"data":null,"errors":[{"message":"query complexity 41 exceeds limit 40","locations":[]}]}`,
+		},
+		{
+			testName:      "unknown field",
+			mockBehaviour: func(a *servicemocks.MockAuth, k *servicemocks.MockAPIKeys, n *servicemocks.MockNews) {},
+			query:         `{ newsList { author } }`,
+			expectCode:    fiber.StatusBadRequest,
+			expectBody:    `{"data":null,"errors":[{"message":"Cannot query field \"author\" on type \"News\".","locations":[{"line":1,"column":14}]}]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			a := servicemocks.NewMockAuth(ctrl)
+			k := servicemocks.NewMockAPIKeys(ctrl)
+			n := servicemocks.NewMockNews(ctrl)
+
+			tc.mockBehaviour(a, k, n)
+
+			h := fiber.New()
+			NewRouter(h, &service.Services{
+				Auth:    a,
+				APIKeys: k,
+				News:    n,
+			}, tc.opts...)
+
+			body, err := json.Marshal(graphqlRequest{Query: tc.query, Variables: tc.variables})
+			assert.NoError(t, err)
+
+			r := httptest.NewRequest(fiber.MethodPost, "/graphql", bytes.NewBuffer(body))
+			r.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			for key, value := range tc.headers {
+				r.Header.Set(key, value)
+			}
+
+			resp, err := h.Test(r)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectCode, resp.StatusCode)
+
+			actual, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectBody, string(actual))
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/graphql-go/graphql"
+	"github.com/rs/zerolog"
+	"strconv"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	"test_news/pkg/dataloader"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 20
+)
+
+var errInternal = errors.New("internal error")
+
+type loaderKey struct{}
+
+type categoriesLoader = dataloader.Loader[int64, []int64]
+
+// у каждого запроса свой загрузчик: кеш категорий не переживает запрос
+func contextWithLoader(ctx context.Context, news service.News) context.Context {
+	return context.WithValue(ctx, loaderKey{}, dataloader.New(news.FindCategories))
+}
+
+func loaderFromContext(ctx context.Context) *categoriesLoader {
+	return ctx.Value(loaderKey{}).(*categoriesLoader)
+}
+
+type resolver struct {
+	news service.News
+}
+
+func newSchema(news service.News) (graphql.Schema, error) {
+	r := &resolver{
+		news: news,
+	}
+
+	categoryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Category",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(int64), nil
+				},
+			},
+		},
+	})
+
+	newsType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "News",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(model.News).Id, nil
+				},
+			},
+			"title": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(model.News).Title, nil
+				},
+			},
+			"content": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(model.News).Content, nil
+				},
+			},
+			"categories": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(categoryType))),
+				Resolve: r.categories,
+			},
+		},
+	})
+
+	idArg := &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}
+	categoriesArg := &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.ID))}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"news": &graphql.Field{
+				Type:    newsType,
+				Args:    graphql.FieldConfigArgument{"id": idArg},
+				Resolve: r.get,
+			},
+			"newsList": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(newsType))),
+				Args: graphql.FieldConfigArgument{
+					"limit":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultListLimit},
+					"offset": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+				},
+				Resolve: r.list,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createNews": &graphql.Field{
+				Type: graphql.NewNonNull(newsType),
+				Args: graphql.FieldConfigArgument{
+					"title":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"content":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"categories": categoriesArg,
+				},
+				Resolve: r.create,
+			},
+			"updateNews": &graphql.Field{
+				Type: graphql.NewNonNull(newsType),
+				Args: graphql.FieldConfigArgument{
+					"id":         idArg,
+					"title":      &graphql.ArgumentConfig{Type: graphql.String},
+					"content":    &graphql.ArgumentConfig{Type: graphql.String},
+					"categories": categoriesArg,
+				},
+				Resolve: r.update,
+			},
+			"deleteNews": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Args:    graphql.FieldConfigArgument{"id": idArg},
+				Resolve: r.delete,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
+
+func (r *resolver) categories(p graphql.ResolveParams) (any, error) {
+	thunk := loaderFromContext(p.Context).Load(p.Context, p.Source.(model.News).Id)
+
+	// thunk вычисляется после того, как резолверы всех новостей списка зарегистрируют свои id
+	return func() (any, error) {
+		categories, err := thunk()
+		if err != nil {
+			return nil, resolveError(p.Context, err)
+		}
+		if categories == nil {
+			return []int64{}, nil
+		}
+		return categories, nil
+	}, nil
+}
+
+func (r *resolver) get(p graphql.ResolveParams) (any, error) {
+	id, err := parseId(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	news, err := r.news.Get(p.Context, id)
+	if err != nil {
+		if errors.Is(err, service.ErrNewsNotFound) {
+			return nil, nil
+		}
+		return nil, resolveError(p.Context, err)
+	}
+	return news, nil
+}
+
+func (r *resolver) list(p graphql.ResolveParams) (any, error) {
+	limit, offset := p.Args["limit"].(int), p.Args["offset"].(int)
+	if limit < 0 || limit > maxListLimit || offset < 0 {
+		return nil, fmt.Errorf("limit must be in [0, %d], offset must be non-negative", maxListLimit)
+	}
+
+	news, err := r.news.List(p.Context, limit, offset)
+	if err != nil {
+		return nil, resolveError(p.Context, err)
+	}
+	return news, nil
+}
+
+func (r *resolver) create(p graphql.ResolveParams) (any, error) {
+	news := model.News{
+		Title:   p.Args["title"].(string),
+		Content: p.Args["content"].(string),
+	}
+	if news.Title == "" || news.Content == "" {
+		return nil, errors.New("title and content cannot be empty")
+	}
+	categories, err := parseCategories(p.Args["categories"])
+	if err != nil {
+		return nil, err
+	}
+	news.Categories = categories
+
+	id, err := r.news.Create(p.Context, news)
+	if err != nil {
+		return nil, resolveError(p.Context, err)
+	}
+	news.Id = id
+	return news, nil
+}
+
+// отсутствующий или null аргумент не меняет поле, пустой список категорий очищает их
+func (r *resolver) update(p graphql.ResolveParams) (any, error) {
+	id, err := parseId(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	input := service.NewsUpdate{Id: id}
+	if title, ok := p.Args["title"].(string); ok {
+		if title == "" {
+			return nil, errors.New("title cannot be empty")
+		}
+		input.Title = &title
+	}
+	if content, ok := p.Args["content"].(string); ok {
+		if content == "" {
+			return nil, errors.New("content cannot be empty")
+		}
+		input.Content = &content
+	}
+	if raw, ok := p.Args["categories"]; ok {
+		if input.Categories, err = parseCategories(raw); err != nil {
+			return nil, err
+		}
+		input.ReplaceCategories = true
+	}
+
+	if err = r.news.Update(p.Context, input); err != nil {
+		return nil, resolveError(p.Context, err)
+	}
+	news, err := r.news.Get(p.Context, id)
+	if err != nil {
+		return nil, resolveError(p.Context, err)
+	}
+	return news, nil
+}
+
+func (r *resolver) delete(p graphql.ResolveParams) (any, error) {
+	id, err := parseId(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	if err = r.news.Delete(p.Context, id); err != nil {
+		return nil, resolveError(p.Context, err)
+	}
+	return true, nil
+}
+
+// ошибки сервиса отдаются клиенту без префиксов оберток, остальные скрываются - как в middleware.Error
+func resolveError(ctx context.Context, err error) error {
+	if errors.Is(err, service.ErrNotFound) || errors.Is(err, service.ErrAlreadyExists) || errors.Is(err, service.ErrInvalidInput) {
+		return errors.New(service.ClientMessage(err))
+	}
+	zerolog.Ctx(ctx).Err(err).Msg("graphql resolver error")
+	return errInternal
+}
+
+func parseId(raw any) (int64, error) {
+	id, err := strconv.ParseInt(fmt.Sprint(raw), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid id %v", raw)
+	}
+	return id, nil
+}
+
+func parseCategories(raw any) ([]int64, error) {
+	values, _ := raw.([]any)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	categories := make([]int64, 0, len(values))
+	for _, v := range values {
+		id, err := parseId(v)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, id)
+	}
+	return categories, nil
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+// AccessLog пишет строку на каждый запрос логгером запроса (после RequestID - с request_id).
+// route - шаблон маршрута, как в Metrics; user - subject токена или api ключа, для анонимных запросов пустой
+func AccessLog(c fiber.Ctx) error {
+	start := time.Now()
+	err := c.Next()
+
+	route, status := routeStatus(c, err)
+	var user string
+	if p, ok := GetPrincipal(c); ok {
+		user = p.Subject
+	}
+	// тело потокового ответа (SSE) еще не записано, его размер неизвестен
+	bytes := -1
+	if !c.Response().IsBodyStream() {
+		bytes = len(c.Response().Body())
+	}
+
+	level := zerolog.InfoLevel
+	if status >= fiber.StatusInternalServerError {
+		level = zerolog.ErrorLevel
+	}
+	zerolog.Ctx(c.Context()).WithLevel(level).
+		Str("method", c.Method()).
+		Str("route", route).
+		Str("path", c.Path()).
+		Int("status", status).
+		Dur("latency", time.Since(start)).
+		Int("bytes", bytes).
+		Str("ip", c.IP()).
+		Str("user", user).
+		Msg("http request")
+	return err
+}
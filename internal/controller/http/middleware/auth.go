@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog"
+	"github.com/valyala/fasthttp"
+	"strings"
+	"test_news/internal/metrics"
+	"test_news/internal/service"
+)
+
+const HeaderAPIKey = "X-API-Key"
+
+// subjectPrefixJWT - subject принципала из jwt токена; токен выдается кому угодно, subject у каждого свой
+const subjectPrefixJWT = "jwt:"
+
+// имена схем авторизации в спецификации OpenAPI
+const (
+	SecurityBearer = "bearerAuth"
+	SecurityAPIKey = "apiKeyAuth"
+)
+
+type principalKey struct{}
+
+func Auth(auth service.Auth, keys service.APIKeys) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if key := c.Get(HeaderAPIKey); key != "" {
+			p, err := keys.Validate(c.Context(), key)
+			if err != nil {
+				if errors.Is(err, service.ErrAPIKeyInvalid) {
+					metrics.AuthFailures.WithLabelValues(metrics.AuthReasonInvalidAPIKey).Inc()
+					zerolog.Ctx(c.Context()).Warn().Str("ip", c.IP()).Msg("auth middleware invalid api key")
+					return c.SendStatus(fiber.StatusForbidden)
+				}
+				return err
+			}
+			setPrincipal(c, p)
+			return c.Next()
+		}
+
+		token, ok := parseToken(c.Request())
+		if !ok {
+			metrics.AuthFailures.WithLabelValues(metrics.AuthReasonMissingToken).Inc()
+			zerolog.Ctx(c.Context()).Warn().Str("ip", c.IP()).Msg("auth middleware unauthorized access")
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+		if subject, ok := auth.Validate(token); ok {
+			// jwt токен выдается кому угодно, поэтому дает только чтение и запись новостей, без admin
+			p := service.Principal{Scopes: service.JWTScopes}
+			if subject != "" {
+				p.Subject = subjectPrefixJWT + subject
+			}
+			setPrincipal(c, p)
+			return c.Next()
+		}
+		metrics.AuthFailures.WithLabelValues(metrics.AuthReasonInvalidToken).Inc()
+		zerolog.Ctx(c.Context()).Warn().Str("ip", c.IP()).Msg("auth middleware invalid token")
+		return c.SendStatus(fiber.StatusForbidden)
+	}
+}
+
+func setPrincipal(c fiber.Ctx, p service.Principal) {
+	c.Locals(principalKey{}, p)
+	c.SetContext(service.ContextWithActor(c.Context(), service.Actor{
+		Subject: p.Subject,
+		IP:      c.IP(),
+		Route:   c.Method() + " " + c.Path(),
+	}))
+}
+
+func GetPrincipal(c fiber.Ctx) (service.Principal, bool) {
+	p, ok := c.Locals(principalKey{}).(service.Principal)
+	return p, ok
+}
+
+func Scope(scope string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		p, ok := GetPrincipal(c)
+		if !ok || !p.HasScope(scope) {
+			metrics.AuthFailures.WithLabelValues(metrics.AuthReasonScope).Inc()
+			zerolog.Ctx(c.Context()).Warn().Str("ip", c.IP()).Str("scope", scope).Msg("scope middleware access denied")
+			return c.SendStatus(fiber.StatusForbidden)
+		}
+		return c.Next()
+	}
+}
+
+func parseToken(r *fasthttp.Request) (string, bool) {
+	header := string(r.Header.Peek(fiber.HeaderAuthorization))
+	if header == "" {
+		return "", false
+	}
+	token := strings.Split(header, "Bearer ")
+	if len(token) != 2 {
+		return "", false
+	}
+	return token[1], true
+}
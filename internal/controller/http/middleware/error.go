@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+	"test_news/internal/service"
+)
+
+func Error(c fiber.Ctx) error {
+	err := c.Next()
+	if err == nil {
+		return nil
+	}
+
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).SendString(service.ClientMessage(err))
+
+	case errors.Is(err, service.ErrAlreadyExists):
+		return c.Status(fiber.StatusBadRequest).SendString(service.ClientMessage(err))
+
+	case errors.Is(err, service.ErrInvalidInput):
+		return c.Status(fiber.StatusBadRequest).SendString(service.ClientMessage(err))
+	}
+
+	// ошибки самого fiber (нет маршрута, неверный метод) уже несут свой статус
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return c.Status(fe.Code).SendString(fe.Message)
+	}
+
+	trace.SpanFromContext(c.Context()).RecordError(err)
+	zerolog.Ctx(c.Context()).Err(err).Str("ip", c.IP()).Msg("error middleware")
+	return c.SendStatus(fiber.StatusInternalServerError)
+}
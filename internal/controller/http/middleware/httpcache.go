@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"github.com/gofiber/fiber/v3"
+	"net/http"
+	"strings"
+	"test_news/internal/model"
+	"time"
+)
+
+// CacheControl ставит заголовок Cache-Control успешным ответам на GET. Ответ авторизованному клиенту
+// всегда private: общие кэши (CDN, прокси) не должны отдавать его другим клиентам
+func CacheControl(policy string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if policy == "" || (c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead) {
+			return nil
+		}
+		if status := c.Response().StatusCode(); status != fiber.StatusOK && status != fiber.StatusNotModified {
+			return nil
+		}
+
+		// policy общая для всех запросов маршрута, значение для ответа считается отдельно
+		p := policy
+		if _, ok := GetPrincipal(c); ok || c.Get(fiber.HeaderAuthorization) != "" || c.Get(HeaderAPIKey) != "" {
+			p = privatePolicy(policy)
+		}
+		c.Set(fiber.HeaderCacheControl, p)
+		return nil
+	}
+}
+
+// privatePolicy заменяет public на private и убирает s-maxage, который имеет смысл только для общих кэшей
+func privatePolicy(policy string) string {
+	directives := []string{"private"}
+	for _, d := range strings.Split(policy, ",") {
+		d = strings.TrimSpace(d)
+		name := strings.ToLower(d)
+		if name == "" || name == "public" || name == "private" || strings.HasPrefix(name, "s-maxage") {
+			continue
+		}
+		directives = append(directives, d)
+	}
+	return strings.Join(directives, ", ")
+}
+
+// NewsETag - strong ETag и время последнего изменения набора новостей. Любое изменение новости (в том числе
+// категорий) обновляет updated_at, а добавление и удаление меняют набор id
+func NewsETag(news ...model.News) (string, time.Time) {
+	var lastModified time.Time
+	h := sha256.New()
+	buf := make([]byte, 16)
+	for _, n := range news {
+		binary.BigEndian.PutUint64(buf, uint64(n.Id))
+		binary.BigEndian.PutUint64(buf[8:], uint64(n.UpdatedAt.UnixNano()))
+		h.Write(buf)
+		if n.UpdatedAt.After(lastModified) {
+			lastModified = n.UpdatedAt
+		}
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`, lastModified
+}
+
+// Conditional ставит ETag и Last-Modified и проверяет условный запрос; true - ответить 304
+func Conditional(c fiber.Ctx, etag string, lastModified time.Time) bool {
+	c.Set(fiber.HeaderETag, etag)
+	if !lastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, lastModified.UTC().Format(http.TimeFormat))
+	}
+	return notModified(c, etag, lastModified)
+}
+
+// notModified проверяет условный запрос: If-None-Match приоритетнее If-Modified-Since (RFC 9110 13.2.2).
+// c.Fresh() не подходит - при одном If-Modified-Since он не сравнивает даты
+func notModified(c fiber.Ctx, etag string, lastModified time.Time) bool {
+	if inm := c.Get(fiber.HeaderIfNoneMatch); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := c.Get(fiber.HeaderIfModifiedSince); ims != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(ims)
+		return err == nil && !lastModified.Truncate(time.Second).After(t)
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v3"
+	"strconv"
+	"test_news/internal/metrics"
+	"time"
+)
+
+// Metrics считает запросы и их длительность. route - шаблон маршрута (/api/v2/news/:id), а не путь:
+// иначе число рядов росло бы с каждым новым id. Запрос, остановленный middleware группы (авторизация,
+// лимит) или не нашедший маршрута, до обработчика не дошел, для него route - префикс последнего
+// пройденного middleware (/api/v1 или /)
+func Metrics(c fiber.Ctx) error {
+	start := time.Now()
+	err := c.Next()
+
+	route, status := routeStatus(c, err)
+	labels := []string{c.Method(), route, strconv.Itoa(status)}
+	metrics.HTTPRequests.WithLabelValues(labels...).Inc()
+	metrics.HTTPRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	return err
+}
+
+// routeStatus вызывается после c.Next(): ошибку еще не превратил в ответ ErrorHandler приложения,
+// статус берем из нее
+func routeStatus(c fiber.Ctx, err error) (string, int) {
+	route, status := c.Route().Path, c.Response().StatusCode()
+	if err != nil {
+		status = fiber.StatusInternalServerError
+		var fe *fiber.Error
+		if errors.As(err, &fe) {
+			status = fe.Code
+		}
+	}
+	return route, status
+}
@@ -0,0 +1,12 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"test_news/internal/repo/txmanager"
+)
+
+// ReadYourWrites - после записи в рамках запроса чтения идут в primary, а не в реплику
+func ReadYourWrites(c fiber.Ctx) error {
+	c.SetContext(txmanager.ReadYourWrites(c.Context()))
+	return c.Next()
+}
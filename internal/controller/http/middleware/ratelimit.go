@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog"
+	"math"
+	"strconv"
+	"strings"
+	"test_news/pkg/ratelimit"
+	"time"
+)
+
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+)
+
+func RateLimit(store ratelimit.Store, group string, limit ratelimit.Limit) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if store == nil || !limit.Enabled() {
+			return c.Next()
+		}
+
+		result, err := store.Take(c.Context(), group+":"+clientKey(c), limit)
+		if err != nil {
+			// лимитер не должен ронять api, поэтому при ошибке стора пропускаем запрос
+			zerolog.Ctx(c.Context()).Err(err).Str("ip", c.IP()).Msg("rate limit middleware store error")
+			return c.Next()
+		}
+
+		c.Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+		c.Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+		c.Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			return c.SendStatus(fiber.StatusTooManyRequests)
+		}
+		return c.Next()
+	}
+}
+
+// ключ клиента: subject api ключа или оператора, иначе ip. Jwt токен без ограничений выдает /authorize,
+// поэтому по его subject лимит обходился бы получением нового токена
+func clientKey(c fiber.Ctx) string {
+	if p, ok := GetPrincipal(c); ok && p.Subject != "" && !strings.HasPrefix(p.Subject, subjectPrefixJWT) {
+		return p.Subject
+	}
+	return "ip:" + c.IP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// входящий X-Request-ID длиннее или с непечатаемыми символами заменяется новым: он попадает в логи
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestID берет id запроса из X-Request-ID или генерирует новый и возвращает его в ответе.
+// В c.Context() кладется логгер с request_id (и trace_id, если запрос трассируется):
+// zerolog.Ctx(ctx) в middleware, сервисах и tracer'е запросов пишет с теми же полями
+func RequestID(c fiber.Ctx) error {
+	id := c.Get(fiber.HeaderXRequestID)
+	if !validRequestID(id) {
+		id = rand.Text()
+	}
+	c.Set(fiber.HeaderXRequestID, id)
+	c.Locals(requestIDKey{}, id)
+
+	lc := log.Logger.With().Str("request_id", id)
+	if sc := trace.SpanContextFromContext(c.Context()); sc.IsValid() {
+		lc = lc.Str("trace_id", sc.TraceID().String())
+	}
+	logger := lc.Logger()
+	c.SetContext(logger.WithContext(c.Context()))
+	return c.Next()
+}
+
+func GetRequestID(c fiber.Ctx) string {
+	id, _ := c.Locals(requestIDKey{}).(string)
+	return id
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
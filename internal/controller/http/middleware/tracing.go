@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+const tracerName = "test_news/internal/controller/http"
+
+// Tracing - server span на запрос. Входящий traceparent становится родителем span'а, сам span
+// кладется в c.Context(), поэтому span'ы сервисов и SQL запросов становятся его потомками.
+// Имя и http.route выставляются после обработки: маршрут известен только тогда
+func Tracing(c fiber.Ctx) error {
+	ctx := otel.GetTextMapPropagator().Extract(c.Context(), headerCarrier{c})
+	ctx, span := otel.Tracer(tracerName).Start(ctx, c.Method(),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(c.Method()),
+			semconv.URLPath(c.Path()),
+			semconv.ClientAddress(c.IP()),
+		),
+	)
+	defer span.End()
+	c.SetContext(ctx)
+
+	err := c.Next()
+
+	route, status := routeStatus(c, err)
+	span.SetName(c.Method() + " " + route)
+	span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+	if err != nil {
+		span.RecordError(err)
+	}
+	// 4xx - ошибка клиента, а не сервера, span ею не помечается
+	if status >= fiber.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
+	return err
+}
+
+type headerCarrier struct {
+	c fiber.Ctx
+}
+
+func (h headerCarrier) Get(key string) string {
+	return h.c.Get(key)
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h.c.Request().Header.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, h.c.Request().Header.Len())
+	for key := range h.c.GetReqHeaders() {
+		keys = append(keys, key)
+	}
+	return keys
+}
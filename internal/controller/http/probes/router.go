@@ -0,0 +1,56 @@
+package probes
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"net/http"
+	"test_news/pkg/health"
+	"test_news/pkg/openapi"
+)
+
+// NewRouter - /healthz (процесс жив) и /readyz (готов принимать запросы). В отличие от /ping,
+// /readyz проверяет зависимости и отвечает 503, пока хоть одна обязательная недоступна или идет остановка
+func NewRouter(g fiber.Router, checker *health.Checker) {
+	g.Get("/healthz", liveness)
+	g.Get("/readyz", readiness(checker))
+}
+
+type livenessResponse struct {
+	Status string `json:"status"`
+}
+
+// liveness не проверяет зависимости: недоступная БД - повод снять сервис с балансировки, а не перезапускать
+func liveness(c fiber.Ctx) error {
+	return c.JSON(livenessResponse{Status: health.StatusOK})
+}
+
+func readiness(checker *health.Checker) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		report := checker.Check(c.Context())
+		status := fiber.StatusOK
+		if !report.Ready() {
+			status = fiber.StatusServiceUnavailable
+		}
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.Status(status).JSON(report)
+	}
+}
+
+func OpenAPIRoutes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method:   http.MethodGet,
+			Path:     "/healthz",
+			Summary:  "Liveness probe, does not check dependencies",
+			Tags:     []string{"system"},
+			Response: livenessResponse{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/readyz",
+			Summary:  "Readiness probe with per-component status and latency, 503 while not ready or shutting down",
+			Tags:     []string{"system"},
+			Response: health.Report{},
+			Errors:   []int{http.StatusServiceUnavailable},
+		},
+	}
+}
@@ -0,0 +1,80 @@
+package probes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"test_news/pkg/health"
+	"testing"
+	"time"
+)
+
+func TestProbes(t *testing.T) {
+	var pgErr error
+	checker := health.NewChecker(time.Second)
+	checker.Add("postgres", func(context.Context) error { return pgErr })
+
+	h := fiber.New()
+	NewRouter(h, checker)
+
+	testCases := []struct {
+		testName       string
+		path           string
+		setup          func()
+		expectCode     int
+		expectStatus   string
+		expectPgStatus string
+	}{
+		{
+			testName:     "liveness",
+			path:         "/healthz",
+			setup:        func() { pgErr = errors.New("connection refused") },
+			expectCode:   fiber.StatusOK,
+			expectStatus: health.StatusOK,
+		},
+		{
+			testName:       "ready",
+			path:           "/readyz",
+			setup:          func() { pgErr = nil },
+			expectCode:     fiber.StatusOK,
+			expectStatus:   health.StatusOK,
+			expectPgStatus: health.StatusOK,
+		},
+		{
+			testName:       "dependency down",
+			path:           "/readyz",
+			setup:          func() { pgErr = errors.New("connection refused") },
+			expectCode:     fiber.StatusServiceUnavailable,
+			expectStatus:   health.StatusFail,
+			expectPgStatus: health.StatusFail,
+		},
+		{
+			testName: "draining",
+			path:     "/readyz",
+			setup: func() {
+				pgErr = nil
+				checker.Drain()
+			},
+			expectCode:   fiber.StatusServiceUnavailable,
+			expectStatus: health.StatusDraining,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			tc.setup()
+
+			resp, err := h.Test(httptest.NewRequest(fiber.MethodGet, tc.path, nil))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectCode, resp.StatusCode)
+
+			var report health.Report
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+			assert.Equal(t, tc.expectStatus, report.Status)
+			assert.Equal(t, tc.expectPgStatus, report.Components["postgres"].Status)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	"time"
+)
+
+const defaultAuditLimit = 50
+
+type auditRouter struct {
+	audit service.Audit
+}
+
+func newAuditRouter(g fiber.Router, audit service.Audit) {
+	r := &auditRouter{
+		audit: audit,
+	}
+
+	g.Get("/list", r.list)
+}
+
+type auditListInput struct {
+	Entity   string `query:"entity"`
+	EntityId int64  `query:"entity_id" validate:"gte=0"`
+	Actor    string `query:"actor"`
+	From     string `query:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To       string `query:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Limit    int    `query:"limit" validate:"gte=0,lte=500"`
+	Offset   int    `query:"offset" validate:"gte=0"`
+}
+
+type auditListResponse struct {
+	Success bool               `json:"Success"`
+	Entries []model.AuditEntry `json:"Entries"`
+}
+
+func (r *auditRouter) list(c fiber.Ctx) error {
+	var input auditListInput
+
+	if err := c.Bind().Query(&input); err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	query := service.AuditQuery{
+		Entity:   input.Entity,
+		EntityId: input.EntityId,
+		Actor:    input.Actor,
+		From:     parseTime(input.From),
+		To:       parseTime(input.To),
+		Limit:    input.Limit,
+		Offset:   input.Offset,
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultAuditLimit
+	}
+
+	entries, err := r.audit.Find(c.Context(), query)
+	if err != nil {
+		return err
+	}
+	return c.JSON(auditListResponse{
+		Success: true,
+		Entries: entries,
+	})
+}
+
+// формат уже проверен валидатором
+func parseTime(s string) *time.Time {
+	if s == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
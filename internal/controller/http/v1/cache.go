@@ -0,0 +1,43 @@
+package v1
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"test_news/internal/service"
+)
+
+type cacheRouter struct {
+	cache service.Cache
+}
+
+func newCacheRouter(g fiber.Router, cache service.Cache) {
+	r := &cacheRouter{
+		cache: cache,
+	}
+
+	g.Get("/stats", r.stats)
+}
+
+type cacheStatsResponse struct {
+	Success   bool   `json:"Success"`
+	Enabled   bool   `json:"Enabled"`
+	Hits      uint64 `json:"Hits"`
+	Misses    uint64 `json:"Misses"`
+	Evictions uint64 `json:"Evictions"`
+	Size      int    `json:"Size"`
+}
+
+func (r *cacheRouter) stats(c fiber.Ctx) error {
+	if r.cache == nil {
+		return c.JSON(cacheStatsResponse{Success: true})
+	}
+
+	stats := r.cache.Stats()
+	return c.JSON(cacheStatsResponse{
+		Success:   true,
+		Enabled:   true,
+		Hits:      stats.Hits,
+		Misses:    stats.Misses,
+		Evictions: stats.Evictions,
+		Size:      stats.Size,
+	})
+}
@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"strconv"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	"time"
+)
+
+type keysRouter struct {
+	keys service.APIKeys
+}
+
+func newKeysRouter(g fiber.Router, keys service.APIKeys) {
+	r := &keysRouter{
+		keys: keys,
+	}
+
+	g.Post("/create", r.create)
+	g.Get("/list", r.list)
+	g.Post("/revoke/:id", r.revoke)
+}
+
+type keyCreateInput struct {
+	Name      string     `json:"Name" validate:"required"`
+	Scopes    []string   `json:"Scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time `json:"ExpiresAt"`
+}
+
+type keyCreateResponse struct {
+	Id  int64  `json:"Id"`
+	Key string `json:"Key"`
+}
+
+func (r *keysRouter) create(c fiber.Ctx) error {
+	var input keyCreateInput
+
+	if err := c.Bind().Body(&input); err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	key, apiKey, err := r.keys.Create(c.Context(), service.APIKeyCreate{
+		Name:      input.Name,
+		Scopes:    input.Scopes,
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(keyCreateResponse{
+		Id:  apiKey.Id,
+		Key: key,
+	})
+}
+
+type keyListResponse struct {
+	Success bool           `json:"Success"`
+	Keys    []model.APIKey `json:"Keys"`
+}
+
+func (r *keysRouter) list(c fiber.Ctx) error {
+	keys, err := r.keys.List(c.Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(keyListResponse{
+		Success: true,
+		Keys:    keys,
+	})
+}
+
+func (r *keysRouter) revoke(c fiber.Ctx) error {
+	id, err := fiber.Convert(c.Params("id"), strconv.Atoi)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	if err = r.keys.Revoke(c.Context(), int64(id)); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
@@ -0,0 +1,158 @@
+package v1
+
+import (
+	"bytes"
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http/httptest"
+	"test_news/internal/controller/http/middleware"
+	"test_news/internal/mocks/servicemocks"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	"test_news/pkg/validator"
+	"testing"
+)
+
+func TestKeysRouter_create(t *testing.T) {
+	type mockBehaviour func(k *servicemocks.MockAPIKeys)
+
+	testCases := []struct {
+		testName      string
+		mockBehaviour mockBehaviour
+		apiKey        string
+		inputBody     string
+		expectCode    int
+		expectBody    string
+	}{
+		{
+			testName: "correct test",
+			mockBehaviour: func(k *servicemocks.MockAPIKeys) {
+				k.EXPECT().Validate(gomock.Any(), "ADMIN KEY").Return(service.Principal{
+					Subject: "apikey:1",
+					Scopes:  []string{service.ScopeAdmin},
+				}, nil)
+				k.EXPECT().Create(gomock.Any(), service.APIKeyCreate{
+					Name:   "bot",
+					Scopes: []string{service.ScopeNewsRead},
+				}).Return("tn_live_KEY", model.APIKey{Id: 2}, nil)
+			},
+			apiKey:     "ADMIN KEY",
+			inputBody:  `{"Name": "bot", "Scopes": ["news:read"]}`,
+			expectCode: fiber.StatusOK,
+			expectBody: `{"Id":2,"Key":"tn_live_KEY"}`,
+		},
+		{
+			testName: "missing admin scope",
+			mockBehaviour: func(k *servicemocks.MockAPIKeys) {
+				k.EXPECT().Validate(gomock.Any(), "BOT KEY").Return(service.Principal{
+					Subject: "apikey:2",
+					Scopes:  []string{service.ScopeNewsRead},
+				}, nil)
+			},
+			apiKey:     "BOT KEY",
+			inputBody:  `{"Name": "bot", "Scopes": ["news:read"]}`,
+			expectCode: fiber.StatusForbidden,
+			expectBody: fiber.ErrForbidden.Message,
+		},
+		{
+			testName: "invalid api key",
+			mockBehaviour: func(k *servicemocks.MockAPIKeys) {
+				k.EXPECT().Validate(gomock.Any(), "REVOKED KEY").Return(service.Principal{}, service.ErrAPIKeyInvalid)
+			},
+			apiKey:     "REVOKED KEY",
+			inputBody:  `{"Name": "bot", "Scopes": ["news:read"]}`,
+			expectCode: fiber.StatusForbidden,
+			expectBody: fiber.ErrForbidden.Message,
+		},
+		{
+			testName: "missing scopes",
+			mockBehaviour: func(k *servicemocks.MockAPIKeys) {
+				k.EXPECT().Validate(gomock.Any(), "ADMIN KEY").Return(service.Principal{
+					Subject: "apikey:1",
+					Scopes:  []string{service.ScopeAdmin},
+				}, nil)
+			},
+			apiKey:     "ADMIN KEY",
+			inputBody:  `{"Name": "bot"}`,
+			expectCode: fiber.StatusBadRequest,
+			expectBody: fiber.ErrBadRequest.Message,
+		},
+		{
+			testName: "unknown scope",
+			mockBehaviour: func(k *servicemocks.MockAPIKeys) {
+				k.EXPECT().Validate(gomock.Any(), "ADMIN KEY").Return(service.Principal{
+					Subject: "apikey:1",
+					Scopes:  []string{service.ScopeAdmin},
+				}, nil)
+				k.EXPECT().Create(gomock.Any(), service.APIKeyCreate{
+					Name:   "bot",
+					Scopes: []string{"foobar"},
+				}).Return("", model.APIKey{}, service.ErrUnknownScope)
+			},
+			apiKey:     "ADMIN KEY",
+			inputBody:  `{"Name": "bot", "Scopes": ["foobar"]}`,
+			expectCode: fiber.StatusBadRequest,
+			expectBody: service.ErrUnknownScope.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			k := servicemocks.NewMockAPIKeys(ctrl)
+			a := servicemocks.NewMockAuth(ctrl)
+
+			tc.mockBehaviour(k)
+
+			h := fiber.New(fiber.Config{
+				StructValidator: validator.New(),
+			})
+			NewRouter(h, &service.Services{
+				Auth:    a,
+				APIKeys: k,
+			})
+
+			r := httptest.NewRequest(fiber.MethodPost, "/api/v1/admin/keys/create", bytes.NewBufferString(tc.inputBody))
+
+			r.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			r.Header.Set(middleware.HeaderAPIKey, tc.apiKey)
+
+			resp, err := h.Test(r)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectBody, string(body))
+		})
+	}
+}
+
+// jwt выдается публичным /authorize, admin маршруты с ним недоступны
+func TestKeysRouter_jwtForbidden(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	a := servicemocks.NewMockAuth(ctrl)
+	a.EXPECT().Validate("TOKEN").Return("subject", true)
+
+	h := fiber.New(fiber.Config{
+		StructValidator: validator.New(),
+	})
+	NewRouter(h, &service.Services{
+		Auth:    a,
+		APIKeys: servicemocks.NewMockAPIKeys(ctrl),
+	})
+
+	r := httptest.NewRequest(fiber.MethodPost, "/api/v1/admin/keys/create", bytes.NewBufferString(`{"Name": "bot", "Scopes": ["admin"]}`))
+	r.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	r.Header.Set(fiber.HeaderAuthorization, "Bearer TOKEN")
+
+	resp, err := h.Test(r)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+}
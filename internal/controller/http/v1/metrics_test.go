@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"test_news/internal/controller/http/middleware"
+	"test_news/internal/metrics"
+	"test_news/internal/mocks/servicemocks"
+	"test_news/internal/service"
+	"testing"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	a := servicemocks.NewMockAuth(ctrl)
+	n := servicemocks.NewMockNews(ctrl)
+
+	a.EXPECT().Validate("TOKEN").Return("subject", true)
+	a.EXPECT().Validate("WRONG").Return("", false)
+	n.EXPECT().FindWithCategories(gomock.Any(), 0, 0).Return(nil, nil)
+
+	h := fiber.New()
+	h.Use(middleware.Metrics)
+	NewRouter(h, &service.Services{
+		Auth: a,
+		News: n,
+	})
+
+	requests := func(route, status string) float64 {
+		return testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(fiber.MethodGet, route, status))
+	}
+	authFailures := func(reason string) float64 {
+		return testutil.ToFloat64(metrics.AuthFailures.WithLabelValues(reason))
+	}
+
+	testCases := []struct {
+		testName     string
+		path         string
+		token        string
+		expectRoute  string
+		expectStatus string
+		expectReason string
+	}{
+		{
+			testName:     "ok",
+			path:         "/api/v1/news/list",
+			token:        "TOKEN",
+			expectRoute:  "/api/v1/news/list",
+			expectStatus: "200",
+		},
+		{
+			testName:     "missing token",
+			path:         "/api/v1/news/list",
+			expectRoute:  "/api/v1",
+			expectStatus: "401",
+			expectReason: metrics.AuthReasonMissingToken,
+		},
+		{
+			testName:     "invalid token",
+			path:         "/api/v1/news/list",
+			token:        "WRONG",
+			expectRoute:  "/api/v1",
+			expectStatus: "403",
+			expectReason: metrics.AuthReasonInvalidToken,
+		},
+		{
+			testName:     "unmatched route",
+			path:         "/foobar/123",
+			expectRoute:  "/",
+			expectStatus: "404",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			before := requests(tc.expectRoute, tc.expectStatus)
+			var beforeAuth float64
+			if tc.expectReason != "" {
+				beforeAuth = authFailures(tc.expectReason)
+			}
+
+			r := httptest.NewRequest(fiber.MethodGet, tc.path, nil)
+			if tc.token != "" {
+				r.Header.Set(fiber.HeaderAuthorization, "Bearer "+tc.token)
+			}
+			_, err := h.Test(r)
+			assert.NoError(t, err)
+
+			assert.Equal(t, before+1, requests(tc.expectRoute, tc.expectStatus))
+			if tc.expectReason != "" {
+				assert.Equal(t, beforeAuth+1, authFailures(tc.expectReason))
+			}
+		})
+	}
+}
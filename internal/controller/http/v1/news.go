@@ -3,22 +3,28 @@ package v1
 import (
 	"github.com/gofiber/fiber/v3"
 	"strconv"
+	"test_news/internal/controller/http/middleware"
 	"test_news/internal/model"
 	"test_news/internal/service"
+	"time"
 )
 
 type newsRouter struct {
-	news service.News
+	news      service.News
+	heartbeat time.Duration
 }
 
-func newNewsRouter(g fiber.Router, news service.News) {
+func newNewsRouter(g fiber.Router, news service.News, heartbeat time.Duration, cache CachePolicies) {
 	r := &newsRouter{
-		news: news,
+		news:      news,
+		heartbeat: heartbeat,
 	}
 
-	g.Post("/create", r.create)
-	g.Post("/edit/:id", r.update)
-	g.Get("/list", r.list)
+	g.Post("/create", middleware.Scope(service.ScopeNewsWrite), r.create)
+	g.Post("/edit/:id", middleware.Scope(service.ScopeNewsWrite), r.update)
+	g.Get("/list", middleware.Scope(service.ScopeNewsRead), middleware.CacheControl(cache.NewsList), r.list)
+	g.Get("/stream", middleware.Scope(service.ScopeNewsRead), r.stream)
+	g.Get("/ws", middleware.Scope(service.ScopeNewsRead), r.websocket)
 }
 
 type newsCreateInput struct {
@@ -27,6 +33,10 @@ type newsCreateInput struct {
 	Categories []int64 `json:"Categories" validate:"required,dive,gt=0"`
 }
 
+type newsCreateResponse struct {
+	Id int64 `json:"Id"`
+}
+
 func (r *newsRouter) create(c fiber.Ctx) error {
 	var input newsCreateInput
 
@@ -42,12 +52,9 @@ func (r *newsRouter) create(c fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
-	response := struct {
-		Id int64 `json:"Id"`
-	}{
+	return c.JSON(newsCreateResponse{
 		Id: id,
-	}
-	return c.JSON(response)
+	})
 }
 
 type newsUpdateInput struct {
@@ -101,6 +108,10 @@ func (r *newsRouter) list(c fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
+	// без Last-Modified: удаление новости не меняет максимальный updated_at страницы, только ETag
+	if etag, _ := middleware.NewsETag(news...); middleware.Conditional(c, etag, time.Time{}) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
 
 	return c.JSON(newsListResponse{
 		Success: true,
@@ -2,23 +2,23 @@ package v1
 
 import (
 	"bytes"
-	"context"
 	"errors"
 	"github.com/gofiber/fiber/v3"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"io"
+	"net/http"
 	"net/http/httptest"
 	"test_news/internal/mocks/servicemocks"
 	"test_news/internal/model"
 	"test_news/internal/service"
 	"test_news/pkg/validator"
 	"testing"
+	"time"
 )
 
 func TestNewsRouter_create(t *testing.T) {
 	type args struct {
-		ctx   context.Context
 		input model.News
 	}
 
@@ -35,7 +35,6 @@ func TestNewsRouter_create(t *testing.T) {
 		{
 			testName: "correct test",
 			args: args{
-				ctx: context.Background(),
 				input: model.News{
 					Title:      "hello world",
 					Content:    "my content",
@@ -43,7 +42,7 @@ func TestNewsRouter_create(t *testing.T) {
 				},
 			},
 			mockBehaviour: func(n *servicemocks.MockNews, a args) {
-				n.EXPECT().Create(a.ctx, a.input).Return(int64(1), nil)
+				n.EXPECT().Create(gomock.Any(), a.input).Return(int64(1), nil)
 			},
 			inputBody:  `{"Title": "hello world", "Content": "my content", "Categories": [1, 2, 3]}`,
 			expectCode: fiber.StatusOK,
@@ -80,7 +79,6 @@ func TestNewsRouter_create(t *testing.T) {
 		{
 			testName: "categories already exists",
 			args: args{
-				ctx: context.Background(),
 				input: model.News{
 					Title:      "hello world",
 					Content:    "my content",
@@ -88,7 +86,7 @@ func TestNewsRouter_create(t *testing.T) {
 				},
 			},
 			mockBehaviour: func(n *servicemocks.MockNews, a args) {
-				n.EXPECT().Create(a.ctx, a.input).Return(int64(0), service.ErrCategoriesAlreadyExists)
+				n.EXPECT().Create(gomock.Any(), a.input).Return(int64(0), service.ErrCategoriesAlreadyExists)
 			},
 			inputBody:  `{"Title": "hello world", "Content": "my content", "Categories": [1, 1]}`,
 			expectCode: fiber.StatusBadRequest,
@@ -97,7 +95,6 @@ func TestNewsRouter_create(t *testing.T) {
 		{
 			testName: "unexpected error",
 			args: args{
-				ctx: context.Background(),
 				input: model.News{
 					Title:      "hello world",
 					Content:    "my content",
@@ -105,7 +102,7 @@ func TestNewsRouter_create(t *testing.T) {
 				},
 			},
 			mockBehaviour: func(n *servicemocks.MockNews, a args) {
-				n.EXPECT().Create(a.ctx, a.input).Return(int64(0), errors.New("some error"))
+				n.EXPECT().Create(gomock.Any(), a.input).Return(int64(0), errors.New("some error"))
 			},
 			inputBody:  `{"Title": "hello world", "Content": "my content", "Categories": [1, 2, 3]}`,
 			expectCode: fiber.StatusInternalServerError,
@@ -120,7 +117,7 @@ func TestNewsRouter_create(t *testing.T) {
 			n := servicemocks.NewMockNews(ctrl)
 			a := servicemocks.NewMockAuth(ctrl)
 
-			a.EXPECT().Validate("TOKEN").Return(true)
+			a.EXPECT().Validate("TOKEN").Return("subject", true)
 
 			tc.mockBehaviour(n, tc.args)
 
@@ -152,7 +149,6 @@ func TestNewsRouter_create(t *testing.T) {
 
 func TestNewsRouter_update(t *testing.T) {
 	type args struct {
-		ctx   context.Context
 		input service.NewsUpdate
 	}
 
@@ -170,7 +166,6 @@ func TestNewsRouter_update(t *testing.T) {
 		{
 			testName: "correct test",
 			args: args{
-				ctx: context.Background(),
 				input: service.NewsUpdate{
 					Id:         1,
 					Title:      ptr("new world"),
@@ -179,7 +174,7 @@ func TestNewsRouter_update(t *testing.T) {
 				},
 			},
 			mockBehaviour: func(n *servicemocks.MockNews, a args) {
-				n.EXPECT().Update(a.ctx, a.input).Return(nil)
+				n.EXPECT().Update(gomock.Any(), a.input).Return(nil)
 			},
 			inputBody:  `{"Title": "new world", "Content": "new content", "Categories": [1, 2, 3]}`,
 			inputId:    "1",
@@ -205,7 +200,6 @@ func TestNewsRouter_update(t *testing.T) {
 		{
 			testName: "news not found",
 			args: args{
-				ctx: context.Background(),
 				input: service.NewsUpdate{
 					Id:         2,
 					Title:      ptr("New title"),
@@ -214,7 +208,7 @@ func TestNewsRouter_update(t *testing.T) {
 				},
 			},
 			mockBehaviour: func(n *servicemocks.MockNews, a args) {
-				n.EXPECT().Update(a.ctx, a.input).Return(service.ErrNewsNotFound)
+				n.EXPECT().Update(gomock.Any(), a.input).Return(service.ErrNewsNotFound)
 			},
 			inputBody:  `{"Title": "New title"}`,
 			inputId:    "2",
@@ -224,7 +218,6 @@ func TestNewsRouter_update(t *testing.T) {
 		{
 			testName: "categories already exists",
 			args: args{
-				ctx: context.Background(),
 				input: service.NewsUpdate{
 					Id:         1,
 					Title:      nil,
@@ -233,7 +226,7 @@ func TestNewsRouter_update(t *testing.T) {
 				},
 			},
 			mockBehaviour: func(n *servicemocks.MockNews, a args) {
-				n.EXPECT().Update(a.ctx, a.input).Return(service.ErrCategoriesAlreadyExists)
+				n.EXPECT().Update(gomock.Any(), a.input).Return(service.ErrCategoriesAlreadyExists)
 			},
 			inputBody:  `{"Categories": [1, 1]}`,
 			inputId:    "1",
@@ -243,7 +236,6 @@ func TestNewsRouter_update(t *testing.T) {
 		{
 			testName: "unexpected error",
 			args: args{
-				ctx: context.Background(),
 				input: service.NewsUpdate{
 					Id:         1,
 					Title:      ptr("hello world"),
@@ -251,7 +243,7 @@ func TestNewsRouter_update(t *testing.T) {
 				},
 			},
 			mockBehaviour: func(n *servicemocks.MockNews, a args) {
-				n.EXPECT().Update(a.ctx, a.input).Return(errors.New("some error"))
+				n.EXPECT().Update(gomock.Any(), a.input).Return(errors.New("some error"))
 			},
 			inputBody:  `{"Title": "hello world", "Categories": [1, 2, 3]}`,
 			inputId:    "1",
@@ -267,7 +259,7 @@ func TestNewsRouter_update(t *testing.T) {
 			n := servicemocks.NewMockNews(ctrl)
 			a := servicemocks.NewMockAuth(ctrl)
 
-			a.EXPECT().Validate("TOKEN").Return(true)
+			a.EXPECT().Validate("TOKEN").Return("subject", true)
 
 			tc.mockBehaviour(n, tc.args)
 
@@ -299,7 +291,6 @@ func TestNewsRouter_update(t *testing.T) {
 
 func TestNewsRouter_find(t *testing.T) {
 	type args struct {
-		ctx    context.Context
 		limit  int
 		offset int
 	}
@@ -316,12 +307,11 @@ func TestNewsRouter_find(t *testing.T) {
 		{
 			testName: "correct test",
 			args: args{
-				ctx:    context.Background(),
 				limit:  10,
 				offset: 0,
 			},
 			mockBehaviour: func(n *servicemocks.MockNews, a args) {
-				n.EXPECT().FindWithCategories(a.ctx, a.limit, a.offset).Return([]model.News{
+				n.EXPECT().FindWithCategories(gomock.Any(), a.limit, a.offset).Return([]model.News{
 					{
 						Id:         1,
 						Title:      "Foobar",
@@ -363,12 +353,11 @@ func TestNewsRouter_find(t *testing.T) {
 		{
 			testName: "unexpected error",
 			args: args{
-				ctx:    context.Background(),
 				limit:  10,
 				offset: 0,
 			},
 			mockBehaviour: func(n *servicemocks.MockNews, a args) {
-				n.EXPECT().FindWithCategories(a.ctx, a.limit, a.offset).Return(nil, errors.New("some error"))
+				n.EXPECT().FindWithCategories(gomock.Any(), a.limit, a.offset).Return(nil, errors.New("some error"))
 			},
 			inputQuery: `limit=10&offset=0`,
 			expectBody: fiber.ErrInternalServerError.Message,
@@ -382,7 +371,7 @@ func TestNewsRouter_find(t *testing.T) {
 			n := servicemocks.NewMockNews(ctrl)
 			a := servicemocks.NewMockAuth(ctrl)
 
-			a.EXPECT().Validate("TOKEN").Return(true)
+			a.EXPECT().Validate("TOKEN").Return("subject", true)
 
 			tc.mockBehaviour(n, tc.args)
 
@@ -410,6 +399,57 @@ func TestNewsRouter_find(t *testing.T) {
 	}
 }
 
+func TestNewsRouter_findConditional(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	n := servicemocks.NewMockNews(ctrl)
+	a := servicemocks.NewMockAuth(ctrl)
+
+	a.EXPECT().Validate("TOKEN").Return("subject", true).Times(3)
+	n.EXPECT().FindWithCategories(gomock.Any(), 10, 0).Return([]model.News{
+		{Id: 1, Title: "Foobar", UpdatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}, nil).Times(2)
+	n.EXPECT().FindWithCategories(gomock.Any(), 10, 0).Return([]model.News{
+		{Id: 1, Title: "Foobar", UpdatedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}, nil)
+
+	h := fiber.New(fiber.Config{
+		StructValidator: validator.New(),
+	})
+	NewRouter(h, &service.Services{
+		Auth: a,
+		News: n,
+	}, WithCachePolicies(CachePolicies{NewsList: "public, max-age=60, s-maxage=600"}))
+
+	request := func(etag string) *http.Response {
+		r := httptest.NewRequest(fiber.MethodGet, "/api/v1/news/list?limit=10", nil)
+		r.Header.Set(fiber.HeaderAuthorization, "Bearer TOKEN")
+		if etag != "" {
+			r.Header.Set(fiber.HeaderIfNoneMatch, etag)
+		}
+		resp, err := h.Test(r)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	resp := request("")
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	// авторизованный ответ не должен попадать в общие кэши
+	assert.Equal(t, "private, max-age=60", resp.Header.Get(fiber.HeaderCacheControl))
+	etag := resp.Header.Get(fiber.HeaderETag)
+	assert.NotEmpty(t, etag)
+
+	resp = request(etag)
+	assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+	assert.Equal(t, etag, resp.Header.Get(fiber.HeaderETag))
+	assert.Equal(t, "private, max-age=60", resp.Header.Get(fiber.HeaderCacheControl))
+
+	// новость изменилась
+	resp = request(etag)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.NotEqual(t, etag, resp.Header.Get(fiber.HeaderETag))
+}
+
 func ptr[T any](t T) *T {
 	return &t
 }
@@ -0,0 +1,203 @@
+package v1
+
+import (
+	_ "embed"
+	"encoding/json"
+	"github.com/gofiber/fiber/v3"
+	"net/http"
+	"test_news/internal/controller/http/middleware"
+	"test_news/pkg/openapi"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+var authErrors = []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests, http.StatusInternalServerError}
+
+func openAPIRoutes() []openapi.Route {
+	secured := []string{middleware.SecurityBearer, middleware.SecurityAPIKey}
+	return []openapi.Route{
+		{
+			Method:   http.MethodGet,
+			Path:     "/ping",
+			Summary:  "Health check",
+			Tags:     []string{"system"},
+			Response: nil,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/authorize",
+			Summary:  "Issue a JWT token",
+			Tags:     []string{"auth"},
+			Response: tokenResponse{},
+			Errors:   []int{http.StatusTooManyRequests, http.StatusInternalServerError},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/api/v1/news/create",
+			Summary:  "Create news (scope news:write)",
+			Tags:     []string{"news"},
+			Security: secured,
+			Body:     newsCreateInput{},
+			Response: newsCreateResponse{},
+			Errors:   append([]int{http.StatusBadRequest}, authErrors...),
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/api/v1/news/edit/:id",
+			Summary:  "Update news, all fields are optional (scope news:write)",
+			Tags:     []string{"news"},
+			Security: secured,
+			Body:     newsUpdateInput{},
+			Errors:   append([]int{http.StatusBadRequest, http.StatusNotFound}, authErrors...),
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/news/list",
+			Summary:  "List news with categories (scope news:read)",
+			Tags:     []string{"news"},
+			Security: secured,
+			Query:    newsPaginationInput{},
+			Response: newsListResponse{},
+			Errors:   append([]int{http.StatusBadRequest}, authErrors...),
+		},
+		{
+			Method:       http.MethodGet,
+			Path:         "/api/v1/news/stream",
+			Summary:      "Stream news changes as Server-Sent Events, resumable by Last-Event-ID (scope news:read)",
+			Tags:         []string{"news"},
+			Security:     secured,
+			ResponseType: mimeEventStream,
+			Errors:       append([]int{http.StatusBadRequest}, authErrors...),
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/news/ws",
+			Summary:  "WebSocket: subscribe to categories and receive changes of their news (scope news:read)",
+			Tags:     []string{"news"},
+			Security: secured,
+			Status:   http.StatusSwitchingProtocols,
+			Errors:   append([]int{http.StatusUpgradeRequired}, authErrors...),
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/api/v1/admin/keys/create",
+			Summary:  "Issue an API key (scope admin)",
+			Tags:     []string{"admin"},
+			Security: secured,
+			Body:     keyCreateInput{},
+			Response: keyCreateResponse{},
+			Errors:   append([]int{http.StatusBadRequest}, authErrors...),
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/admin/keys/list",
+			Summary:  "List API keys (scope admin)",
+			Tags:     []string{"admin"},
+			Security: secured,
+			Response: keyListResponse{},
+			Errors:   authErrors,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/api/v1/admin/keys/revoke/:id",
+			Summary:  "Revoke an API key (scope admin)",
+			Tags:     []string{"admin"},
+			Security: secured,
+			Errors:   append([]int{http.StatusBadRequest, http.StatusNotFound}, authErrors...),
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/admin/audit/list",
+			Summary:  "Query the audit log (scope admin)",
+			Tags:     []string{"admin"},
+			Security: secured,
+			Query:    auditListInput{},
+			Response: auditListResponse{},
+			Errors:   append([]int{http.StatusBadRequest}, authErrors...),
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/api/v1/admin/webhooks/create",
+			Summary:  "Subscribe a URL to news events; the signing secret is returned only once (scope admin)",
+			Tags:     []string{"admin"},
+			Security: secured,
+			Body:     webhookCreateInput{},
+			Response: webhookCreateResponse{},
+			Errors:   append([]int{http.StatusBadRequest}, authErrors...),
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/admin/webhooks/list",
+			Summary:  "List webhook subscriptions (scope admin)",
+			Tags:     []string{"admin"},
+			Security: secured,
+			Response: webhookListResponse{},
+			Errors:   authErrors,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/api/v1/admin/webhooks/delete/:id",
+			Summary:  "Delete a webhook subscription (scope admin)",
+			Tags:     []string{"admin"},
+			Security: secured,
+			Errors:   append([]int{http.StatusBadRequest, http.StatusNotFound}, authErrors...),
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/admin/webhooks/deliveries/:id",
+			Summary:  "Delivery log of a webhook subscription, newest first (scope admin)",
+			Tags:     []string{"admin"},
+			Security: secured,
+			Query:    webhookDeliveriesInput{},
+			Response: webhookDeliveriesResponse{},
+			Errors:   append([]int{http.StatusBadRequest}, authErrors...),
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v1/admin/cache/stats",
+			Summary:  "News read cache hit/miss counters (scope admin)",
+			Tags:     []string{"admin"},
+			Security: secured,
+			Response: cacheStatsResponse{},
+			Errors:   authErrors,
+		},
+	}
+}
+
+func newOpenAPIDocument(extra ...openapi.Route) *openapi.Document {
+	doc := openapi.NewDocument(openapi.Info{
+		Title:   "test_news",
+		Version: "1.0.0",
+	})
+	doc.AddSecurityScheme(middleware.SecurityBearer, &openapi.SecurityScheme{
+		Type:         "http",
+		Scheme:       "bearer",
+		BearerFormat: "JWT",
+	})
+	doc.AddSecurityScheme(middleware.SecurityAPIKey, &openapi.SecurityScheme{
+		Type: "apiKey",
+		In:   "header",
+		Name: middleware.HeaderAPIKey,
+	})
+	doc.Add(openAPIRoutes()...)
+	doc.Add(extra...)
+	return doc
+}
+
+func newDocsRouter(g fiber.Router, extra ...openapi.Route) {
+	spec, err := json.Marshal(newOpenAPIDocument(extra...))
+	if err != nil {
+		// документ строится из статических типов, ошибка здесь - ошибка программиста
+		panic(err)
+	}
+
+	g.Get("/openapi.json", func(c fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		return c.Send(spec)
+	})
+	g.Get("/docs", func(c fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.Send(docsPage)
+	})
+}
@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"encoding/json"
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"test_news/internal/controller/http/feeds"
+	"test_news/internal/controller/http/gql"
+	"test_news/internal/controller/http/probes"
+	v2 "test_news/internal/controller/http/v2"
+	"test_news/internal/service"
+	"test_news/pkg/health"
+	"test_news/pkg/openapi"
+	"testing"
+	"time"
+)
+
+// маршруты, которые не описываются в спецификации
+var undocumentedRoutes = map[string]bool{
+	"GET /openapi.json": true,
+	"GET /docs":         true,
+}
+
+// TestOpenAPI_routesDrift - маршруты v1 и роутеров, добавленных в спецификацию через WithOpenAPIRoutes
+func TestOpenAPI_routesDrift(t *testing.T) {
+	services := &service.Services{}
+	extra := slices.Concat(probes.OpenAPIRoutes(), v2.OpenAPIRoutes(), gql.OpenAPIRoutes(), feeds.OpenAPIRoutes())
+
+	h := fiber.New()
+	probes.NewRouter(h, health.NewChecker(time.Second))
+	NewRouter(h, services, WithOpenAPIRoutes(extra...))
+	v2.NewRouter(h, services)
+	gql.NewRouter(h, services)
+	feeds.NewRouter(h, services)
+
+	var registered []string
+	seen := make(map[string]bool)
+	for _, r := range h.GetRoutes(true) {
+		if r.Method == fiber.MethodHead {
+			continue
+		}
+		route := r.Method + " " + openapi.ToOpenAPIPath(r.Path)
+		if undocumentedRoutes[route] || seen[route] {
+			continue
+		}
+		seen[route] = true
+		registered = append(registered, route)
+	}
+	sort.Strings(registered)
+
+	assert.Equal(t, registered, newOpenAPIDocument(extra...).Operations(), "openapi spec is out of sync with registered routes")
+}
+
+func TestOpenAPI_serve(t *testing.T) {
+	h := fiber.New()
+	NewRouter(h, &service.Services{})
+
+	resp, err := h.Test(httptest.NewRequest(fiber.MethodGet, "/openapi.json", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	var doc struct {
+		OpenAPI    string                     `json:"openapi"`
+		Paths      map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &doc))
+
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	assert.Contains(t, doc.Paths, "/api/v1/news/edit/{id}")
+	assert.Contains(t, doc.Components.Schemas, "newsCreateInput")
+	assert.Contains(t, doc.Components.Schemas, "newsListResponse")
+	assert.Contains(t, doc.Components.Schemas, "News")
+
+	resp, err = h.Test(httptest.NewRequest(fiber.MethodGet, "/docs", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	// версия бандла закреплена, чтобы обновление Redoc не меняло страницу незаметно
+	body, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `<redoc spec-url="/openapi.json"></redoc>`)
+	assert.Contains(t, string(body), `src="https://cdn.redoc.ly/redoc/v2.1.5/bundles/redoc.standalone.js"`)
+}
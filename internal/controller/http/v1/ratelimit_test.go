@@ -0,0 +1,101 @@
+package v1
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"test_news/internal/controller/http/middleware"
+	"test_news/internal/mocks/servicemocks"
+	"test_news/internal/service"
+	"test_news/pkg/ratelimit"
+	"testing"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	a := servicemocks.NewMockAuth(ctrl)
+	k := servicemocks.NewMockAPIKeys(ctrl)
+	n := servicemocks.NewMockNews(ctrl)
+
+	a.EXPECT().Validate("TOKEN 1").Return("subject 1", true).Times(3)
+	a.EXPECT().Validate("TOKEN 2").Return("subject 2", true)
+	k.EXPECT().Validate(gomock.Any(), "BOT KEY").Return(service.Principal{
+		Subject: "apikey:2",
+		Scopes:  []string{service.ScopeNewsRead},
+	}, nil)
+	n.EXPECT().FindWithCategories(gomock.Any(), 0, 0).Return(nil, nil).Times(3)
+
+	h := fiber.New()
+	NewRouter(h, &service.Services{
+		Auth:    a,
+		APIKeys: k,
+		News:    n,
+	}, WithRateLimit(ratelimit.NewMemoryStore(), RateLimits{
+		News: ratelimit.Limit{Rate: 0.5, Burst: 2},
+	}))
+
+	testCases := []struct {
+		testName        string
+		token           string
+		apiKey          string
+		expectCode      int
+		expectRemaining string
+		expectRetry     string
+	}{
+		{
+			testName:        "first request",
+			token:           "TOKEN 1",
+			expectCode:      fiber.StatusOK,
+			expectRemaining: "1",
+		},
+		{
+			testName:        "second request",
+			token:           "TOKEN 1",
+			expectCode:      fiber.StatusOK,
+			expectRemaining: "0",
+		},
+		{
+			testName:        "limit exceeded",
+			token:           "TOKEN 1",
+			expectCode:      fiber.StatusTooManyRequests,
+			expectRemaining: "0",
+			expectRetry:     "2",
+		},
+		{
+			// новый jwt токен не дает новый лимит: jwt клиенты считаются по ip
+			testName:        "another token",
+			token:           "TOKEN 2",
+			expectCode:      fiber.StatusTooManyRequests,
+			expectRemaining: "0",
+			expectRetry:     "2",
+		},
+		{
+			testName:        "api key",
+			apiKey:          "BOT KEY",
+			expectCode:      fiber.StatusOK,
+			expectRemaining: "1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			r := httptest.NewRequest(fiber.MethodGet, "/api/v1/news/list", nil)
+			if tc.token != "" {
+				r.Header.Set(fiber.HeaderAuthorization, "Bearer "+tc.token)
+			}
+			if tc.apiKey != "" {
+				r.Header.Set(middleware.HeaderAPIKey, tc.apiKey)
+			}
+
+			resp, err := h.Test(r)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectCode, resp.StatusCode)
+			assert.Equal(t, "2", resp.Header.Get(middleware.HeaderRateLimitLimit))
+			assert.Equal(t, tc.expectRemaining, resp.Header.Get(middleware.HeaderRateLimitRemaining))
+			assert.Equal(t, tc.expectRetry, resp.Header.Get(fiber.HeaderRetryAfter))
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"strings"
+	"test_news/internal/controller/http/middleware"
+	"test_news/internal/mocks/servicemocks"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	"testing"
+)
+
+func TestRequestIDAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	prev := log.Logger
+	log.Logger = zerolog.New(&buf)
+	t.Cleanup(func() { log.Logger = prev })
+
+	ctrl := gomock.NewController(t)
+
+	a := servicemocks.NewMockAuth(ctrl)
+	n := servicemocks.NewMockNews(ctrl)
+
+	a.EXPECT().Validate("TOKEN").Return("subject", true).AnyTimes()
+	n.EXPECT().FindWithCategories(gomock.Any(), 0, 0).DoAndReturn(func(ctx context.Context, _, _ int) ([]model.News, error) {
+		// логгер из ctx сервиса - логгер запроса
+		zerolog.Ctx(ctx).Info().Msg("from service")
+		return nil, nil
+	}).Times(3)
+
+	h := fiber.New()
+	h.Use(middleware.RequestID, middleware.AccessLog)
+	NewRouter(h, &service.Services{
+		Auth: a,
+		News: n,
+	})
+
+	testCases := []struct {
+		testName  string
+		requestId string
+		expectId  string
+	}{
+		{
+			testName:  "incoming id",
+			requestId: "req-1",
+			expectId:  "req-1",
+		},
+		{
+			testName:  "generated id",
+			requestId: "",
+		},
+		{
+			testName:  "invalid incoming id",
+			requestId: strings.Repeat("a", 129),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			buf.Reset()
+
+			r := httptest.NewRequest(fiber.MethodGet, "/api/v1/news/list", nil)
+			r.Header.Set(fiber.HeaderAuthorization, "Bearer TOKEN")
+			if tc.requestId != "" {
+				r.Header.Set(fiber.HeaderXRequestID, tc.requestId)
+			}
+			resp, err := h.Test(r)
+			assert.NoError(t, err)
+
+			id := resp.Header.Get(fiber.HeaderXRequestID)
+			if tc.expectId != "" {
+				assert.Equal(t, tc.expectId, id)
+			} else {
+				assert.NotEmpty(t, id)
+				assert.NotEqual(t, tc.requestId, id)
+			}
+
+			lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+			if len(lines) != 2 {
+				t.Fatalf("expected 2 log lines, got %d: %s", len(lines), buf.String())
+			}
+			var serviceEntry, accessEntry map[string]any
+			if err = json.Unmarshal(lines[0], &serviceEntry); err != nil {
+				t.Fatal(err)
+			}
+			if err = json.Unmarshal(lines[1], &accessEntry); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, "from service", serviceEntry["message"])
+			assert.Equal(t, id, serviceEntry["request_id"])
+
+			assert.Equal(t, "http request", accessEntry["message"])
+			assert.Equal(t, id, accessEntry["request_id"])
+			assert.Equal(t, fiber.MethodGet, accessEntry["method"])
+			assert.Equal(t, "/api/v1/news/list", accessEntry["route"])
+			assert.Equal(t, float64(fiber.StatusOK), accessEntry["status"])
+			assert.Equal(t, "jwt:subject", accessEntry["user"])
+			assert.Contains(t, accessEntry, "latency")
+			assert.Contains(t, accessEntry, "bytes")
+		})
+	}
+
+	t.Run("unauthorized", func(t *testing.T) {
+		buf.Reset()
+
+		r := httptest.NewRequest(fiber.MethodGet, "/api/v1/news/list", nil)
+		r.Header.Set(fiber.HeaderXRequestID, "req-2")
+		_, err := h.Test(r)
+		assert.NoError(t, err)
+
+		// предупреждение auth middleware и access log с одним request_id
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+		if len(lines) != 2 {
+			t.Fatalf("expected 2 log lines, got %d: %s", len(lines), buf.String())
+		}
+		for _, line := range lines {
+			var entry map[string]any
+			if err = json.Unmarshal(line, &entry); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, "req-2", entry["request_id"])
+		}
+	})
+}
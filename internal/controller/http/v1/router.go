@@ -2,34 +2,106 @@ package v1
 
 import (
 	"github.com/gofiber/fiber/v3"
+	"test_news/internal/controller/http/middleware"
 	"test_news/internal/service"
+	"test_news/pkg/openapi"
+	"test_news/pkg/ratelimit"
+	"time"
 )
 
-func NewRouter(g fiber.Router, services *service.Services) {
+type RateLimits struct {
+	Auth  ratelimit.Limit
+	News  ratelimit.Limit
+	Admin ratelimit.Limit
+}
+
+const defaultNewsCachePolicy = "no-cache"
+
+// CachePolicies - значения Cache-Control для GET маршрутов; ответы v1 требуют авторизации, поэтому всегда private
+type CachePolicies struct {
+	NewsList string
+}
+
+type options struct {
+	limiter   ratelimit.Store
+	limits    RateLimits
+	docs      []openapi.Route
+	heartbeat time.Duration
+	cache     CachePolicies
+}
+
+type Option func(o *options)
+
+func WithRateLimit(store ratelimit.Store, limits RateLimits) Option {
+	return func(o *options) {
+		o.limiter = store
+		o.limits = limits
+	}
+}
+
+// WithOpenAPIRoutes добавляет в спецификацию маршруты, зарегистрированные вне v1
+func WithOpenAPIRoutes(routes ...openapi.Route) Option {
+	return func(o *options) {
+		o.docs = append(o.docs, routes...)
+	}
+}
+
+// WithStreamHeartbeat задает интервал комментариев-heartbeat в /news/stream, чтобы прокси не закрывали соединение
+func WithStreamHeartbeat(d time.Duration) Option {
+	return func(o *options) {
+		o.heartbeat = d
+	}
+}
+
+func WithCachePolicies(policies CachePolicies) Option {
+	return func(o *options) {
+		o.cache = policies
+	}
+}
+
+func NewRouter(g fiber.Router, services *service.Services, opts ...Option) {
+	o := &options{
+		heartbeat: defaultStreamHeartbeat,
+		cache: CachePolicies{
+			NewsList: defaultNewsCachePolicy,
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	g.Get("/ping", ping)
-	g.Get("/authorize", createTokenHandler(services.Auth))
+	newDocsRouter(g, o.docs...)
+	g.Get("/authorize", middleware.RateLimit(o.limiter, "auth", o.limits.Auth), createTokenHandler(services.Auth))
+
+	g.Use(middleware.Error)
 
-	g.Use(errorMiddleware)
+	v1 := g.Group("/api/v1", middleware.Auth(services.Auth, services.APIKeys))
+	newNewsRouter(v1.Group("/news", middleware.RateLimit(o.limiter, "news", o.limits.News)), services.News, o.heartbeat, o.cache)
 
-	v1 := g.Group("/api/v1", authMiddleware(services.Auth))
-	newNewsRouter(v1.Group("/news"), services.News)
+	admin := v1.Group("/admin", middleware.Scope(service.ScopeAdmin), middleware.RateLimit(o.limiter, "admin", o.limits.Admin))
+	newKeysRouter(admin.Group("/keys"), services.APIKeys)
+	newAuditRouter(admin.Group("/audit"), services.Audit)
+	newWebhooksRouter(admin.Group("/webhooks"), services.Webhooks)
+	newCacheRouter(admin.Group("/cache"), services.Cache)
 }
 
 func ping(c fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusOK)
 }
 
+type tokenResponse struct {
+	Token string `json:"token"`
+}
+
 func createTokenHandler(auth service.Auth) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		token, err := auth.Create()
 		if err != nil {
 			return err
 		}
-		response := struct {
-			Token string `json:"token"`
-		}{
+		return c.JSON(tokenResponse{
 			Token: token,
-		}
-		return c.JSON(response)
+		})
 	}
 }
@@ -0,0 +1,255 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v3"
+	"github.com/rs/zerolog/log"
+	"slices"
+	"sync"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	"time"
+)
+
+const (
+	socketSendBuffer = 32
+	socketReadLimit  = 4 << 10
+	socketWriteWait  = 10 * time.Second
+	socketPongWait   = 60 * time.Second
+	socketPingPeriod = socketPongWait * 9 / 10
+
+	socketActionSubscribe   = "subscribe"
+	socketActionUnsubscribe = "unsubscribe"
+
+	socketMessageEvent      = "event"
+	socketMessageSubscribed = "subscribed"
+	socketMessageError      = "error"
+)
+
+var socketUpgrader = websocket.FastHTTPUpgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// socketRequest - сообщение клиента: {"Action": "subscribe", "Categories": [1, 2]}
+type socketRequest struct {
+	Action     string  `json:"Action"`
+	Categories []int64 `json:"Categories"`
+}
+
+type socketEvent struct {
+	Type  string     `json:"Type"`
+	Id    int64      `json:"Id"`
+	Event string     `json:"Event"`
+	News  model.News `json:"News"`
+}
+
+type socketSubscribed struct {
+	Type       string  `json:"Type"`
+	Categories []int64 `json:"Categories"`
+}
+
+type socketError struct {
+	Type  string `json:"Type"`
+	Error string `json:"Error"`
+}
+
+// socket - одно websocket соединение. Писать в conn может только writeLoop, остальные горутины
+// кладут сообщения в ограниченный send; переполнение означает медленного клиента, и он отключается
+type socket struct {
+	conn *websocket.Conn
+	send chan any
+	kick chan []byte
+
+	mu         sync.Mutex
+	categories map[int64]struct{}
+}
+
+func newSocket(conn *websocket.Conn) *socket {
+	return &socket{
+		conn:       conn,
+		send:       make(chan any, socketSendBuffer),
+		kick:       make(chan []byte, 1),
+		categories: make(map[int64]struct{}),
+	}
+}
+
+// websocket - двунаправленный канал: клиент подписывается на категории и получает изменения их новостей
+func (r *newsRouter) websocket(c fiber.Ctx) error {
+	if !websocket.FastHTTPIsWebSocketUpgrade(c.RequestCtx()) {
+		return c.SendStatus(fiber.StatusUpgradeRequired)
+	}
+
+	// после Upgrade fiber.Ctx уже не действителен, обработчик соединения работает сам по себе
+	err := socketUpgrader.Upgrade(c.RequestCtx(), func(conn *websocket.Conn) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		s := newSocket(conn)
+		events := r.news.Watch(ctx, 0)
+
+		go s.writeLoop(ctx)
+		go s.fanOut(ctx, events)
+		s.readLoop()
+	})
+	if err != nil {
+		// ответ с ошибкой рукопожатия уже записан upgrader'ом
+		log.Debug().Err(err).Msg("websocket upgrade error")
+	}
+	return nil
+}
+
+func (s *socket) readLoop() {
+	defer func() { _ = s.conn.Close() }()
+
+	s.conn.SetReadLimit(socketReadLimit)
+	_ = s.conn.SetReadDeadline(time.Now().Add(socketPongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(socketPongWait))
+	})
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req socketRequest
+		if err = json.Unmarshal(data, &req); err != nil {
+			s.reply(socketError{Type: socketMessageError, Error: "invalid message"})
+			continue
+		}
+		if slices.ContainsFunc(req.Categories, func(id int64) bool { return id <= 0 }) {
+			s.reply(socketError{Type: socketMessageError, Error: "invalid category id"})
+			continue
+		}
+
+		switch req.Action {
+		case socketActionSubscribe:
+			s.reply(socketSubscribed{Type: socketMessageSubscribed, Categories: s.subscribe(req.Categories)})
+		case socketActionUnsubscribe:
+			s.reply(socketSubscribed{Type: socketMessageSubscribed, Categories: s.unsubscribe(req.Categories)})
+		default:
+			s.reply(socketError{Type: socketMessageError, Error: "unknown action"})
+		}
+	}
+}
+
+func (s *socket) writeLoop(ctx context.Context) {
+	ping := time.NewTicker(socketPingPeriod)
+	defer func() {
+		ping.Stop()
+		// разрывает readLoop, если запись завершилась первой
+		_ = s.conn.Close()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-s.send:
+			_ = s.conn.SetWriteDeadline(time.Now().Add(socketWriteWait))
+			if err := s.conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(socketWriteWait)); err != nil {
+				return
+			}
+		case msg := <-s.kick:
+			_ = s.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(socketWriteWait))
+			return
+		}
+	}
+}
+
+// fanOut отбирает события подписанных категорий
+func (s *socket) fanOut(ctx context.Context, events <-chan service.NewsEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				// сервис отключил подписку: клиент не успевал читать или сервер останавливается
+				s.disconnect("events subscription closed")
+				return
+			}
+			if !s.deliver(event) {
+				return
+			}
+		}
+	}
+}
+
+// deliver возвращает false, если клиент не успевает читать и отключается
+func (s *socket) deliver(event service.NewsEvent) bool {
+	if !s.matches(event.Categories) {
+		return true
+	}
+	select {
+	case s.send <- socketEvent{Type: socketMessageEvent, Id: event.Id, Event: event.Type, News: event.News}:
+		return true
+	default:
+		s.disconnect("slow consumer")
+		return false
+	}
+}
+
+func (s *socket) reply(msg any) {
+	select {
+	case s.send <- msg:
+	default:
+		s.disconnect("slow consumer")
+	}
+}
+
+func (s *socket) disconnect(reason string) {
+	select {
+	case s.kick <- websocket.FormatCloseMessage(websocket.CloseTryAgainLater, reason):
+	default:
+	}
+}
+
+func (s *socket) matches(categories []int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range categories {
+		if _, ok := s.categories[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *socket) subscribe(categories []int64) []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range categories {
+		s.categories[id] = struct{}{}
+	}
+	return s.subscribed()
+}
+
+func (s *socket) unsubscribe(categories []int64) []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range categories {
+		delete(s.categories, id)
+	}
+	return s.subscribed()
+}
+
+func (s *socket) subscribed() []int64 {
+	result := make([]int64, 0, len(s.categories))
+	for id := range s.categories {
+		result = append(result, id)
+	}
+	slices.Sort(result)
+	return result
+}
@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"test_news/internal/mocks/servicemocks"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	"testing"
+	"time"
+)
+
+func TestNewsRouter_websocket(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	n := servicemocks.NewMockNews(ctrl)
+	a := servicemocks.NewMockAuth(ctrl)
+
+	events := make(chan service.NewsEvent, 2)
+	a.EXPECT().Validate("TOKEN").Return("subject", true)
+	n.EXPECT().Watch(gomock.Any(), int64(0)).Return(events)
+
+	h := fiber.New()
+	NewRouter(h, &service.Services{
+		Auth: a,
+		News: n,
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = h.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true}) }()
+	defer func() { _ = h.Shutdown() }()
+
+	header := http.Header{}
+	header.Set(fiber.HeaderAuthorization, "Bearer TOKEN")
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+ln.Addr().String()+"/api/v1/news/ws", header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var subscribed socketSubscribed
+	assert.NoError(t, conn.WriteJSON(socketRequest{Action: socketActionSubscribe, Categories: []int64{3, 1}}))
+	assert.NoError(t, conn.ReadJSON(&subscribed))
+	assert.Equal(t, socketSubscribed{Type: socketMessageSubscribed, Categories: []int64{1, 3}}, subscribed)
+
+	// событие чужой категории не доходит до клиента
+	events <- service.NewsEvent{Id: 1, Type: service.NewsEventCreated, News: model.News{Id: 1}, Categories: []int64{2}}
+	events <- service.NewsEvent{Id: 2, Type: service.NewsEventUpdated, News: model.News{Id: 2}, Categories: []int64{1, 2}}
+
+	var event socketEvent
+	assert.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, socketEvent{Type: socketMessageEvent, Id: 2, Event: service.NewsEventUpdated, News: model.News{Id: 2}}, event)
+
+	assert.NoError(t, conn.WriteJSON(socketRequest{Action: socketActionUnsubscribe, Categories: []int64{3}}))
+	assert.NoError(t, conn.ReadJSON(&subscribed))
+	assert.Equal(t, []int64{1}, subscribed.Categories)
+
+	var socketErr socketError
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"Action": "foobar"}`)))
+	assert.NoError(t, conn.ReadJSON(&socketErr))
+	assert.Equal(t, "unknown action", socketErr.Error)
+
+	// сервис закрыл подписку - клиент отключается с кодом 1013
+	close(events)
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseTryAgainLater), err)
+}
+
+func TestNewsRouter_websocketUpgradeRequired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	a := servicemocks.NewMockAuth(ctrl)
+	a.EXPECT().Validate("TOKEN").Return("subject", true)
+
+	h := fiber.New()
+	NewRouter(h, &service.Services{
+		Auth: a,
+		News: servicemocks.NewMockNews(ctrl),
+	})
+
+	r := httptest.NewRequest(fiber.MethodGet, "/api/v1/news/ws", nil)
+	r.Header.Set(fiber.HeaderAuthorization, "Bearer TOKEN")
+
+	resp, err := h.Test(r)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusUpgradeRequired, resp.StatusCode)
+}
+
+func TestSocket_slowConsumer(t *testing.T) {
+	s := newSocket(nil)
+	s.subscribe([]int64{1})
+
+	event := service.NewsEvent{Type: service.NewsEventCreated, Categories: []int64{1}}
+	for i := 0; i < socketSendBuffer; i++ {
+		assert.True(t, s.deliver(event))
+	}
+	// неподписанные категории не занимают буфер
+	assert.True(t, s.deliver(service.NewsEvent{Categories: []int64{2}}))
+
+	assert.False(t, s.deliver(event))
+	assert.Equal(t, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "slow consumer"), <-s.kick)
+}
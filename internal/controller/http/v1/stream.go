@@ -0,0 +1,73 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber/v3"
+	"strconv"
+	"test_news/internal/service"
+	"time"
+)
+
+const (
+	defaultStreamHeartbeat = 15 * time.Second
+	// streamRetry - через сколько браузер переподключается после обрыва
+	streamRetry = 3 * time.Second
+
+	headerLastEventID = "Last-Event-ID"
+	mimeEventStream   = "text/event-stream"
+)
+
+// stream отдает изменения новостей как Server-Sent Events: id - номер события, event - тип, data - новость в JSON.
+// По Last-Event-ID клиент получает пропущенные события, если они еще есть в буфере сервиса
+func (r *newsRouter) stream(c fiber.Ctx) error {
+	var lastEventId int64
+	if h := c.Get(headerLastEventID); h != "" {
+		id, err := strconv.ParseInt(h, 10, 64)
+		if err != nil {
+			return c.SendStatus(fiber.StatusBadRequest)
+		}
+		lastEventId = id
+	}
+
+	// c.Context() нельзя использовать после выхода из обработчика, а поток пишется позже
+	ctx, cancel := context.WithCancel(context.Background())
+	events := r.news.Watch(ctx, lastEventId)
+
+	c.Set(fiber.HeaderContentType, mimeEventStream)
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set("X-Accel-Buffering", "no")
+
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		heartbeat := time.NewTicker(r.heartbeat)
+		defer heartbeat.Stop()
+
+		_, _ = fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds())
+		for {
+			if err := w.Flush(); err != nil {
+				// клиент отключился
+				return
+			}
+
+			select {
+			case event, ok := <-events:
+				if !ok {
+					// подписчик не успевал читать: клиент переподключится с Last-Event-ID
+					return
+				}
+				writeEvent(w, event)
+			case <-heartbeat.C:
+				_, _ = w.WriteString(": heartbeat\n\n")
+			}
+		}
+	})
+}
+
+func writeEvent(w *bufio.Writer, event service.NewsEvent) {
+	data, _ := json.Marshal(event.News)
+	_, _ = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data)
+}
@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"test_news/internal/mocks/servicemocks"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	"testing"
+	"time"
+)
+
+func TestNewsRouter_stream(t *testing.T) {
+	type mockBehaviour func(n *servicemocks.MockNews)
+
+	testCases := []struct {
+		testName       string
+		mockBehaviour  mockBehaviour
+		lastEventId    string
+		expectCode     int
+		expectBody     string
+		expectContains string
+	}{
+		{
+			testName: "resume from last event id",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				events := make(chan service.NewsEvent, 2)
+				events <- service.NewsEvent{Id: 6, Type: service.NewsEventCreated, News: model.News{Id: 1, Title: "hello world", Content: "my content", Categories: []int64{1}}}
+				events <- service.NewsEvent{Id: 7, Type: service.NewsEventDeleted, News: model.News{Id: 2}}
+				close(events)
+				n.EXPECT().Watch(gomock.Any(), int64(5)).Return(events)
+			},
+			lastEventId: "5",
+			expectCode:  fiber.StatusOK,
+			expectBody: "retry: 3000\n\n" +
+				"id: 6\nevent: created\ndata: {\"Id\":1,\"Title\":\"hello world\",\"Content\":\"my content\",\"Categories\":[1]}\n\n" +
+				"id: 7\nevent: deleted\ndata: {\"Id\":2,\"Title\":\"\",\"Content\":\"\",\"Categories\":null}\n\n",
+		},
+		{
+			testName: "heartbeat",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				events := make(chan service.NewsEvent)
+				time.AfterFunc(100*time.Millisecond, func() { close(events) })
+				n.EXPECT().Watch(gomock.Any(), int64(0)).Return(events)
+			},
+			expectCode:     fiber.StatusOK,
+			expectContains: ": heartbeat\n\n",
+		},
+		{
+			testName:      "incorrect last event id",
+			mockBehaviour: func(n *servicemocks.MockNews) {},
+			lastEventId:   "foobar",
+			expectCode:    fiber.StatusBadRequest,
+			expectBody:    fiber.ErrBadRequest.Message,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			n := servicemocks.NewMockNews(ctrl)
+			a := servicemocks.NewMockAuth(ctrl)
+
+			a.EXPECT().Validate("TOKEN").Return("subject", true)
+
+			tc.mockBehaviour(n)
+
+			h := fiber.New()
+			NewRouter(h, &service.Services{
+				Auth: a,
+				News: n,
+			}, WithStreamHeartbeat(10*time.Millisecond))
+
+			r := httptest.NewRequest(fiber.MethodGet, "/api/v1/news/stream", nil)
+
+			r.Header.Set(fiber.HeaderAuthorization, "Bearer TOKEN")
+			if tc.lastEventId != "" {
+				r.Header.Set(headerLastEventID, tc.lastEventId)
+			}
+
+			resp, err := h.Test(r)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			if tc.expectCode == fiber.StatusOK {
+				assert.Equal(t, mimeEventStream, resp.Header.Get(fiber.HeaderContentType))
+			}
+			if tc.expectBody != "" {
+				assert.Equal(t, tc.expectBody, string(body))
+			}
+			if tc.expectContains != "" {
+				assert.True(t, strings.Contains(string(body), tc.expectContains))
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http/httptest"
+	"test_news/internal/controller/http/middleware"
+	"test_news/internal/mocks/servicemocks"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	"test_news/pkg/tracing"
+	"testing"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tracing.NewProvider(sdktrace.NewSimpleSpanProcessor(exporter), tracing.Config{SampleRatio: 1}))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	ctrl := gomock.NewController(t)
+
+	a := servicemocks.NewMockAuth(ctrl)
+	n := servicemocks.NewMockNews(ctrl)
+
+	a.EXPECT().Validate("TOKEN").Return("subject", true).AnyTimes()
+	gomock.InOrder(
+		n.EXPECT().FindWithCategories(gomock.Any(), 0, 0).DoAndReturn(func(ctx context.Context, _, _ int) ([]model.News, error) {
+			// сервис получает ctx со span'ом запроса
+			assert.True(t, trace.SpanContextFromContext(ctx).IsValid())
+			return nil, nil
+		}),
+		n.EXPECT().FindWithCategories(gomock.Any(), 0, 0).Return(nil, errors.New("some error")),
+	)
+
+	h := fiber.New()
+	h.Use(middleware.Tracing)
+	NewRouter(h, &service.Services{
+		Auth: a,
+		News: n,
+	})
+
+	const (
+		traceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanId  = "00f067aa0ba902b7"
+	)
+
+	testCases := []struct {
+		testName     string
+		path         string
+		traceparent  string
+		expectName   string
+		expectStatus int
+		expectCode   codes.Code
+	}{
+		{
+			testName:     "traceparent",
+			path:         "/api/v1/news/list",
+			traceparent:  "00-" + traceId + "-" + spanId + "-01",
+			expectName:   "GET /api/v1/news/list",
+			expectStatus: fiber.StatusOK,
+			expectCode:   codes.Unset,
+		},
+		{
+			testName:     "internal error",
+			path:         "/api/v1/news/list",
+			expectName:   "GET /api/v1/news/list",
+			expectStatus: fiber.StatusInternalServerError,
+			expectCode:   codes.Error,
+		},
+		{
+			testName:     "unmatched route",
+			path:         "/foobar/123",
+			expectName:   "GET /",
+			expectStatus: fiber.StatusNotFound,
+			expectCode:   codes.Unset,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			exporter.Reset()
+
+			r := httptest.NewRequest(fiber.MethodGet, tc.path, nil)
+			r.Header.Set(fiber.HeaderAuthorization, "Bearer TOKEN")
+			if tc.traceparent != "" {
+				r.Header.Set("traceparent", tc.traceparent)
+			}
+			resp, err := h.Test(r)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectStatus, resp.StatusCode)
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 span, got %d", len(spans))
+			}
+			span := spans[0]
+			assert.Equal(t, tc.expectName, span.Name)
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+			assert.Equal(t, tc.expectCode, span.Status.Code)
+			assert.Contains(t, span.Attributes, semconv.HTTPResponseStatusCode(tc.expectStatus))
+			assert.Contains(t, span.Attributes, semconv.URLPath(tc.path))
+
+			if tc.traceparent != "" {
+				assert.Equal(t, traceId, span.SpanContext.TraceID().String())
+				assert.Equal(t, spanId, span.Parent.SpanID().String())
+				assert.True(t, span.Parent.IsRemote())
+			} else {
+				assert.False(t, span.Parent.IsValid())
+			}
+		})
+	}
+}
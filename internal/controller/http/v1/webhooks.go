@@ -0,0 +1,117 @@
+package v1
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"strconv"
+	"test_news/internal/model"
+	"test_news/internal/service"
+)
+
+const defaultDeliveriesLimit = 50
+
+type webhooksRouter struct {
+	webhooks service.Webhooks
+}
+
+func newWebhooksRouter(g fiber.Router, webhooks service.Webhooks) {
+	r := &webhooksRouter{
+		webhooks: webhooks,
+	}
+
+	g.Post("/create", r.create)
+	g.Get("/list", r.list)
+	g.Post("/delete/:id", r.delete)
+	g.Get("/deliveries/:id", r.deliveries)
+}
+
+type webhookCreateInput struct {
+	URL        string   `json:"URL" validate:"required,url"`
+	Events     []string `json:"Events" validate:"required,min=1"`
+	Categories []int64  `json:"Categories"`
+}
+
+type webhookCreateResponse struct {
+	Id     int64  `json:"Id"`
+	Secret string `json:"Secret"`
+}
+
+func (r *webhooksRouter) create(c fiber.Ctx) error {
+	var input webhookCreateInput
+
+	if err := c.Bind().Body(&input); err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	webhook, err := r.webhooks.Create(c.Context(), service.WebhookCreate{
+		URL:        input.URL,
+		Events:     input.Events,
+		Categories: input.Categories,
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(webhookCreateResponse{
+		Id:     webhook.Id,
+		Secret: webhook.Secret,
+	})
+}
+
+type webhookListResponse struct {
+	Success  bool            `json:"Success"`
+	Webhooks []model.Webhook `json:"Webhooks"`
+}
+
+func (r *webhooksRouter) list(c fiber.Ctx) error {
+	webhooks, err := r.webhooks.List(c.Context())
+	if err != nil {
+		return err
+	}
+	return c.JSON(webhookListResponse{
+		Success:  true,
+		Webhooks: webhooks,
+	})
+}
+
+func (r *webhooksRouter) delete(c fiber.Ctx) error {
+	id, err := fiber.Convert(c.Params("id"), strconv.Atoi)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	if err = r.webhooks.Delete(c.Context(), int64(id)); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+type webhookDeliveriesInput struct {
+	Limit int `query:"limit" validate:"gte=0,lte=500"`
+}
+
+type webhookDeliveriesResponse struct {
+	Success  bool                   `json:"Success"`
+	Attempts []model.WebhookAttempt `json:"Attempts"`
+}
+
+func (r *webhooksRouter) deliveries(c fiber.Ctx) error {
+	id, err := fiber.Convert(c.Params("id"), strconv.Atoi)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	var input webhookDeliveriesInput
+	if err = c.Bind().Query(&input); err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	if input.Limit == 0 {
+		input.Limit = defaultDeliveriesLimit
+	}
+
+	attempts, err := r.webhooks.Attempts(c.Context(), int64(id), input.Limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(webhookDeliveriesResponse{
+		Success:  true,
+		Attempts: attempts,
+	})
+}
@@ -0,0 +1,155 @@
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http/httptest"
+	"test_news/internal/controller/http/middleware"
+	"test_news/internal/mocks/servicemocks"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	"test_news/pkg/validator"
+	"testing"
+)
+
+func TestWebhooksRouter_create(t *testing.T) {
+	type mockBehaviour func(w *servicemocks.MockWebhooks)
+
+	testCases := []struct {
+		testName      string
+		mockBehaviour mockBehaviour
+		inputBody     string
+		expectCode    int
+		expectBody    string
+	}{
+		{
+			testName: "correct test",
+			mockBehaviour: func(w *servicemocks.MockWebhooks) {
+				w.EXPECT().Create(gomock.Any(), service.WebhookCreate{
+					URL:        "https://example.com/hook",
+					Events:     []string{service.NewsEventCreated},
+					Categories: []int64{1},
+				}).Return(model.Webhook{Id: 3, Secret: "whsec_SECRET"}, nil)
+			},
+			inputBody:  `{"URL": "https://example.com/hook", "Events": ["created"], "Categories": [1]}`,
+			expectCode: fiber.StatusOK,
+			expectBody: `{"Id":3,"Secret":"whsec_SECRET"}`,
+		},
+		{
+			testName:      "missing events",
+			mockBehaviour: func(w *servicemocks.MockWebhooks) {},
+			inputBody:     `{"URL": "https://example.com/hook"}`,
+			expectCode:    fiber.StatusBadRequest,
+			expectBody:    fiber.ErrBadRequest.Message,
+		},
+		{
+			testName: "unknown event",
+			mockBehaviour: func(w *servicemocks.MockWebhooks) {
+				w.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.Webhook{}, service.ErrUnknownWebhookEvent)
+			},
+			inputBody:  `{"URL": "https://example.com/hook", "Events": ["foobar"]}`,
+			expectCode: fiber.StatusBadRequest,
+			expectBody: service.ErrUnknownWebhookEvent.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			k := servicemocks.NewMockAPIKeys(ctrl)
+			w := servicemocks.NewMockWebhooks(ctrl)
+
+			k.EXPECT().Validate(gomock.Any(), "ADMIN KEY").Return(service.Principal{
+				Subject: "apikey:1",
+				Scopes:  []string{service.ScopeAdmin},
+			}, nil)
+			tc.mockBehaviour(w)
+
+			h := fiber.New(fiber.Config{
+				StructValidator: validator.New(),
+			})
+			NewRouter(h, &service.Services{
+				Auth:     servicemocks.NewMockAuth(ctrl),
+				APIKeys:  k,
+				Webhooks: w,
+			})
+
+			r := httptest.NewRequest(fiber.MethodPost, "/api/v1/admin/webhooks/create", bytes.NewBufferString(tc.inputBody))
+
+			r.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			r.Header.Set(middleware.HeaderAPIKey, "ADMIN KEY")
+
+			resp, err := h.Test(r)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectBody, string(body))
+		})
+	}
+}
+
+func TestWebhooksRouter_delete(t *testing.T) {
+	testCases := []struct {
+		testName   string
+		deleteErr  error
+		expectCode int
+		expectBody string
+	}{
+		{
+			testName:   "correct test",
+			deleteErr:  nil,
+			expectCode: fiber.StatusOK,
+			expectBody: "OK",
+		},
+		{
+			testName:   "not found",
+			deleteErr:  fmt.Errorf("txmanager.TxFunc exec func error: %w", service.ErrWebhookNotFound),
+			expectCode: fiber.StatusNotFound,
+			expectBody: service.ErrWebhookNotFound.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			k := servicemocks.NewMockAPIKeys(ctrl)
+			w := servicemocks.NewMockWebhooks(ctrl)
+
+			k.EXPECT().Validate(gomock.Any(), "ADMIN KEY").Return(service.Principal{
+				Subject: "apikey:1",
+				Scopes:  []string{service.ScopeAdmin},
+			}, nil)
+			w.EXPECT().Delete(gomock.Any(), int64(3)).Return(tc.deleteErr)
+
+			h := fiber.New()
+			NewRouter(h, &service.Services{
+				Auth:     servicemocks.NewMockAuth(ctrl),
+				APIKeys:  k,
+				Webhooks: w,
+			})
+
+			r := httptest.NewRequest(fiber.MethodPost, "/api/v1/admin/webhooks/delete/3", nil)
+			r.Header.Set(middleware.HeaderAPIKey, "ADMIN KEY")
+
+			resp, err := h.Test(r)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectBody, string(body))
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gofiber/fiber/v3"
+	"strconv"
+	"strings"
+	"test_news/internal/controller/http/middleware"
+	"test_news/internal/model"
+	"test_news/internal/service"
+)
+
+const mimeMergePatchJSON = "application/merge-patch+json"
+
+type newsRouter struct {
+	news service.News
+}
+
+func newNewsRouter(g fiber.Router, news service.News, cachePolicy string) {
+	r := &newsRouter{
+		news: news,
+	}
+
+	g.Post("", middleware.Scope(service.ScopeNewsWrite), r.create)
+	g.Get("/:id", middleware.Scope(service.ScopeNewsRead), middleware.CacheControl(cachePolicy), r.get)
+	g.Put("/:id", middleware.Scope(service.ScopeNewsWrite), r.replace)
+	g.Patch("/:id", middleware.Scope(service.ScopeNewsWrite), r.patch)
+	g.Delete("/:id", middleware.Scope(service.ScopeNewsWrite), r.delete)
+}
+
+type newsInput struct {
+	Title      string  `json:"Title" validate:"required"`
+	Content    string  `json:"Content" validate:"required"`
+	Categories []int64 `json:"Categories" validate:"dive,gt=0"`
+}
+
+func (r *newsRouter) create(c fiber.Ctx) error {
+	var input newsInput
+
+	if err := c.Bind().Body(&input); err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	news := model.News{
+		Title:      input.Title,
+		Content:    input.Content,
+		Categories: input.Categories,
+	}
+	id, err := r.news.Create(c.Context(), news)
+	if err != nil {
+		return err
+	}
+	news.Id = id
+
+	c.Location(strings.TrimSuffix(c.Path(), "/") + "/" + strconv.FormatInt(id, 10))
+	return c.Status(fiber.StatusCreated).JSON(news)
+}
+
+func (r *newsRouter) get(c fiber.Ctx) error {
+	id, err := parseId(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	news, err := r.news.Get(c.Context(), id)
+	if err != nil {
+		return err
+	}
+	if etag, lastModified := middleware.NewsETag(news); middleware.Conditional(c, etag, lastModified) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	return c.JSON(news)
+}
+
+// PUT заменяет новость целиком: отсутствующие категории очищаются
+func (r *newsRouter) replace(c fiber.Ctx) error {
+	id, err := parseId(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var input newsInput
+
+	if err = c.Bind().Body(&input); err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	return r.update(c, service.NewsUpdate{
+		Id:                id,
+		Title:             &input.Title,
+		Content:           &input.Content,
+		Categories:        input.Categories,
+		ReplaceCategories: true,
+	})
+}
+
+// newsPatchInput нужен только для спецификации, тело разбирается в parseNewsPatch
+type newsPatchInput struct {
+	Title      string   `json:"Title"`
+	Content    string   `json:"Content"`
+	Categories *[]int64 `json:"Categories" validate:"dive,gt=0"`
+}
+
+// PATCH по RFC 7396 (JSON Merge Patch): отсутствующее поле не меняется, null очищает
+func (r *newsRouter) patch(c fiber.Ctx) error {
+	id, err := parseId(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	mime, _, _ := strings.Cut(string(c.Request().Header.ContentType()), ";")
+	switch strings.TrimSpace(mime) {
+	case mimeMergePatchJSON, fiber.MIMEApplicationJSON:
+	default:
+		return c.SendStatus(fiber.StatusUnsupportedMediaType)
+	}
+
+	input, ok := parseNewsPatch(c.Body())
+	if !ok {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	input.Id = id
+
+	return r.update(c, input)
+}
+
+func (r *newsRouter) update(c fiber.Ctx, input service.NewsUpdate) error {
+	if err := r.news.Update(c.Context(), input); err != nil {
+		return err
+	}
+
+	news, err := r.news.Get(c.Context(), input.Id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(news)
+}
+
+func (r *newsRouter) delete(c fiber.Ctx) error {
+	id, err := parseId(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	if err = r.news.Delete(c.Context(), id); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// title и content обязательны, поэтому null для них - ошибка; null в Categories очищает список
+func parseNewsPatch(body []byte) (service.NewsUpdate, bool) {
+	var (
+		fields map[string]json.RawMessage
+		input  service.NewsUpdate
+	)
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return service.NewsUpdate{}, false
+	}
+
+	for key, value := range fields {
+		isNull := bytes.Equal(value, []byte("null"))
+
+		switch key {
+		case "Title":
+			if isNull || json.Unmarshal(value, &input.Title) != nil || *input.Title == "" {
+				return service.NewsUpdate{}, false
+			}
+		case "Content":
+			if isNull || json.Unmarshal(value, &input.Content) != nil || *input.Content == "" {
+				return service.NewsUpdate{}, false
+			}
+		case "Categories":
+			input.ReplaceCategories = true
+			if isNull {
+				continue
+			}
+			if json.Unmarshal(value, &input.Categories) != nil {
+				return service.NewsUpdate{}, false
+			}
+			for _, category := range input.Categories {
+				if category <= 0 {
+					return service.NewsUpdate{}, false
+				}
+			}
+		default:
+			return service.NewsUpdate{}, false
+		}
+	}
+	return input, true
+}
+
+func parseId(c fiber.Ctx) (int64, error) {
+	return fiber.Convert(c.Params("id"), func(s string) (int64, error) {
+		return strconv.ParseInt(s, 10, 64)
+	})
+}
@@ -0,0 +1,311 @@
+package v2
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gofiber/fiber/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http/httptest"
+	"test_news/internal/controller/http/middleware"
+	"test_news/internal/mocks/servicemocks"
+	"test_news/internal/model"
+	"test_news/internal/service"
+	"test_news/pkg/validator"
+	"testing"
+)
+
+func TestNewsRouter(t *testing.T) {
+	type mockBehaviour func(n *servicemocks.MockNews)
+
+	news := model.News{
+		Id:         1,
+		Title:      "hello world",
+		Content:    "my content",
+		Categories: []int64{1, 2},
+	}
+	newsBody := `{"Id":1,"Title":"hello world","Content":"my content","Categories":[1,2]}`
+	etag, _ := middleware.NewsETag(news)
+
+	testCases := []struct {
+		testName       string
+		mockBehaviour  mockBehaviour
+		method         string
+		path           string
+		contentType    string
+		inputBody      string
+		ifNoneMatch    string
+		expectCode     int
+		expectBody     string
+		expectLocation string
+	}{
+		{
+			testName: "create",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().Create(gomock.Any(), model.News{
+					Title:      "hello world",
+					Content:    "my content",
+					Categories: []int64{1, 2},
+				}).Return(int64(1), nil)
+			},
+			method:         fiber.MethodPost,
+			path:           "/api/v2/news",
+			inputBody:      `{"Title": "hello world", "Content": "my content", "Categories": [1, 2]}`,
+			expectCode:     fiber.StatusCreated,
+			expectBody:     newsBody,
+			expectLocation: "/api/v2/news/1",
+		},
+		{
+			testName:      "create missing title",
+			mockBehaviour: func(n *servicemocks.MockNews) {},
+			method:        fiber.MethodPost,
+			path:          "/api/v2/news",
+			inputBody:     `{"Content": "my content"}`,
+			expectCode:    fiber.StatusBadRequest,
+			expectBody:    fiber.ErrBadRequest.Message,
+		},
+		{
+			testName: "get",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().Get(gomock.Any(), int64(1)).Return(news, nil)
+			},
+			method:     fiber.MethodGet,
+			path:       "/api/v2/news/1",
+			expectCode: fiber.StatusOK,
+			expectBody: newsBody,
+		},
+		{
+			testName: "get not modified",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().Get(gomock.Any(), int64(1)).Return(news, nil)
+			},
+			method:      fiber.MethodGet,
+			path:        "/api/v2/news/1",
+			ifNoneMatch: etag,
+			expectCode:  fiber.StatusNotModified,
+			expectBody:  "",
+		},
+		{
+			testName: "get not found",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().Get(gomock.Any(), int64(2)).Return(model.News{}, service.ErrNewsNotFound)
+			},
+			method:     fiber.MethodGet,
+			path:       "/api/v2/news/2",
+			expectCode: fiber.StatusNotFound,
+			expectBody: service.ErrNewsNotFound.Error(),
+		},
+		{
+			testName:      "get incorrect id",
+			mockBehaviour: func(n *servicemocks.MockNews) {},
+			method:        fiber.MethodGet,
+			path:          "/api/v2/news/foobar",
+			expectCode:    fiber.StatusBadRequest,
+			expectBody:    fiber.ErrBadRequest.Message,
+		},
+		{
+			testName: "replace clears omitted categories",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().Update(gomock.Any(), service.NewsUpdate{
+					Id:                1,
+					Title:             ptr("hello world"),
+					Content:           ptr("my content"),
+					ReplaceCategories: true,
+				}).Return(nil)
+				n.EXPECT().Get(gomock.Any(), int64(1)).Return(model.News{
+					Id:         1,
+					Title:      "hello world",
+					Content:    "my content",
+					Categories: []int64{},
+				}, nil)
+			},
+			method:     fiber.MethodPut,
+			path:       "/api/v2/news/1",
+			inputBody:  `{"Title": "hello world", "Content": "my content"}`,
+			expectCode: fiber.StatusOK,
+			expectBody: `{"Id":1,"Title":"hello world","Content":"my content","Categories":[]}`,
+		},
+		{
+			testName: "patch title",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().Update(gomock.Any(), service.NewsUpdate{
+					Id:    1,
+					Title: ptr("hello world"),
+				}).Return(nil)
+				n.EXPECT().Get(gomock.Any(), int64(1)).Return(news, nil)
+			},
+			method:      fiber.MethodPatch,
+			path:        "/api/v2/news/1",
+			contentType: mimeMergePatchJSON,
+			inputBody:   `{"Title": "hello world"}`,
+			expectCode:  fiber.StatusOK,
+			expectBody:  newsBody,
+		},
+		{
+			testName: "patch null clears categories",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().Update(gomock.Any(), service.NewsUpdate{
+					Id:                1,
+					ReplaceCategories: true,
+				}).Return(nil)
+				n.EXPECT().Get(gomock.Any(), int64(1)).Return(news, nil)
+			},
+			method:      fiber.MethodPatch,
+			path:        "/api/v2/news/1",
+			contentType: mimeMergePatchJSON,
+			inputBody:   `{"Categories": null}`,
+			expectCode:  fiber.StatusOK,
+			expectBody:  newsBody,
+		},
+		{
+			testName: "patch replaces categories",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().Update(gomock.Any(), service.NewsUpdate{
+					Id:                1,
+					Categories:        []int64{1, 2},
+					ReplaceCategories: true,
+				}).Return(nil)
+				n.EXPECT().Get(gomock.Any(), int64(1)).Return(news, nil)
+			},
+			method:     fiber.MethodPatch,
+			path:       "/api/v2/news/1",
+			inputBody:  `{"Categories": [1, 2]}`,
+			expectCode: fiber.StatusOK,
+			expectBody: newsBody,
+		},
+		{
+			testName: "patch not found wrapped",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().Update(gomock.Any(), service.NewsUpdate{
+					Id:    2,
+					Title: ptr("hello world"),
+				}).Return(fmt.Errorf("txmanager.TxFunc exec func error: %w", service.ErrNewsNotFound))
+			},
+			method:      fiber.MethodPatch,
+			path:        "/api/v2/news/2",
+			contentType: mimeMergePatchJSON,
+			inputBody:   `{"Title": "hello world"}`,
+			expectCode:  fiber.StatusNotFound,
+			expectBody:  service.ErrNewsNotFound.Error(),
+		},
+		{
+			testName:      "patch null title",
+			mockBehaviour: func(n *servicemocks.MockNews) {},
+			method:        fiber.MethodPatch,
+			path:          "/api/v2/news/1",
+			contentType:   mimeMergePatchJSON,
+			inputBody:     `{"Title": null}`,
+			expectCode:    fiber.StatusBadRequest,
+			expectBody:    fiber.ErrBadRequest.Message,
+		},
+		{
+			testName:      "patch unknown field",
+			mockBehaviour: func(n *servicemocks.MockNews) {},
+			method:        fiber.MethodPatch,
+			path:          "/api/v2/news/1",
+			contentType:   mimeMergePatchJSON,
+			inputBody:     `{"Author": "foobar"}`,
+			expectCode:    fiber.StatusBadRequest,
+			expectBody:    fiber.ErrBadRequest.Message,
+		},
+		{
+			testName:      "patch not an object",
+			mockBehaviour: func(n *servicemocks.MockNews) {},
+			method:        fiber.MethodPatch,
+			path:          "/api/v2/news/1",
+			contentType:   mimeMergePatchJSON,
+			inputBody:     `[1, 2]`,
+			expectCode:    fiber.StatusBadRequest,
+			expectBody:    fiber.ErrBadRequest.Message,
+		},
+		{
+			testName:      "patch unsupported media type",
+			mockBehaviour: func(n *servicemocks.MockNews) {},
+			method:        fiber.MethodPatch,
+			path:          "/api/v2/news/1",
+			contentType:   fiber.MIMETextPlain,
+			inputBody:     `{"Title": "hello world"}`,
+			expectCode:    fiber.StatusUnsupportedMediaType,
+			expectBody:    fiber.ErrUnsupportedMediaType.Message,
+		},
+		{
+			testName: "delete",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().Delete(gomock.Any(), int64(1)).Return(nil)
+			},
+			method:     fiber.MethodDelete,
+			path:       "/api/v2/news/1",
+			expectCode: fiber.StatusNoContent,
+			expectBody: "",
+		},
+		{
+			testName: "delete not found",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().Delete(gomock.Any(), int64(2)).Return(service.ErrNewsNotFound)
+			},
+			method:     fiber.MethodDelete,
+			path:       "/api/v2/news/2",
+			expectCode: fiber.StatusNotFound,
+			expectBody: service.ErrNewsNotFound.Error(),
+		},
+		{
+			testName: "delete not found wrapped",
+			mockBehaviour: func(n *servicemocks.MockNews) {
+				n.EXPECT().Delete(gomock.Any(), int64(2)).Return(fmt.Errorf("newsService.Delete error: %w",
+					fmt.Errorf("txmanager.TxFunc exec func error: %w", service.ErrNewsNotFound)))
+			},
+			method:     fiber.MethodDelete,
+			path:       "/api/v2/news/2",
+			expectCode: fiber.StatusNotFound,
+			expectBody: service.ErrNewsNotFound.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			n := servicemocks.NewMockNews(ctrl)
+			a := servicemocks.NewMockAuth(ctrl)
+
+			a.EXPECT().Validate("TOKEN").Return("subject", true)
+			tc.mockBehaviour(n)
+
+			h := fiber.New(fiber.Config{
+				StructValidator: validator.New(),
+			})
+			NewRouter(h, &service.Services{
+				Auth: a,
+				News: n,
+			})
+
+			r := httptest.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.inputBody))
+
+			contentType := tc.contentType
+			if contentType == "" {
+				contentType = fiber.MIMEApplicationJSON
+			}
+			r.Header.Set(fiber.HeaderContentType, contentType)
+			r.Header.Set(fiber.HeaderAuthorization, "Bearer TOKEN")
+			if tc.ifNoneMatch != "" {
+				r.Header.Set(fiber.HeaderIfNoneMatch, tc.ifNoneMatch)
+			}
+
+			resp, err := h.Test(r)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectCode, resp.StatusCode)
+			assert.Equal(t, tc.expectLocation, resp.Header.Get(fiber.HeaderLocation))
+
+			body, err := io.ReadAll(resp.Body)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tc.expectBody, string(body))
+		})
+	}
+}
+
+func ptr[T any](t T) *T {
+	return &t
+}
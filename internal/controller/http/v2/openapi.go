@@ -0,0 +1,67 @@
+package v2
+
+import (
+	"net/http"
+	"test_news/internal/controller/http/middleware"
+	"test_news/internal/model"
+	"test_news/pkg/openapi"
+)
+
+var authErrors = []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests, http.StatusInternalServerError}
+
+// OpenAPIRoutes описывает маршруты v2, спецификацию отдает роутер v1
+func OpenAPIRoutes() []openapi.Route {
+	secured := []string{middleware.SecurityBearer, middleware.SecurityAPIKey}
+	return []openapi.Route{
+		{
+			Method:   http.MethodPost,
+			Path:     "/api/v2/news",
+			Summary:  "Create news, Location header points to the created resource (scope news:write)",
+			Tags:     []string{"news v2"},
+			Security: secured,
+			Body:     newsInput{},
+			Status:   http.StatusCreated,
+			Response: model.News{},
+			Errors:   append([]int{http.StatusBadRequest}, authErrors...),
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/api/v2/news/:id",
+			Summary:  "Get news by id (scope news:read)",
+			Tags:     []string{"news v2"},
+			Security: secured,
+			Response: model.News{},
+			Errors:   append([]int{http.StatusBadRequest, http.StatusNotFound}, authErrors...),
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/api/v2/news/:id",
+			Summary:  "Replace news, omitted categories are cleared (scope news:write)",
+			Tags:     []string{"news v2"},
+			Security: secured,
+			Body:     newsInput{},
+			Response: model.News{},
+			Errors:   append([]int{http.StatusBadRequest, http.StatusNotFound}, authErrors...),
+		},
+		{
+			Method:      http.MethodPatch,
+			Path:        "/api/v2/news/:id",
+			Summary:     "Update news with JSON Merge Patch, null Categories clears them (scope news:write)",
+			Tags:        []string{"news v2"},
+			Security:    secured,
+			Body:        newsPatchInput{},
+			ContentType: mimeMergePatchJSON,
+			Response:    model.News{},
+			Errors:      append([]int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnsupportedMediaType}, authErrors...),
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     "/api/v2/news/:id",
+			Summary:  "Delete news (scope news:write)",
+			Tags:     []string{"news v2"},
+			Security: secured,
+			Status:   http.StatusNoContent,
+			Errors:   append([]int{http.StatusBadRequest, http.StatusNotFound}, authErrors...),
+		},
+	}
+}
@@ -0,0 +1,35 @@
+package v2
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"test_news/internal/service"
+	"test_news/pkg/openapi"
+	"testing"
+)
+
+func TestOpenAPI_routesDrift(t *testing.T) {
+	h := fiber.New()
+	NewRouter(h, &service.Services{})
+
+	var registered []string
+	seen := make(map[string]bool)
+	for _, r := range h.GetRoutes(true) {
+		if r.Method == fiber.MethodHead {
+			continue
+		}
+		route := r.Method + " " + openapi.ToOpenAPIPath(r.Path)
+		if seen[route] {
+			continue
+		}
+		seen[route] = true
+		registered = append(registered, route)
+	}
+	sort.Strings(registered)
+
+	doc := openapi.NewDocument(openapi.Info{})
+	doc.Add(OpenAPIRoutes()...)
+
+	assert.Equal(t, registered, doc.Operations(), "openapi spec is out of sync with registered routes")
+}
@@ -0,0 +1,45 @@
+package v2
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"test_news/internal/controller/http/middleware"
+	"test_news/internal/service"
+	"test_news/pkg/ratelimit"
+)
+
+const defaultNewsCachePolicy = "no-cache"
+
+type options struct {
+	limiter     ratelimit.Store
+	limit       ratelimit.Limit
+	cachePolicy string
+}
+
+type Option func(o *options)
+
+// WithRateLimit ограничивает частоту запросов к /news; группа общая с v1, поэтому лимит клиента один на обе версии
+func WithRateLimit(store ratelimit.Store, limit ratelimit.Limit) Option {
+	return func(o *options) {
+		o.limiter = store
+		o.limit = limit
+	}
+}
+
+// WithCachePolicy задает Cache-Control для GET /news/:id; ответ всегда private, так как требует авторизации
+func WithCachePolicy(policy string) Option {
+	return func(o *options) {
+		o.cachePolicy = policy
+	}
+}
+
+func NewRouter(g fiber.Router, services *service.Services, opts ...Option) {
+	o := &options{
+		cachePolicy: defaultNewsCachePolicy,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	v2 := g.Group("/api/v2", middleware.Error, middleware.Auth(services.Auth, services.APIKeys))
+	newNewsRouter(v2.Group("/news", middleware.RateLimit(o.limiter, "news", o.limit)), services.News, o.cachePolicy)
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"test_news/internal/repo/txmanager"
+	"test_news/internal/service"
+	"test_news/pkg/postgres"
+)
+
+// счетчики ниже уже накапливаются в своих пакетах, collector'ы только читают их при каждом scrape
+
+var (
+	poolLabels = []string{"pool"}
+
+	poolAcquiredConns = prometheus.NewDesc("db_pool_acquired_conns", "Connections currently in use.", poolLabels, nil)
+	poolIdleConns     = prometheus.NewDesc("db_pool_idle_conns", "Idle connections.", poolLabels, nil)
+	poolTotalConns    = prometheus.NewDesc("db_pool_total_conns", "Total connections, including constructing.", poolLabels, nil)
+	poolMaxConns      = prometheus.NewDesc("db_pool_max_conns", "Maximum pool size.", poolLabels, nil)
+	poolAcquires      = prometheus.NewDesc("db_pool_acquires_total", "Successful connection acquires.", poolLabels, nil)
+	poolAcquireWait   = prometheus.NewDesc("db_pool_acquire_duration_seconds_total", "Total time spent acquiring connections.", poolLabels, nil)
+	poolEmptyAcquires = prometheus.NewDesc("db_pool_empty_acquires_total", "Acquires that had to wait for a connection.", poolLabels, nil)
+	poolHealthy       = prometheus.NewDesc("db_pool_healthy", "1 if the pool receives queries: primary always, replica while lag is under threshold.", poolLabels, nil)
+
+	queryDuration = prometheus.NewDesc("db_query_duration_seconds", "SQL query latency by operation.", []string{"operation"}, nil)
+	queryErrors   = prometheus.NewDesc("db_query_errors_total", "Failed SQL queries by operation.", []string{"operation"}, nil)
+)
+
+type postgresCollector struct {
+	pg     postgres.Postgres
+	tracer *postgres.QueryTracer
+}
+
+// NewPostgresCollector - пулы primary и реплик и гистограмма запросов tracer'а; tracer может быть nil
+func NewPostgresCollector(pg postgres.Postgres, tracer *postgres.QueryTracer) prometheus.Collector {
+	return &postgresCollector{
+		pg:     pg,
+		tracer: tracer,
+	}
+}
+
+func (c *postgresCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		poolAcquiredConns, poolIdleConns, poolTotalConns, poolMaxConns,
+		poolAcquires, poolAcquireWait, poolEmptyAcquires, poolHealthy,
+		queryDuration, queryErrors,
+	} {
+		ch <- d
+	}
+}
+
+func (c *postgresCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range c.pg.Stats() {
+		healthy := 0.
+		if s.Healthy {
+			healthy = 1
+		}
+		ch <- prometheus.MustNewConstMetric(poolAcquiredConns, prometheus.GaugeValue, float64(s.Stat.AcquiredConns()), s.Name)
+		ch <- prometheus.MustNewConstMetric(poolIdleConns, prometheus.GaugeValue, float64(s.Stat.IdleConns()), s.Name)
+		ch <- prometheus.MustNewConstMetric(poolTotalConns, prometheus.GaugeValue, float64(s.Stat.TotalConns()), s.Name)
+		ch <- prometheus.MustNewConstMetric(poolMaxConns, prometheus.GaugeValue, float64(s.Stat.MaxConns()), s.Name)
+		ch <- prometheus.MustNewConstMetric(poolAcquires, prometheus.CounterValue, float64(s.Stat.AcquireCount()), s.Name)
+		ch <- prometheus.MustNewConstMetric(poolAcquireWait, prometheus.CounterValue, s.Stat.AcquireDuration().Seconds(), s.Name)
+		ch <- prometheus.MustNewConstMetric(poolEmptyAcquires, prometheus.CounterValue, float64(s.Stat.EmptyAcquireCount()), s.Name)
+		ch <- prometheus.MustNewConstMetric(poolHealthy, prometheus.GaugeValue, healthy, s.Name)
+	}
+
+	if c.tracer == nil {
+		return
+	}
+	for op, s := range c.tracer.Snapshot() {
+		buckets := make(map[float64]uint64, len(s.Bounds))
+		for i, bound := range s.Bounds {
+			buckets[bound] = s.Buckets[i]
+		}
+		ch <- prometheus.MustNewConstHistogram(queryDuration, s.Count, s.Sum, buckets, op)
+		ch <- prometheus.MustNewConstMetric(queryErrors, prometheus.CounterValue, float64(s.Errors), op)
+	}
+}
+
+// NewTxCollector - коммиты, откаты и повторы транзакций txmanager
+func NewTxCollector(tx txmanager.Manager) prometheus.Collector {
+	return newFuncCollector(
+		counterFunc("db_tx_commits_total", "Committed transactions.", func() uint64 { return tx.Stats().Commits }),
+		counterFunc("db_tx_rollbacks_total", "Rolled back transactions.", func() uint64 { return tx.Stats().Rollbacks }),
+		counterFunc("db_tx_serialization_failures_total", "Transaction attempts failed with SQLSTATE 40001.", func() uint64 { return tx.Stats().SerializationFailures }),
+		counterFunc("db_tx_deadlocks_total", "Transaction attempts failed with SQLSTATE 40P01.", func() uint64 { return tx.Stats().Deadlocks }),
+		counterFunc("db_tx_retries_exhausted_total", "Transactions that failed after all retry attempts.", func() uint64 { return tx.Stats().Exhausted }),
+	)
+}
+
+// NewCacheCollector - счетчики кэша чтения новостей
+func NewCacheCollector(c service.Cache) prometheus.Collector {
+	return newFuncCollector(
+		counterFunc("news_cache_hits_total", "News read cache hits.", func() uint64 { return c.Stats().Hits }),
+		counterFunc("news_cache_misses_total", "News read cache misses.", func() uint64 { return c.Stats().Misses }),
+		counterFunc("news_cache_evictions_total", "News read cache evictions.", func() uint64 { return c.Stats().Evictions }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "news_cache_entries",
+			Help: "News read cache entries.",
+		}, func() float64 { return float64(c.Stats().Size) }),
+	)
+}
+
+func counterFunc(name, help string, fn func() uint64) prometheus.Collector {
+	return prometheus.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help}, func() float64 {
+		return float64(fn())
+	})
+}
+
+type funcCollector []prometheus.Collector
+
+func newFuncCollector(cs ...prometheus.Collector) prometheus.Collector {
+	return funcCollector(cs)
+}
+
+func (c funcCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, col := range c {
+		col.Describe(ch)
+	}
+}
+
+func (c funcCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, col := range c {
+		col.Collect(ch)
+	}
+}
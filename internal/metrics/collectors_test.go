@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"test_news/internal/mocks/txmocks"
+	"test_news/internal/repo/txmanager"
+	"test_news/pkg/cache"
+	"test_news/pkg/postgres"
+	"testing"
+)
+
+func TestTxCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	tx := txmocks.NewMockManager(ctrl)
+	tx.EXPECT().Stats().Return(txmanager.Stats{
+		Commits:               10,
+		Rollbacks:             2,
+		SerializationFailures: 3,
+		Deadlocks:             1,
+		Exhausted:             1,
+	}).AnyTimes()
+
+	expected := `
+# HELP db_tx_commits_total Committed transactions.
+# TYPE db_tx_commits_total counter
+db_tx_commits_total 10
+# HELP db_tx_deadlocks_total Transaction attempts failed with SQLSTATE 40P01.
+# TYPE db_tx_deadlocks_total counter
+db_tx_deadlocks_total 1
+# HELP db_tx_retries_exhausted_total Transactions that failed after all retry attempts.
+# TYPE db_tx_retries_exhausted_total counter
+db_tx_retries_exhausted_total 1
+# HELP db_tx_rollbacks_total Rolled back transactions.
+# TYPE db_tx_rollbacks_total counter
+db_tx_rollbacks_total 2
+# HELP db_tx_serialization_failures_total Transaction attempts failed with SQLSTATE 40001.
+# TYPE db_tx_serialization_failures_total counter
+db_tx_serialization_failures_total 3
+`
+	assert.NoError(t, testutil.CollectAndCompare(NewTxCollector(tx), strings.NewReader(expected)))
+}
+
+type stubCache cache.Stats
+
+func (c stubCache) Stats() cache.Stats {
+	return cache.Stats(c)
+}
+
+func TestCacheCollector(t *testing.T) {
+	c := NewCacheCollector(stubCache{Hits: 5, Misses: 2, Evictions: 1, Size: 3})
+
+	expected := `
+# HELP news_cache_entries News read cache entries.
+# TYPE news_cache_entries gauge
+news_cache_entries 3
+# HELP news_cache_hits_total News read cache hits.
+# TYPE news_cache_hits_total counter
+news_cache_hits_total 5
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "news_cache_entries", "news_cache_hits_total"))
+	assert.Equal(t, 4, testutil.CollectAndCount(c))
+}
+
+type stubPostgres struct {
+	postgres.Postgres
+}
+
+func (stubPostgres) Stats() []postgres.PoolStat {
+	return nil
+}
+
+func TestPostgresCollector_queries(t *testing.T) {
+	tracer := postgres.NewQueryTracer(zerolog.Nop(), postgres.TracerConfig{Buckets: []float64{1}})
+	ctx := postgres.WithOperation(context.Background(), "repo.newsRepo.Find")
+	for range 2 {
+		tracer.TraceQueryEnd(tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{}), nil, pgx.TraceQueryEndData{})
+	}
+
+	c := NewPostgresCollector(stubPostgres{}, tracer)
+	assert.Equal(t, 2, testutil.CollectAndCount(c, "db_query_duration_seconds", "db_query_errors_total"))
+
+	expected := `
+# HELP db_query_errors_total Failed SQL queries by operation.
+# TYPE db_query_errors_total counter
+db_query_errors_total{operation="repo.newsRepo.Find"} 0
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "db_query_errors_total"))
+}
+
+func TestNewRegistry(t *testing.T) {
+	reg := NewRegistry(NewPostgresCollector(stubPostgres{}, nil))
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(families))
+	for _, f := range families {
+		names = append(names, f.GetName())
+	}
+	assert.Contains(t, names, "go_goroutines")
+}
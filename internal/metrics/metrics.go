@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// причины отказа в авторизации для AuthFailures
+const (
+	AuthReasonMissingToken  = "missing_token"
+	AuthReasonInvalidToken  = "invalid_token"
+	AuthReasonInvalidAPIKey = "invalid_api_key"
+	AuthReasonScope         = "scope"
+)
+
+// метрики http слоя общие для всех роутеров, поэтому объявлены на уровне пакета, как принято в prometheus;
+// отдаются только после регистрации в NewRegistry
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method, route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	AuthFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_failures_total",
+		Help: "Rejected authentication and authorization attempts by reason.",
+	}, []string{"reason"})
+)
+
+// NewRegistry - отдельный registry, а не prometheus.DefaultRegisterer: в /metrics попадает только то,
+// что зарегистрировано здесь
+func NewRegistry(cs ...prometheus.Collector) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPRequestDuration,
+		AuthFailures,
+	)
+	reg.MustRegister(cs...)
+	return reg
+}
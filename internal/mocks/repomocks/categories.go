@@ -61,3 +61,18 @@ func (mr *MockCategoriesMockRecorder) Delete(exec, newsId interface{}) *gomock.C
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCategories)(nil).Delete), exec, newsId)
 }
+
+// FindByNewsIds mocks base method.
+func (m *MockCategories) FindByNewsIds(exec repo.Querier, newsIds []int64) (map[int64][]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByNewsIds", exec, newsIds)
+	ret0, _ := ret[0].(map[int64][]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByNewsIds indicates an expected call of FindByNewsIds.
+func (mr *MockCategoriesMockRecorder) FindByNewsIds(exec, newsIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByNewsIds", reflect.TypeOf((*MockCategories)(nil).FindByNewsIds), exec, newsIds)
+}
@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repo/events.go
+
+// Package repomocks is a generated GoMock package.
+package repomocks
+
+import (
+	context "context"
+	reflect "reflect"
+	repo "test_news/internal/repo"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockNewsEvents is a mock of NewsEvents interface.
+type MockNewsEvents struct {
+	ctrl     *gomock.Controller
+	recorder *MockNewsEventsMockRecorder
+}
+
+// MockNewsEventsMockRecorder is the mock recorder for MockNewsEvents.
+type MockNewsEventsMockRecorder struct {
+	mock *MockNewsEvents
+}
+
+// NewMockNewsEvents creates a new mock instance.
+func NewMockNewsEvents(ctrl *gomock.Controller) *MockNewsEvents {
+	mock := &MockNewsEvents{ctrl: ctrl}
+	mock.recorder = &MockNewsEventsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNewsEvents) EXPECT() *MockNewsEventsMockRecorder {
+	return m.recorder
+}
+
+// Listen mocks base method.
+func (m *MockNewsEvents) Listen(ctx context.Context, fn func(repo.NewsNotification)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Listen", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Listen indicates an expected call of Listen.
+func (mr *MockNewsEventsMockRecorder) Listen(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listen", reflect.TypeOf((*MockNewsEvents)(nil).Listen), ctx, fn)
+}
+
+// Notify mocks base method.
+func (m *MockNewsEvents) Notify(exec repo.Querier, eventType string, newsId int64, categories []int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notify", exec, eventType, newsId, categories)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Notify indicates an expected call of Notify.
+func (mr *MockNewsEventsMockRecorder) Notify(exec, eventType, newsId, categories interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockNewsEvents)(nil).Notify), exec, eventType, newsId, categories)
+}
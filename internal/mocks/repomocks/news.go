@@ -8,6 +8,7 @@ import (
 	reflect "reflect"
 	model "test_news/internal/model"
 	repo "test_news/internal/repo"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -50,6 +51,65 @@ func (mr *MockNewsMockRecorder) Create(exec, news interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockNews)(nil).Create), exec, news)
 }
 
+// Delete mocks base method.
+func (m *MockNews) Delete(exec repo.Querier, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", exec, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockNewsMockRecorder) Delete(exec, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockNews)(nil).Delete), exec, id)
+}
+
+// Find mocks base method.
+func (m *MockNews) Find(exec repo.Querier, limit, offset int) ([]model.News, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", exec, limit, offset)
+	ret0, _ := ret[0].([]model.News)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockNewsMockRecorder) Find(exec, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockNews)(nil).Find), exec, limit, offset)
+}
+
+// FindById mocks base method.
+func (m *MockNews) FindById(exec repo.Querier, id int64) (model.News, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindById", exec, id)
+	ret0, _ := ret[0].(model.News)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindById indicates an expected call of FindById.
+func (mr *MockNewsMockRecorder) FindById(exec, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockNews)(nil).FindById), exec, id)
+}
+
+// FindLatest mocks base method.
+func (m *MockNews) FindLatest(exec repo.Querier, categoryId int64, limit int) ([]model.News, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLatest", exec, categoryId, limit)
+	ret0, _ := ret[0].([]model.News)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLatest indicates an expected call of FindLatest.
+func (mr *MockNewsMockRecorder) FindLatest(exec, categoryId, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLatest", reflect.TypeOf((*MockNews)(nil).FindLatest), exec, categoryId, limit)
+}
+
 // FindWithCategories mocks base method.
 func (m *MockNews) FindWithCategories(exec repo.Querier, limit, offset int) ([]model.News, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +125,49 @@ func (mr *MockNewsMockRecorder) FindWithCategories(exec, limit, offset interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWithCategories", reflect.TypeOf((*MockNews)(nil).FindWithCategories), exec, limit, offset)
 }
 
+// Lock mocks base method.
+func (m *MockNews) Lock(exec repo.Querier, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", exec, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockNewsMockRecorder) Lock(exec, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockNews)(nil).Lock), exec, id)
+}
+
+// SitemapChunks mocks base method.
+func (m *MockNews) SitemapChunks(exec repo.Querier, size int) ([]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SitemapChunks", exec, size)
+	ret0, _ := ret[0].([]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SitemapChunks indicates an expected call of SitemapChunks.
+func (mr *MockNewsMockRecorder) SitemapChunks(exec, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SitemapChunks", reflect.TypeOf((*MockNews)(nil).SitemapChunks), exec, size)
+}
+
+// StreamSitemap mocks base method.
+func (m *MockNews) StreamSitemap(exec repo.Querier, limit, offset int, fn func(int64, time.Time) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamSitemap", exec, limit, offset, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamSitemap indicates an expected call of StreamSitemap.
+func (mr *MockNewsMockRecorder) StreamSitemap(exec, limit, offset, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamSitemap", reflect.TypeOf((*MockNews)(nil).StreamSitemap), exec, limit, offset, fn)
+}
+
 // Update mocks base method.
 func (m *MockNews) Update(exec repo.Querier, id int64, title, content *string) error {
 	m.ctrl.T.Helper()
@@ -243,31 +243,69 @@ func (mr *MockManagerMockRecorder) DB(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DB", reflect.TypeOf((*MockManager)(nil).DB), ctx)
 }
 
+// Primary mocks base method.
+func (m *MockManager) Primary(ctx context.Context) txmanager.Executor {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Primary", ctx)
+	ret0, _ := ret[0].(txmanager.Executor)
+	return ret0
+}
+
+// Primary indicates an expected call of Primary.
+func (mr *MockManagerMockRecorder) Primary(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Primary", reflect.TypeOf((*MockManager)(nil).Primary), ctx)
+}
+
+// Stats mocks base method.
+func (m *MockManager) Stats() txmanager.Stats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(txmanager.Stats)
+	return ret0
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockManagerMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockManager)(nil).Stats))
+}
+
 // TX mocks base method.
-func (m *MockManager) TX(ctx context.Context) (txmanager.TX, error) {
+func (m *MockManager) TX(ctx context.Context, opts ...txmanager.Option) (txmanager.TX, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TX", ctx)
+	varargs := []interface{}{ctx}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "TX", varargs...)
 	ret0, _ := ret[0].(txmanager.TX)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // TX indicates an expected call of TX.
-func (mr *MockManagerMockRecorder) TX(ctx interface{}) *gomock.Call {
+func (mr *MockManagerMockRecorder) TX(ctx interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TX", reflect.TypeOf((*MockManager)(nil).TX), ctx)
+	varargs := append([]interface{}{ctx}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TX", reflect.TypeOf((*MockManager)(nil).TX), varargs...)
 }
 
 // TxFunc mocks base method.
-func (m *MockManager) TxFunc(ctx context.Context, f func(txmanager.TX) error) error {
+func (m *MockManager) TxFunc(ctx context.Context, f func(context.Context, txmanager.TX) error, opts ...txmanager.Option) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TxFunc", ctx, f)
+	varargs := []interface{}{ctx, f}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "TxFunc", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // TxFunc indicates an expected call of TxFunc.
-func (mr *MockManagerMockRecorder) TxFunc(ctx, f interface{}) *gomock.Call {
+func (mr *MockManagerMockRecorder) TxFunc(ctx, f interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxFunc", reflect.TypeOf((*MockManager)(nil).TxFunc), ctx, f)
+	varargs := append([]interface{}{ctx, f}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxFunc", reflect.TypeOf((*MockManager)(nil).TxFunc), varargs...)
 }
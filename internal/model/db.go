@@ -1,8 +1,81 @@
 package model
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type News struct {
-	Id         int64   `json:"Id" db:"id"`
-	Title      string  `json:"Title" db:"title"`
-	Content    string  `json:"Content" db:"content"`
-	Categories []int64 `json:"Categories" db:"categories"`
+	Id         int64     `json:"Id" db:"id"`
+	Title      string    `json:"Title" db:"title"`
+	Content    string    `json:"Content" db:"content"`
+	Categories []int64   `json:"Categories" db:"categories"`
+	CreatedAt  time.Time `json:"CreatedAt,omitzero" db:"created_at"`
+	UpdatedAt  time.Time `json:"UpdatedAt,omitzero" db:"updated_at"`
+}
+
+type APIKey struct {
+	Id         int64      `json:"Id" db:"id"`
+	Name       string     `json:"Name" db:"name"`
+	Prefix     string     `json:"Prefix" db:"prefix"`
+	Hash       string     `json:"-" db:"hash"`
+	Scopes     []string   `json:"Scopes" db:"scopes"`
+	ExpiresAt  *time.Time `json:"ExpiresAt" db:"expires_at"`
+	LastUsedAt *time.Time `json:"LastUsedAt" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"RevokedAt" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"CreatedAt" db:"created_at"`
+}
+
+type AuditEntry struct {
+	Id        int64           `json:"Id" db:"id"`
+	Entity    string          `json:"Entity" db:"entity"`
+	EntityId  int64           `json:"EntityId" db:"entity_id"`
+	Action    string          `json:"Action" db:"action"`
+	Actor     string          `json:"Actor" db:"actor"`
+	IP        string          `json:"IP" db:"ip"`
+	Route     string          `json:"Route" db:"route"`
+	Before    json.RawMessage `json:"Before" db:"before"`
+	After     json.RawMessage `json:"After" db:"after"`
+	CreatedAt time.Time       `json:"CreatedAt" db:"created_at"`
+}
+
+type Webhook struct {
+	Id         int64     `json:"Id" db:"id"`
+	URL        string    `json:"URL" db:"url"`
+	Secret     string    `json:"-" db:"secret"`
+	Events     []string  `json:"Events" db:"events"`
+	Categories []int64   `json:"Categories" db:"categories"`
+	CreatedAt  time.Time `json:"CreatedAt" db:"created_at"`
+}
+
+// WebhookDelivery - доставка, взятая в работу, вместе с адресом и секретом подписки
+type WebhookDelivery struct {
+	Id        int64           `db:"id"`
+	WebhookId int64           `db:"webhook_id"`
+	Event     string          `db:"event"`
+	Payload   json.RawMessage `db:"payload"`
+	Attempts  int             `db:"attempts"`
+	URL       string          `db:"url"`
+	Secret    string          `db:"secret"`
+}
+
+type WebhookAttempt struct {
+	Id         int64     `json:"Id" db:"id"`
+	DeliveryId int64     `json:"DeliveryId" db:"delivery_id"`
+	Event      string    `json:"Event" db:"event"`
+	Status     string    `json:"Status" db:"status"` // текущее состояние доставки
+	StatusCode int       `json:"StatusCode" db:"status_code"`
+	Error      string    `json:"Error" db:"error"`
+	DurationMs int64     `json:"DurationMs" db:"duration_ms"`
+	CreatedAt  time.Time `json:"CreatedAt" db:"created_at"`
+}
+
+type OutboxEvent struct {
+	Id          int64           `db:"id"`
+	Aggregate   string          `db:"aggregate"`
+	AggregateId int64           `db:"aggregate_id"`
+	Type        string          `db:"event_type"`
+	Payload     json.RawMessage `db:"payload"`
+	Attempts    int             `db:"attempts"`
+	CreatedAt   time.Time       `db:"created_at"`
 }
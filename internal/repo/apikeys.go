@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"test_news/internal/model"
+)
+
+type APIKeys interface {
+	Create(exec Querier, key model.APIKey) (int64, error)
+	FindByPrefix(exec Querier, prefix string) (model.APIKey, error)
+	List(exec Querier) ([]model.APIKey, error)
+	Revoke(exec Querier, id int64) error
+	Touch(exec Querier, id int64) error
+}
+
+type apiKeysRepo struct{}
+
+func NewAPIKeysRepo() APIKeys {
+	return &apiKeysRepo{}
+}
+
+const apiKeysColumns = "id, name, prefix, hash, scopes, expires_at, last_used_at, revoked_at, created_at"
+
+func (r *apiKeysRepo) Create(exec Querier, key model.APIKey) (int64, error) {
+	sql := "INSERT INTO api_keys (name, prefix, hash, scopes, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+
+	var id int64
+	if err := exec.QueryRow(sql, key.Name, key.Prefix, key.Hash, key.Scopes, key.ExpiresAt).Scan(&id); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == codeErrUniqueViolation {
+			return 0, ErrAlreadyExists
+		}
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *apiKeysRepo) FindByPrefix(exec Querier, prefix string) (model.APIKey, error) {
+	sql := "SELECT " + apiKeysColumns + " FROM api_keys WHERE prefix = $1"
+
+	rows, err := exec.Query(sql, prefix)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+	key, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.APIKey])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.APIKey{}, ErrNotFound
+		}
+		return model.APIKey{}, err
+	}
+	return key, nil
+}
+
+func (r *apiKeysRepo) List(exec Querier) ([]model.APIKey, error) {
+	sql := "SELECT " + apiKeysColumns + " FROM api_keys ORDER BY id"
+
+	rows, err := exec.Query(sql)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[model.APIKey])
+}
+
+func (r *apiKeysRepo) Revoke(exec Querier, id int64) error {
+	sql := "UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL"
+
+	result, err := exec.Exec(sql, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Touch не пишет в таблицу чаще раза в минуту, чтобы не делать update на каждый запрос
+func (r *apiKeysRepo) Touch(exec Querier, id int64) error {
+	sql := `
+		UPDATE api_keys SET last_used_at = now()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+	`
+	if _, err := exec.Exec(sql, id); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package repo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"test_news/internal/model"
+	"testing"
+)
+
+func (s *pgdbTestSuite) TestAPIKeysRepo_Create() {
+	testCases := []struct {
+		testName  string
+		key       model.APIKey
+		expectErr error
+	}{
+		{
+			testName: "correct test",
+			key: model.APIKey{
+				Name:   "bot",
+				Prefix: "aaaaaaaaaaaa",
+				Hash:   "hash",
+				Scopes: []string{"news:read"},
+			},
+			expectErr: nil,
+		},
+		{
+			testName: "prefix already exists",
+			key: model.APIKey{
+				Name:   "bot 2",
+				Prefix: "aaaaaaaaaaaa",
+				Hash:   "hash 2",
+				Scopes: []string{"news:read"},
+			},
+			expectErr: ErrAlreadyExists,
+		},
+	}
+
+	for _, tc := range testCases {
+		s.T().Run(tc.testName, func(t *testing.T) {
+			id, err := s.apiKeys.Create(s.tx.DB(s.ctx), tc.key)
+
+			assert.Equal(t, tc.expectErr, err)
+
+			if tc.expectErr == nil {
+				actual, err := s.apiKeys.FindByPrefix(s.tx.DB(s.ctx), tc.key.Prefix)
+				assert.NoError(t, err)
+
+				assert.Equal(t, id, actual.Id)
+				assert.Equal(t, tc.key.Name, actual.Name)
+				assert.Equal(t, tc.key.Hash, actual.Hash)
+				assert.Equal(t, tc.key.Scopes, actual.Scopes)
+				assert.Nil(t, actual.RevokedAt)
+			}
+		})
+	}
+}
+
+func (s *pgdbTestSuite) TestAPIKeysRepo_FindByPrefix() {
+	_, err := s.apiKeys.FindByPrefix(s.tx.DB(s.ctx), "not exists")
+	assert.Equal(s.T(), ErrNotFound, err)
+}
+
+func (s *pgdbTestSuite) TestAPIKeysRepo_Revoke() {
+	id, err := s.apiKeys.Create(s.tx.DB(s.ctx), model.APIKey{
+		Name:   "bot",
+		Prefix: "bbbbbbbbbbbb",
+		Hash:   "hash",
+		Scopes: []string{"news:write"},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	testCases := []struct {
+		testName  string
+		id        int64
+		expectErr error
+	}{
+		{
+			testName:  "correct test",
+			id:        id,
+			expectErr: nil,
+		},
+		{
+			testName:  "already revoked",
+			id:        id,
+			expectErr: ErrNotFound,
+		},
+		{
+			testName:  "not found",
+			id:        -1,
+			expectErr: ErrNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		s.T().Run(tc.testName, func(t *testing.T) {
+			err := s.apiKeys.Revoke(s.tx.DB(s.ctx), tc.id)
+
+			assert.Equal(t, tc.expectErr, err)
+		})
+	}
+
+	keys, err := s.apiKeys.List(s.tx.DB(s.ctx))
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), keys, 1)
+	assert.NotNil(s.T(), keys[0].RevokedAt)
+}
+
+func (s *pgdbTestSuite) TestAPIKeysRepo_Touch() {
+	id, err := s.apiKeys.Create(s.tx.DB(s.ctx), model.APIKey{
+		Name:   "bot",
+		Prefix: "cccccccccccc",
+		Hash:   "hash",
+		Scopes: []string{"news:read"},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	err = s.apiKeys.Touch(s.tx.DB(s.ctx), id)
+	assert.NoError(s.T(), err)
+
+	actual, err := s.apiKeys.FindByPrefix(s.tx.DB(s.ctx), "cccccccccccc")
+	assert.NoError(s.T(), err)
+	assert.NotNil(s.T(), actual.LastUsedAt)
+}
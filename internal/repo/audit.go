@@ -0,0 +1,82 @@
+package repo
+
+import (
+	"github.com/jackc/pgx/v5"
+	"strconv"
+	"strings"
+	"test_news/internal/model"
+	"time"
+)
+
+type Audit interface {
+	Create(exec Querier, entry model.AuditEntry) error
+	Find(exec Querier, filter AuditFilter) ([]model.AuditEntry, error)
+}
+
+type AuditFilter struct {
+	Entity   string
+	EntityId int64
+	Actor    string
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+	Offset   int
+}
+
+type auditRepo struct{}
+
+func NewAuditRepo() Audit {
+	return &auditRepo{}
+}
+
+func (r *auditRepo) Create(exec Querier, entry model.AuditEntry) error {
+	sql := `
+		INSERT INTO audit_log (entity, entity_id, action, actor, ip, route, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := exec.Exec(sql, entry.Entity, entry.EntityId, entry.Action, entry.Actor, entry.IP, entry.Route, entry.Before, entry.After)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *auditRepo) Find(exec Querier, filter AuditFilter) ([]model.AuditEntry, error) {
+	var (
+		parts []string
+		args  []any
+	)
+	if filter.Entity != "" {
+		args = append(args, filter.Entity)
+		parts = append(parts, "entity = $"+strconv.Itoa(len(args)))
+	}
+	if filter.EntityId != 0 {
+		args = append(args, filter.EntityId)
+		parts = append(parts, "entity_id = $"+strconv.Itoa(len(args)))
+	}
+	if filter.Actor != "" {
+		args = append(args, filter.Actor)
+		parts = append(parts, "actor = $"+strconv.Itoa(len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		parts = append(parts, "created_at >= $"+strconv.Itoa(len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		parts = append(parts, "created_at < $"+strconv.Itoa(len(args)))
+	}
+
+	sql := "SELECT id, entity, entity_id, action, actor, ip, route, before, after, created_at FROM audit_log"
+	if len(parts) != 0 {
+		sql += " WHERE " + strings.Join(parts, " AND ")
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	sql += " ORDER BY id DESC LIMIT $" + strconv.Itoa(len(args)-1) + " OFFSET $" + strconv.Itoa(len(args))
+
+	rows, err := exec.Query(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[model.AuditEntry])
+}
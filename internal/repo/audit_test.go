@@ -0,0 +1,104 @@
+package repo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"test_news/internal/model"
+	"testing"
+	"time"
+)
+
+func (s *pgdbTestSuite) TestAuditRepo_Find() {
+	entries := []model.AuditEntry{
+		{
+			Entity:   "news",
+			EntityId: 1,
+			Action:   "create",
+			Actor:    "jwt:1",
+			IP:       "127.0.0.1",
+			Route:    "POST /api/v1/news/create",
+			After:    []byte(`{"Id": 1}`),
+		},
+		{
+			Entity:   "news",
+			EntityId: 1,
+			Action:   "update",
+			Actor:    "apikey:2",
+			IP:       "127.0.0.1",
+			Route:    "POST /api/v1/news/edit/1",
+			Before:   []byte(`{"Id": 1}`),
+			After:    []byte(`{"Id": 1}`),
+		},
+		{
+			Entity:   "news",
+			EntityId: 2,
+			Action:   "create",
+			Actor:    "jwt:1",
+			IP:       "127.0.0.1",
+			Route:    "POST /api/v1/news/create",
+			After:    []byte(`{"Id": 2}`),
+		},
+	}
+	for _, e := range entries {
+		if err := s.audit.Create(s.tx.DB(s.ctx), e); err != nil {
+			panic(err)
+		}
+	}
+	future := time.Now().Add(time.Hour)
+
+	testCases := []struct {
+		testName      string
+		filter        AuditFilter
+		expectActions []string
+	}{
+		{
+			testName:      "by entity",
+			filter:        AuditFilter{Entity: "news", EntityId: 1, Limit: 10},
+			expectActions: []string{"update", "create"},
+		},
+		{
+			testName:      "by actor",
+			filter:        AuditFilter{Actor: "jwt:1", Limit: 10},
+			expectActions: []string{"create", "create"},
+		},
+		{
+			testName:      "by time range",
+			filter:        AuditFilter{From: &future, Limit: 10},
+			expectActions: []string{},
+		},
+		{
+			testName:      "limit",
+			filter:        AuditFilter{Limit: 1},
+			expectActions: []string{"create"},
+		},
+	}
+
+	for _, tc := range testCases {
+		s.T().Run(tc.testName, func(t *testing.T) {
+			actual, err := s.audit.Find(s.tx.DB(s.ctx), tc.filter)
+
+			assert.NoError(t, err)
+
+			actions := make([]string, 0, len(actual))
+			for _, e := range actual {
+				actions = append(actions, e.Action)
+			}
+			assert.Equal(t, tc.expectActions, actions)
+		})
+	}
+}
+
+func (s *pgdbTestSuite) TestAuditRepo_AppendOnly() {
+	err := s.audit.Create(s.tx.DB(s.ctx), model.AuditEntry{
+		Entity:   "news",
+		EntityId: 1,
+		Action:   "create",
+		Actor:    "jwt:1",
+	})
+	assert.NoError(s.T(), err)
+
+	_, err = s.pg.Exec(s.ctx, "UPDATE audit_log SET actor = 'someone else'")
+	assert.Error(s.T(), err)
+
+	_, err = s.pg.Exec(s.ctx, "DELETE FROM audit_log")
+	assert.Error(s.T(), err)
+}
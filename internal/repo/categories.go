@@ -8,6 +8,7 @@ import (
 type Categories interface {
 	Create(exec Querier, newsId int64, categories []int64) error
 	Delete(exec Querier, newsId int64) error
+	FindByNewsIds(exec Querier, newsIds []int64) (map[int64][]int64, error)
 }
 
 type categoriesRepo struct{}
@@ -40,3 +41,23 @@ func (r *categoriesRepo) Delete(exec Querier, newsId int64) error {
 	}
 	return nil
 }
+
+func (r *categoriesRepo) FindByNewsIds(exec Querier, newsIds []int64) (map[int64][]int64, error) {
+	sql := "SELECT news_id, category_id FROM news_categories WHERE news_id = ANY($1) ORDER BY news_id, category_id"
+
+	rows, err := exec.Query(sql, newsIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64][]int64, len(newsIds))
+	for rows.Next() {
+		var newsId, categoryId int64
+		if err = rows.Scan(&newsId, &categoryId); err != nil {
+			return nil, err
+		}
+		result[newsId] = append(result[newsId], categoryId)
+	}
+	return result, rows.Err()
+}
@@ -99,3 +99,19 @@ func (s *pgdbTestSuite) TestCategoriesRepo_Delete() {
 		})
 	}
 }
+
+func (s *pgdbTestSuite) TestCategoriesRepo_FindByNewsIds() {
+	first := s.createNews()
+	second := s.createNews()
+	empty := s.createNews()
+	s.createCategories(first.Id, 1, 2)
+	s.createCategories(second.Id, 3)
+
+	actual, err := s.categories.FindByNewsIds(s.tx.DB(s.ctx), []int64{first.Id, second.Id, empty.Id})
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), map[int64][]int64{
+		first.Id:  {1, 2},
+		second.Id: {3},
+	}, actual)
+}
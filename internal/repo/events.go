@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"test_news/pkg/postgres"
+)
+
+const newsEventsChannel = "news_events"
+
+type NewsEvents interface {
+	Notify(exec Querier, eventType string, newsId int64, categories []int64) error
+	// Listen держит отдельное соединение и передает в fn уведомления всех реплик до ошибки или отмены ctx
+	Listen(ctx context.Context, fn func(n NewsNotification)) error
+}
+
+// NewsNotification - payload NOTIFY; новость не передается целиком из-за ограничения в 8000 байт
+type NewsNotification struct {
+	Id         int64   `json:"id"`
+	Type       string  `json:"type"`
+	NewsId     int64   `json:"news_id"`
+	Categories []int64 `json:"categories"`
+}
+
+type newsEventsRepo struct {
+	pg postgres.Postgres
+}
+
+func NewNewsEventsRepo(pg postgres.Postgres) NewsEvents {
+	return &newsEventsRepo{
+		pg: pg,
+	}
+}
+
+func (r *newsEventsRepo) Notify(exec Querier, eventType string, newsId int64, categories []int64) error {
+	sql := `
+		SELECT pg_notify($1, json_build_object(
+		    'id', nextval('news_events_id_seq'),
+		    'type', $2::text,
+		    'news_id', $3::bigint,
+		    'categories', $4::bigint[]
+		)::text)
+	`
+
+	_, err := exec.Exec(sql, newsEventsChannel, eventType, newsId, categories)
+	return err
+}
+
+func (r *newsEventsRepo) Listen(ctx context.Context, fn func(n NewsNotification)) error {
+	pooled, err := r.pg.GetPool().Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// соединение с LISTEN не должно вернуться в пул
+	conn := pooled.Hijack()
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	if _, err = conn.Exec(ctx, "LISTEN "+newsEventsChannel); err != nil {
+		return err
+	}
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var n NewsNotification
+		if err = json.Unmarshal([]byte(notification.Payload), &n); err != nil {
+			return fmt.Errorf("unmarshal notification %q: %w", notification.Payload, err)
+		}
+		fn(n)
+	}
+}
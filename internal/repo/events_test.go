@@ -0,0 +1,47 @@
+package repo
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"time"
+)
+
+func (s *pgdbTestSuite) TestNewsEventsRepo_Listen() {
+	ctx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+
+	received := make(chan NewsNotification, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- s.events.Listen(ctx, func(n NewsNotification) {
+			select {
+			case received <- n:
+			default:
+			}
+		})
+	}()
+
+	// LISTEN может выполниться позже первого NOTIFY, поэтому уведомляем до получения
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(5 * time.Second)
+
+	for {
+		assert.NoError(s.T(), s.events.Notify(s.tx.DB(s.ctx), "created", 42, []int64{1, 2}))
+
+		select {
+		case n := <-received:
+			assert.Equal(s.T(), "created", n.Type)
+			assert.Equal(s.T(), int64(42), n.NewsId)
+			assert.Equal(s.T(), []int64{1, 2}, n.Categories)
+			assert.Greater(s.T(), n.Id, int64(0))
+
+			cancel()
+			assert.Error(s.T(), <-done)
+			return
+		case <-ticker.C:
+		case <-timeout:
+			s.T().Fatal("notification not received")
+		}
+	}
+}
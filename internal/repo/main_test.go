@@ -21,6 +21,11 @@ type pgdbTestSuite struct {
 	tx         txmanager.Manager
 	news       *newsRepo
 	categories *categoriesRepo
+	apiKeys    *apiKeysRepo
+	audit      *auditRepo
+	events     *newsEventsRepo
+	webhooks   *webhooksRepo
+	outbox     *outboxRepo
 }
 
 func (s *pgdbTestSuite) SetupTest() {
@@ -44,6 +49,11 @@ func (s *pgdbTestSuite) SetupTest() {
 	s.tx = txmanager.NewManager(pg)
 	s.news = &newsRepo{}
 	s.categories = &categoriesRepo{}
+	s.apiKeys = &apiKeysRepo{}
+	s.audit = &auditRepo{}
+	s.events = &newsEventsRepo{pg: pg}
+	s.webhooks = &webhooksRepo{}
+	s.outbox = &outboxRepo{}
 }
 
 func (s *pgdbTestSuite) TearDownTest() {
@@ -64,12 +74,10 @@ func (s *pgdbTestSuite) createNews() model.News {
 		Title:   "My Title",
 		Content: "Content",
 	}
-	sql := "INSERT INTO news (title, content) VALUES ($1, $2) RETURNING id"
-	var id int64
-	if err := s.pg.QueryRow(s.ctx, sql, n.Title, n.Content).Scan(&id); err != nil {
+	sql := "INSERT INTO news (title, content) VALUES ($1, $2) RETURNING id, created_at, updated_at"
+	if err := s.pg.QueryRow(s.ctx, sql, n.Title, n.Content).Scan(&n.Id, &n.CreatedAt, &n.UpdatedAt); err != nil {
 		panic(err)
 	}
-	n.Id = id
 	return n
 }
 
@@ -1,17 +1,26 @@
 package repo
 
 import (
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
 	"strconv"
 	"strings"
 	"test_news/internal/model"
+	"time"
 )
 
 type News interface {
 	Create(exec Querier, news model.News) (int64, error)
 	Update(exec Querier, id int64, title, content *string) error
+	Delete(exec Querier, id int64) error
+	Lock(exec Querier, id int64) error
+	FindById(exec Querier, id int64) (model.News, error)
+	Find(exec Querier, limit, offset int) ([]model.News, error)
 	FindWithCategories(exec Querier, limit, offset int) ([]model.News, error)
+	FindLatest(exec Querier, categoryId int64, limit int) ([]model.News, error)
+	SitemapChunks(exec Querier, size int) ([]time.Time, error)
+	StreamSitemap(exec Querier, limit, offset int, fn func(id int64, updatedAt time.Time) error) error
 }
 
 type newsRepo struct{}
@@ -46,9 +55,8 @@ func (r *newsRepo) Update(exec Querier, id int64, title, content *string) error
 		args = append(args, *content)
 		pos++
 	}
-	if len(parts) == 0 {
-		return nil
-	}
+	// updated_at обновляется и без изменения полей: замена категорий тоже изменение новости
+	parts = append(parts, "updated_at = now()")
 	sql := fmt.Sprintf("UPDATE news SET %s WHERE id = $%d", strings.Join(parts, ", "), pos)
 
 	args = append(args, id)
@@ -63,13 +71,73 @@ func (r *newsRepo) Update(exec Querier, id int64, title, content *string) error
 	return nil
 }
 
+func (r *newsRepo) Delete(exec Querier, id int64) error {
+	sql := "DELETE FROM news WHERE id = $1"
+
+	result, err := exec.Exec(sql, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *newsRepo) Lock(exec Querier, id int64) error {
+	sql := "SELECT id FROM news WHERE id = $1 FOR UPDATE"
+
+	if err := exec.QueryRow(sql, id).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *newsRepo) FindById(exec Querier, id int64) (model.News, error) {
+	sql := `
+		SELECT n.id, n.title, n.content, n.created_at, n.updated_at,
+		       COALESCE(array_agg(nc.category_id) FILTER (WHERE nc.category_id IS NOT NULL), '{}') AS categories
+		FROM news n
+		LEFT JOIN news_categories nc ON n.id = nc.news_id
+		WHERE n.id = $1
+		GROUP BY n.id
+	`
+
+	rows, err := exec.Query(sql, id)
+	if err != nil {
+		return model.News{}, err
+	}
+	news, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.News])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.News{}, ErrNotFound
+		}
+		return model.News{}, err
+	}
+	return news, nil
+}
+
+// Find возвращает новости без категорий, для пакетной загрузки через Categories.FindByNewsIds
+func (r *newsRepo) Find(exec Querier, limit, offset int) ([]model.News, error) {
+	sql := "SELECT id, title, content, created_at, updated_at FROM news ORDER BY id LIMIT $1 OFFSET $2"
+
+	rows, err := exec.Query(sql, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByNameLax[model.News])
+}
+
 func (r *newsRepo) FindWithCategories(exec Querier, limit, offset int) ([]model.News, error) {
 	sql := `
-		SELECT n.id, n.title, n.content, 
+		SELECT n.id, n.title, n.content, n.created_at, n.updated_at,
 		       COALESCE(array_agg(nc.category_id) FILTER (WHERE nc.category_id IS NOT NULL), '{}') AS categories
 		FROM news n
 		LEFT JOIN news_categories nc ON n.id = nc.news_id
-		GROUP BY n.id, n.title, n.content
+		GROUP BY n.id
 		ORDER BY n.id
 		LIMIT $1
 		OFFSET $2
@@ -81,3 +149,64 @@ func (r *newsRepo) FindWithCategories(exec Querier, limit, offset int) ([]model.
 	}
 	return pgx.CollectRows(rows, pgx.RowToStructByName[model.News])
 }
+
+// FindLatest возвращает последние новости, categoryId == 0 - без фильтра по категории
+func (r *newsRepo) FindLatest(exec Querier, categoryId int64, limit int) ([]model.News, error) {
+	sql := `
+		SELECT n.id, n.title, n.content, n.created_at, n.updated_at,
+		       COALESCE(array_agg(nc.category_id) FILTER (WHERE nc.category_id IS NOT NULL), '{}') AS categories
+		FROM news n
+		LEFT JOIN news_categories nc ON n.id = nc.news_id
+		WHERE $1::bigint = 0 OR n.id IN (SELECT news_id FROM news_categories WHERE category_id = $1)
+		GROUP BY n.id
+		ORDER BY n.id DESC
+		LIMIT $2
+	`
+
+	rows, err := exec.Query(sql, categoryId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[model.News])
+}
+
+// SitemapChunks делит новости по id на части по size штук и возвращает время последнего изменения каждой части
+func (r *newsRepo) SitemapChunks(exec Querier, size int) ([]time.Time, error) {
+	sql := `
+		SELECT max(updated_at)
+		FROM (SELECT updated_at, (row_number() OVER (ORDER BY id) - 1) / $1 AS chunk FROM news) t
+		GROUP BY chunk
+		ORDER BY chunk
+	`
+
+	rows, err := exec.Query(sql, size)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[time.Time])
+}
+
+// StreamSitemap читает id и время изменения новостей построчно и передает в fn, не собирая их в память
+func (r *newsRepo) StreamSitemap(exec Querier, limit, offset int, fn func(id int64, updatedAt time.Time) error) error {
+	sql := "SELECT id, updated_at FROM news ORDER BY id LIMIT $1 OFFSET $2"
+
+	rows, err := exec.Query(sql, limit, offset)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var (
+		id        int64
+		updatedAt time.Time
+	)
+	for rows.Next() {
+		if err = rows.Scan(&id, &updatedAt); err != nil {
+			return err
+		}
+		if err = fn(id, updatedAt); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -1,9 +1,13 @@
 package repo
 
 import (
+	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"test_news/internal/model"
+	"test_news/internal/repo/txmanager"
 	"testing"
+	"time"
 )
 
 func (s *pgdbTestSuite) TestNewsRepo_Create() {
@@ -138,12 +142,16 @@ func (s *pgdbTestSuite) TestNewsRepo_FindWithCategories() {
 					Id:         news1.Id,
 					Title:      news1.Title,
 					Content:    news1.Content,
+					CreatedAt:  news1.CreatedAt,
+					UpdatedAt:  news1.UpdatedAt,
 					Categories: []int64{1, 2, 3, 4},
 				},
 				{
 					Id:         news2.Id,
 					Title:      news2.Title,
 					Content:    news2.Content,
+					CreatedAt:  news2.CreatedAt,
+					UpdatedAt:  news2.UpdatedAt,
 					Categories: []int64{2, 5},
 				},
 			},
@@ -161,3 +169,121 @@ func (s *pgdbTestSuite) TestNewsRepo_FindWithCategories() {
 	}
 
 }
+
+func (s *pgdbTestSuite) TestNewsRepo_FindById() {
+	news := s.createNews()
+	s.createCategories(news.Id, 1, 2)
+
+	testCases := []struct {
+		testName     string
+		id           int64
+		expectOutput model.News
+		expectErr    error
+	}{
+		{
+			testName: "correct test",
+			id:       news.Id,
+			expectOutput: model.News{
+				Id:         news.Id,
+				Title:      news.Title,
+				Content:    news.Content,
+				Categories: []int64{1, 2},
+				CreatedAt:  news.CreatedAt,
+				UpdatedAt:  news.UpdatedAt,
+			},
+			expectErr: nil,
+		},
+		{
+			testName:     "not found",
+			id:           -1,
+			expectOutput: model.News{},
+			expectErr:    ErrNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		s.T().Run(tc.testName, func(t *testing.T) {
+			actual, err := s.news.FindById(s.tx.DB(s.ctx), tc.id)
+
+			assert.Equal(t, tc.expectErr, err)
+			assert.Equal(t, tc.expectOutput, actual)
+		})
+	}
+}
+
+func (s *pgdbTestSuite) TestNewsRepo_Lock() {
+	news := s.createNews()
+
+	err := s.tx.TxFunc(s.ctx, func(ctx context.Context, tx txmanager.TX) error {
+		return s.news.Lock(tx, news.Id)
+	})
+	assert.NoError(s.T(), err)
+
+	err = s.news.Lock(s.tx.DB(s.ctx), -1)
+	assert.Equal(s.T(), ErrNotFound, err)
+}
+
+func (s *pgdbTestSuite) TestNewsRepo_Delete() {
+	news := s.createNews()
+
+	err := s.news.Delete(s.tx.DB(s.ctx), news.Id)
+	assert.NoError(s.T(), err)
+
+	_, err = s.news.FindById(s.tx.DB(s.ctx), news.Id)
+	assert.Equal(s.T(), ErrNotFound, err)
+
+	err = s.news.Delete(s.tx.DB(s.ctx), news.Id)
+	assert.Equal(s.T(), ErrNotFound, err)
+}
+
+func (s *pgdbTestSuite) TestNewsRepo_Find() {
+	news := s.createNews()
+	s.createCategories(news.Id, 1)
+
+	actual, err := s.news.Find(s.tx.DB(s.ctx), 20, 0)
+
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []model.News{news}, actual) // категории не загружаются
+}
+
+func (s *pgdbTestSuite) TestNewsRepo_FindLatest() {
+	news1 := s.createNews()
+	news2 := s.createNews()
+	s.createCategories(news1.Id, 1)
+	s.createCategories(news2.Id, 2)
+
+	actual, err := s.news.FindLatest(s.tx.DB(s.ctx), 0, 20)
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), actual, 2)
+	assert.Equal(s.T(), news2.Id, actual[0].Id) // новые первыми
+
+	actual, err = s.news.FindLatest(s.tx.DB(s.ctx), 1, 20)
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), actual, 1)
+	assert.Equal(s.T(), news1.Id, actual[0].Id)
+	assert.Equal(s.T(), []int64{1}, actual[0].Categories)
+}
+
+func (s *pgdbTestSuite) TestNewsRepo_Sitemap() {
+	news1 := s.createNews()
+	news2 := s.createNews()
+	news3 := s.createNews()
+
+	chunks, err := s.news.SitemapChunks(s.tx.DB(s.ctx), 2)
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), chunks, 2)
+	assert.True(s.T(), news3.UpdatedAt.Equal(chunks[1]))
+
+	var ids []int64
+	err = s.news.StreamSitemap(s.tx.DB(s.ctx), 2, 0, func(id int64, updatedAt time.Time) error {
+		ids = append(ids, id)
+		return nil
+	})
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), []int64{news1.Id, news2.Id}, ids)
+
+	err = s.news.StreamSitemap(s.tx.DB(s.ctx), 2, 2, func(id int64, updatedAt time.Time) error {
+		return errors.New("stop")
+	})
+	assert.EqualError(s.T(), err, "stop")
+}
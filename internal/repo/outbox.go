@@ -0,0 +1,142 @@
+package repo
+
+import (
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"test_news/internal/model"
+	"time"
+)
+
+type Outbox interface {
+	Add(exec Querier, event model.OutboxEvent) error
+	// Claim берет до limit готовых событий и откладывает их на lease, чтобы другие relay их не взяли; строки
+	// не остаются заблокированными на время публикации. Берется только самое раннее событие каждого агрегата,
+	// поэтому события одного агрегата публикуются строго по порядку
+	Claim(exec Querier, limit int, lease time.Duration) ([]model.OutboxEvent, error)
+	MarkPublished(exec Querier, ids []int64) error
+	// MarkFailed откладывает следующую попытку до nextAttemptAt
+	MarkFailed(exec Querier, id int64, reason string, nextAttemptAt time.Time) error
+	// MarkDead переводит событие в dead letter: оно больше не публикуется и не задерживает следующие события агрегата
+	MarkDead(exec Querier, id int64, reason string) error
+	DeletePublished(exec Querier, before time.Time) (int64, error)
+	// FindLast - последнее событие eventType агрегата, опубликованное или нет
+	FindLast(exec Querier, aggregate string, aggregateId int64, eventType string) (model.OutboxEvent, error)
+}
+
+type outboxRepo struct{}
+
+func NewOutboxRepo() Outbox {
+	return &outboxRepo{}
+}
+
+func (r *outboxRepo) Add(exec Querier, event model.OutboxEvent) error {
+	sql := "INSERT INTO outbox (aggregate, aggregate_id, event_type, payload) VALUES ($1, $2, $3, $4)"
+
+	_, err := exec.Exec(sql, event.Aggregate, event.AggregateId, event.Type, event.Payload)
+	return err
+}
+
+func (r *outboxRepo) Claim(exec Querier, limit int, lease time.Duration) ([]model.OutboxEvent, error) {
+	// событие, перед которым есть неопубликованное событие того же агрегата (в том числе взятое другим relay), пропускается
+	sql := `
+		WITH claimed AS (
+		    UPDATE outbox
+		    SET next_attempt_at = now() + $2 * interval '1 millisecond'
+		    WHERE id IN (
+		        SELECT id
+		        FROM outbox o
+		        WHERE published_at IS NULL
+		          AND dead_at IS NULL
+		          AND next_attempt_at <= now()
+		          AND NOT EXISTS (
+		              SELECT 1
+		              FROM outbox p
+		              WHERE p.aggregate = o.aggregate
+		                AND p.aggregate_id = o.aggregate_id
+		                AND p.published_at IS NULL
+		                AND p.dead_at IS NULL
+		                AND p.id < o.id
+		          )
+		        ORDER BY id
+		        LIMIT $1
+		        FOR UPDATE SKIP LOCKED
+		    )
+		    RETURNING id, aggregate, aggregate_id, event_type, payload, attempts, created_at
+		)
+		SELECT id, aggregate, aggregate_id, event_type, payload, attempts, created_at
+		FROM claimed
+		ORDER BY id
+	`
+
+	rows, err := exec.Query(sql, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[model.OutboxEvent])
+}
+
+func (r *outboxRepo) MarkPublished(exec Querier, ids []int64) error {
+	sql := "UPDATE outbox SET published_at = now(), attempts = attempts + 1 WHERE id = ANY ($1)"
+
+	_, err := exec.Exec(sql, ids)
+	return err
+}
+
+func (r *outboxRepo) MarkFailed(exec Querier, id int64, reason string, nextAttemptAt time.Time) error {
+	sql := "UPDATE outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE id = $1"
+
+	result, err := exec.Exec(sql, id, reason, nextAttemptAt)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *outboxRepo) MarkDead(exec Querier, id int64, reason string) error {
+	sql := "UPDATE outbox SET attempts = attempts + 1, last_error = $2, dead_at = now() WHERE id = $1"
+
+	result, err := exec.Exec(sql, id, reason)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *outboxRepo) DeletePublished(exec Querier, before time.Time) (int64, error) {
+	sql := "DELETE FROM outbox WHERE published_at < $1"
+
+	result, err := exec.Exec(sql, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+func (r *outboxRepo) FindLast(exec Querier, aggregate string, aggregateId int64, eventType string) (model.OutboxEvent, error) {
+	sql := `
+		SELECT id, aggregate, aggregate_id, event_type, payload, attempts, created_at
+		FROM outbox
+		WHERE aggregate = $1 AND aggregate_id = $2 AND event_type = $3
+		ORDER BY id DESC
+		LIMIT 1
+	`
+
+	rows, err := exec.Query(sql, aggregate, aggregateId, eventType)
+	if err != nil {
+		return model.OutboxEvent{}, err
+	}
+	event, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[model.OutboxEvent])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.OutboxEvent{}, ErrNotFound
+		}
+		return model.OutboxEvent{}, err
+	}
+	return event, nil
+}
@@ -0,0 +1,62 @@
+package repo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"test_news/internal/model"
+	"time"
+)
+
+func (s *pgdbTestSuite) TestOutboxRepo() {
+	db := s.tx.DB(s.ctx)
+
+	for _, event := range []model.OutboxEvent{
+		{Aggregate: "news", AggregateId: 1, Type: "created", Payload: []byte(`{"Id":1}`)},
+		{Aggregate: "news", AggregateId: 1, Type: "updated", Payload: []byte(`{"Id":1}`)},
+		{Aggregate: "news", AggregateId: 2, Type: "created", Payload: []byte(`{"Id":2}`)},
+	} {
+		assert.NoError(s.T(), s.outbox.Add(db, event))
+	}
+
+	// второе событие новости 1 ждет публикации первого
+	events, err := s.outbox.Claim(db, 10, time.Minute)
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), events, 2)
+	assert.Equal(s.T(), "created", events[0].Type)
+	assert.Equal(s.T(), int64(2), events[1].AggregateId)
+
+	// взятые события отложены на lease
+	leased, err := s.outbox.Claim(db, 10, time.Minute)
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), leased)
+
+	assert.NoError(s.T(), s.outbox.MarkFailed(db, events[1].Id, "broker unavailable", time.Now().Add(-time.Minute)))
+	assert.Equal(s.T(), ErrNotFound, s.outbox.MarkFailed(db, -1, "foobar", time.Now()))
+	assert.NoError(s.T(), s.outbox.MarkPublished(db, []int64{events[0].Id}))
+
+	events, err = s.outbox.Claim(db, 10, time.Minute)
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), events, 2)
+	assert.Equal(s.T(), "updated", events[0].Type)
+	assert.Equal(s.T(), int64(2), events[1].AggregateId)
+	assert.Equal(s.T(), 1, events[1].Attempts)
+
+	// dead letter больше не публикуется
+	assert.NoError(s.T(), s.outbox.MarkDead(db, events[1].Id, "broker unavailable"))
+	assert.Equal(s.T(), ErrNotFound, s.outbox.MarkDead(db, -1, "foobar"))
+	assert.NoError(s.T(), s.outbox.MarkFailed(db, events[0].Id, "broker unavailable", time.Now().Add(-time.Minute)))
+
+	events, err = s.outbox.Claim(db, 10, time.Minute)
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), events, 1)
+	assert.Equal(s.T(), "updated", events[0].Type)
+
+	last, err := s.outbox.FindLast(db, "news", 1, "updated")
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), events[0].Id, last.Id)
+	_, err = s.outbox.FindLast(db, "news", 1, "deleted")
+	assert.Equal(s.T(), ErrNotFound, err)
+
+	deleted, err := s.outbox.DeletePublished(db, time.Now().Add(time.Minute))
+	assert.NoError(s.T(), err)
+	assert.Equal(s.T(), int64(1), deleted)
+}
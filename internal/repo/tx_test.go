@@ -0,0 +1,41 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"test_news/internal/model"
+	"test_news/internal/repo/txmanager"
+)
+
+func (s *pgdbTestSuite) TestTxManager_savepoint() {
+	var outer, inner int64
+	err := s.tx.TxFunc(s.ctx, func(ctx context.Context, tx txmanager.TX) error {
+		id, err := s.news.Create(tx, model.News{Title: "outer", Content: "Content"})
+		if err != nil {
+			return err
+		}
+		outer = id
+
+		// вложенный TxFunc работает в savepoint: его ошибка откатывает только его изменения
+		err = s.tx.TxFunc(ctx, func(ctx context.Context, tx txmanager.TX) error {
+			id, err := s.news.Create(s.tx.DB(ctx), model.News{Title: "inner", Content: "Content"})
+			if err != nil {
+				return err
+			}
+			inner = id
+			return errors.New("rollback inner")
+		})
+		assert.Error(s.T(), err)
+
+		// DB(ctx) видит незакоммиченные изменения внешней транзакции
+		_, err = s.news.FindById(s.tx.DB(ctx), outer)
+		return err
+	})
+	assert.NoError(s.T(), err)
+
+	_, err = s.news.FindById(s.tx.DB(s.ctx), outer)
+	assert.NoError(s.T(), err)
+	_, err = s.news.FindById(s.tx.DB(s.ctx), inner)
+	assert.Equal(s.T(), ErrNotFound, err)
+}
@@ -0,0 +1,52 @@
+package txmanager
+
+import "github.com/jackc/pgx/v5"
+
+type Option func(o *txOptions)
+
+type txOptions struct {
+	retry RetryPolicy
+	tx    pgx.TxOptions
+}
+
+func newTxOptions(defaults, opts []Option) txOptions {
+	var o txOptions
+	for _, opt := range defaults {
+		opt(&o)
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithRetry повторяет транзакцию на ошибках 40001 и 40P01. Вложенные TxFunc (savepoint) не повторяются:
+// такая ошибка ломает всю транзакцию, повторить ее может только внешний TxFunc
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *txOptions) {
+		o.retry = policy
+	}
+}
+
+// WithIsolation задает уровень изоляции; по умолчанию - уровень сервера (read committed).
+// Опции транзакции не действуют на savepoint: он наследует режим внешней транзакции
+func WithIsolation(level pgx.TxIsoLevel) Option {
+	return func(o *txOptions) {
+		o.tx.IsoLevel = level
+	}
+}
+
+// ReadOnly - READ ONLY: запись в такой транзакции завершится ошибкой
+func ReadOnly() Option {
+	return func(o *txOptions) {
+		o.tx.AccessMode = pgx.ReadOnly
+	}
+}
+
+// Deferrable - DEFERRABLE; действует только вместе с Serializable и ReadOnly: транзакция ждет снимок,
+// на котором не может получить serialization failure
+func Deferrable() Option {
+	return func(o *txOptions) {
+		o.tx.DeferrableMode = pgx.Deferrable
+	}
+}
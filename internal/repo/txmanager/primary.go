@@ -0,0 +1,40 @@
+package txmanager
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type pinKey struct{}
+
+// pin - изменяемая метка в ctx: после первого коммита все чтения с этим ctx идут в primary
+type pin struct {
+	primary atomic.Bool
+}
+
+// ReadYourWrites возвращает ctx, в котором после записи чтение переключается с реплик на primary,
+// чтобы не увидеть состояние до своего же изменения. Ставится один раз на запрос
+func ReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(pinKey{}).(*pin); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, pinKey{}, &pin{})
+}
+
+// WithPrimary - все чтения с ctx идут в primary
+func WithPrimary(ctx context.Context) context.Context {
+	p := &pin{}
+	p.primary.Store(true)
+	return context.WithValue(ctx, pinKey{}, p)
+}
+
+func pinPrimary(ctx context.Context) {
+	if p, ok := ctx.Value(pinKey{}).(*pin); ok {
+		p.primary.Store(true)
+	}
+}
+
+func pinnedPrimary(ctx context.Context) bool {
+	p, ok := ctx.Value(pinKey{}).(*pin)
+	return ok && p.primary.Load()
+}
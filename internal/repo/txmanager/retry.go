@@ -0,0 +1,76 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+)
+
+// RetryPolicy - повтор транзакции при serialization failure и deadlock. Функция транзакции при этом
+// выполняется заново, поэтому она не должна иметь побочных эффектов вне БД, которые нельзя повторить
+type RetryPolicy struct {
+	// MaxAttempts - максимум попыток вместе с первой; <= 1 - без повторов
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+func retryableCode(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return "", false
+	}
+	return pgErr.Code, pgErr.Code == codeSerializationFailure || pgErr.Code == codeDeadlockDetected
+}
+
+// backoff - full jitter: случайная задержка от 0 до BaseDelay*2^(attempt-1), не больше MaxDelay
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, p.MaxDelay)
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d + 1)
+}
+
+// retry выполняет run, пока он возвращает retryable ошибку и есть попытки и время до дедлайна ctx
+func (m *manager) retry(ctx context.Context, policy RetryPolicy, run func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := run()
+		code, ok := retryableCode(err)
+		if !ok {
+			return err
+		}
+		if code == codeDeadlockDetected {
+			m.stats.deadlocks.Add(1)
+		} else {
+			m.stats.serializationFailures.Add(1)
+		}
+		if attempt >= policy.MaxAttempts {
+			m.stats.exhausted.Add(1)
+			return err
+		}
+
+		delay := policy.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			m.stats.exhausted.Add(1)
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package txmanager
+
+import "sync/atomic"
+
+// Stats - счетчики транзакций; savepoint'ы не учитываются
+type Stats struct {
+	Commits   uint64
+	Rollbacks uint64
+	// SerializationFailures, Deadlocks - все retryable ошибки, включая последнюю попытку
+	SerializationFailures uint64
+	Deadlocks             uint64
+	// Exhausted - транзакции, которые не удалось выполнить за MaxAttempts попыток
+	Exhausted uint64
+}
+
+type txStats struct {
+	commits               atomic.Uint64
+	rollbacks             atomic.Uint64
+	serializationFailures atomic.Uint64
+	deadlocks             atomic.Uint64
+	exhausted             atomic.Uint64
+}
+
+func (s *txStats) snapshot() Stats {
+	return Stats{
+		Commits:               s.commits.Load(),
+		Rollbacks:             s.rollbacks.Load(),
+		SerializationFailures: s.serializationFailures.Load(),
+		Deadlocks:             s.deadlocks.Load(),
+		Exhausted:             s.exhausted.Load(),
+	}
+}
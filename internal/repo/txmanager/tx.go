@@ -2,10 +2,12 @@ package txmanager
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"sync"
 	"test_news/pkg/postgres"
 )
 
@@ -22,37 +24,142 @@ type TX interface {
 }
 
 type Manager interface {
+	// DB - для чтения: транзакция из ctx, если она есть, иначе реплика (или primary, если ctx
+	// закреплен за ним через ReadYourWrites/WithPrimary). Реплика может отставать на PG_REPLICA_MAX_LAG
 	DB(ctx context.Context) Executor
-	TX(ctx context.Context) (TX, error)
-	TxFunc(ctx context.Context, f func(tx TX) error) (err error)
+	// Primary - для записи вне транзакции: транзакция из ctx, если она есть, иначе primary
+	Primary(ctx context.Context) Executor
+	// TX начинает транзакцию; если в ctx уже есть транзакция - savepoint внутри нее
+	TX(ctx context.Context, opts ...Option) (TX, error)
+	// TxFunc выполняет f в транзакции (или savepoint, если транзакция уже есть в ctx). ctx, переданный в f,
+	// содержит эту транзакцию: DB и вложенные TxFunc с ним работают внутри нее. Транзакция привязана
+	// к одному соединению, поэтому ctx из f нельзя использовать из нескольких горутин одновременно
+	TxFunc(ctx context.Context, f func(ctx context.Context, tx TX) error, opts ...Option) (err error)
+	Stats() Stats
+}
+
+type txKey struct{}
+
+// InTx - в ctx есть транзакция
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txExec)
+	return ok
+}
+
+// AfterTx выполняет f после завершения внешней транзакции из ctx (коммит или откат), без транзакции - сразу.
+// Например, сброс кэша: до коммита другие запросы читают старые данные и вернули бы их в кэш
+func AfterTx(ctx context.Context, f func()) {
+	if t, ok := ctx.Value(txKey{}).(*txExec); ok {
+		t.hooks.add(f)
+		return
+	}
+	f()
+}
+
+// txHooks - общие для транзакции и ее savepoint'ов
+type txHooks struct {
+	mu    sync.Mutex
+	funcs []func()
+}
+
+func (h *txHooks) add(f func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.funcs = append(h.funcs, f)
+}
+
+func (h *txHooks) run() {
+	h.mu.Lock()
+	funcs := h.funcs
+	h.funcs = nil
+	h.mu.Unlock()
+	for _, f := range funcs {
+		f()
+	}
 }
 
 type manager struct {
-	pg postgres.Postgres
+	pg       postgres.Postgres
+	defaults []Option
+	stats    txStats
 }
 
-func NewManager(pg postgres.Postgres) Manager {
+// NewManager - opts применяются к каждому TxFunc до опций конкретного вызова
+func NewManager(pg postgres.Postgres, opts ...Option) Manager {
 	return &manager{
-		pg: pg,
+		pg:       pg,
+		defaults: opts,
 	}
 }
 
+func (m *manager) Stats() Stats {
+	return m.stats.snapshot()
+}
+
 func (m *manager) DB(ctx context.Context) Executor {
+	if t, ok := ctx.Value(txKey{}).(*txExec); ok {
+		return &txExec{
+			ctx: ctx,
+			tx:  t.tx,
+		}
+	}
+	if pinnedPrimary(ctx) {
+		return &poolExec{
+			ctx:  ctx,
+			pool: m.pg.GetPool(),
+		}
+	}
+	return &poolExec{
+		ctx:  ctx,
+		pool: m.pg.GetReplicaPool(),
+	}
+}
+
+func (m *manager) Primary(ctx context.Context) Executor {
+	if t, ok := ctx.Value(txKey{}).(*txExec); ok {
+		return &txExec{
+			ctx: ctx,
+			tx:  t.tx,
+		}
+	}
 	return &poolExec{
 		ctx:  ctx,
 		pool: m.pg.GetPool(),
 	}
 }
 
-func (m *manager) TX(ctx context.Context) (TX, error) {
-	tx, err := m.pg.Begin(ctx)
+func (m *manager) TX(ctx context.Context, opts ...Option) (TX, error) {
+	o := newTxOptions(m.defaults, opts)
+	return m.begin(ctx, o.tx)
+}
+
+func (m *manager) begin(ctx context.Context, txOptions pgx.TxOptions) (TX, error) {
+	var (
+		tx  pgx.Tx
+		err error
+	)
+	t, nested := ctx.Value(txKey{}).(*txExec)
+	if nested {
+		// pgx реализует вложенную транзакцию через SAVEPOINT: Commit делает RELEASE, Rollback - ROLLBACK TO
+		tx, err = t.tx.Begin(ctx)
+	} else {
+		tx, err = m.pg.BeginTx(ctx, txOptions)
+	}
 	if err != nil {
 		return nil, err
 	}
-	return &txExec{
-		ctx: ctx,
-		tx:  tx,
-	}, nil
+	e := &txExec{
+		ctx:      ctx,
+		tx:       tx,
+		readOnly: txOptions.AccessMode == pgx.ReadOnly,
+	}
+	if nested {
+		e.hooks = t.hooks
+	} else {
+		e.stats = &m.stats
+		e.hooks = &txHooks{}
+	}
+	return e, nil
 }
 
 type poolExec struct {
@@ -73,8 +180,12 @@ func (p *poolExec) Query(sql string, args ...any) (pgx.Rows, error) {
 }
 
 type txExec struct {
-	ctx context.Context
-	tx  pgx.Tx
+	ctx      context.Context
+	tx       pgx.Tx
+	readOnly bool
+	// nil у savepoint
+	stats *txStats
+	hooks *txHooks
 }
 
 func (t *txExec) Exec(sql string, args ...any) (pgconn.CommandTag, error) {
@@ -89,18 +200,52 @@ func (t *txExec) Query(sql string, args ...any) (pgx.Rows, error) {
 	return t.tx.Query(t.ctx, sql, args...)
 }
 
+// Commit закрепляет ctx транзакции за primary (см. ReadYourWrites). Функции AfterTx выполняются после
+// коммита внешней транзакции, даже неудачного: неизвестно, применился ли он
 func (t *txExec) Commit(ctx context.Context) error {
-	return t.tx.Commit(ctx)
+	if t.stats != nil {
+		defer t.hooks.run()
+	}
+	if err := t.tx.Commit(ctx); err != nil {
+		return err
+	}
+	if t.stats != nil {
+		t.stats.commits.Add(1)
+	}
+	if !t.readOnly {
+		pinPrimary(t.ctx)
+	}
+	return nil
 }
 
 func (t *txExec) Rollback(ctx context.Context) error {
-	return t.tx.Rollback(ctx)
+	if t.stats != nil {
+		defer t.hooks.run()
+	}
+	if err := t.tx.Rollback(ctx); err != nil {
+		return err
+	}
+	if t.stats != nil {
+		t.stats.rollbacks.Add(1)
+	}
+	return nil
+}
+
+func (m *manager) TxFunc(ctx context.Context, f func(ctx context.Context, tx TX) error, opts ...Option) error {
+	o := newTxOptions(m.defaults, opts)
+
+	if _, nested := ctx.Value(txKey{}).(*txExec); nested || o.retry.MaxAttempts <= 1 {
+		return m.txFunc(ctx, f, o.tx)
+	}
+	return m.retry(ctx, o.retry, func() error {
+		return m.txFunc(ctx, f, o.tx)
+	})
 }
 
-func (m *manager) TxFunc(ctx context.Context, f func(tx TX) error) (err error) {
+func (m *manager) txFunc(ctx context.Context, f func(ctx context.Context, tx TX) error, txOptions pgx.TxOptions) (err error) {
 	const op = "txmanager.tx.TxFunc"
 
-	tx, err := m.TX(ctx)
+	tx, err := m.begin(ctx, txOptions)
 	if err != nil {
 		return fmt.Errorf("%s init TX error: %w", op, err)
 	}
@@ -108,12 +253,13 @@ func (m *manager) TxFunc(ctx context.Context, f func(tx TX) error) (err error) {
 		if tx == nil {
 			return
 		}
-		if e := tx.Rollback(ctx); e != nil {
+		// после неудачного Commit pgx уже закрыл транзакцию: ErrTxClosed не должен затирать ошибку коммита
+		if e := tx.Rollback(ctx); e != nil && !errors.Is(e, pgx.ErrTxClosed) {
 			err = fmt.Errorf("%s rollback TX error: %w", op, e)
 		}
 	}()
 
-	if err = f(tx); err != nil {
+	if err = f(context.WithValue(ctx, txKey{}, tx), tx); err != nil {
 		return fmt.Errorf("%s exec func error: %w", op, err)
 	}
 	if err = tx.Commit(ctx); err != nil {
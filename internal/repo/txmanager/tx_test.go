@@ -0,0 +1,332 @@
+package txmanager
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"test_news/pkg/postgres"
+	"testing"
+	"time"
+)
+
+// fakeTx закрывается после Commit, даже неудачного, как pgx
+type fakeTx struct {
+	pgx.Tx
+	commitErr error
+	closed    bool
+}
+
+func (t *fakeTx) Commit(context.Context) error {
+	if t.closed {
+		return pgx.ErrTxClosed
+	}
+	t.closed = true
+	return t.commitErr
+}
+
+func (t *fakeTx) Rollback(context.Context) error {
+	if t.closed {
+		return pgx.ErrTxClosed
+	}
+	t.closed = true
+	return nil
+}
+
+// Begin - savepoint
+func (t *fakeTx) Begin(context.Context) (pgx.Tx, error) {
+	return &fakeTx{}, nil
+}
+
+// fakePG отдает транзакции, Commit которых по очереди возвращает commitErrs, затем nil
+type fakePG struct {
+	postgres.Postgres
+	commitErrs []error
+	begins     int
+	txOptions  pgx.TxOptions
+	primary    *pgxpool.Pool
+	replica    *pgxpool.Pool
+}
+
+func (p *fakePG) GetPool() *pgxpool.Pool {
+	return p.primary
+}
+
+func (p *fakePG) GetReplicaPool() *pgxpool.Pool {
+	return p.replica
+}
+
+func (p *fakePG) BeginTx(_ context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	p.begins++
+	p.txOptions = txOptions
+	tx := &fakeTx{}
+	if len(p.commitErrs) > 0 {
+		tx.commitErr, p.commitErrs = p.commitErrs[0], p.commitErrs[1:]
+	}
+	return tx, nil
+}
+
+func pgError(code string) error {
+	return &pgconn.PgError{Code: code}
+}
+
+func TestManager_TxFunc_retry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+	someErr := errors.New("some error")
+
+	testCases := []struct {
+		testName   string
+		policy     RetryPolicy
+		commitErrs []error
+		funcErr    error
+		expectErr  bool
+		expectRuns int
+		expect     Stats
+	}{
+		{
+			testName:   "retried then committed",
+			policy:     policy,
+			commitErrs: []error{pgError(codeSerializationFailure), pgError(codeDeadlockDetected)},
+			expectRuns: 3,
+			expect:     Stats{Commits: 1, SerializationFailures: 1, Deadlocks: 1},
+		},
+		{
+			testName:   "exhausted",
+			policy:     policy,
+			commitErrs: []error{pgError(codeSerializationFailure), pgError(codeSerializationFailure), pgError(codeSerializationFailure)},
+			expectErr:  true,
+			expectRuns: 3,
+			expect:     Stats{SerializationFailures: 3, Exhausted: 1},
+		},
+		{
+			testName:   "not retryable",
+			policy:     policy,
+			funcErr:    someErr,
+			expectErr:  true,
+			expectRuns: 1,
+			expect:     Stats{Rollbacks: 1},
+		},
+		{
+			testName:   "other sqlstate",
+			policy:     policy,
+			commitErrs: []error{pgError("23505")},
+			expectErr:  true,
+			expectRuns: 1,
+		},
+		{
+			testName:   "retry disabled",
+			policy:     RetryPolicy{MaxAttempts: 1},
+			commitErrs: []error{pgError(codeSerializationFailure)},
+			expectErr:  true,
+			expectRuns: 1,
+			expect:     Stats{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			pg := &fakePG{commitErrs: tc.commitErrs}
+			m := NewManager(pg, WithRetry(tc.policy))
+
+			runs := 0
+			err := m.TxFunc(context.Background(), func(ctx context.Context, tx TX) error {
+				runs++
+				return tc.funcErr
+			})
+			assert.Equal(t, tc.expectErr, err != nil)
+			assert.Equal(t, tc.expectRuns, runs)
+			assert.Equal(t, tc.expectRuns, pg.begins)
+			assert.Equal(t, tc.expect, m.Stats())
+		})
+	}
+}
+
+func TestManager_TxFunc_retryOption(t *testing.T) {
+	pg := &fakePG{commitErrs: []error{pgError(codeSerializationFailure)}}
+	m := NewManager(pg)
+
+	err := m.TxFunc(context.Background(), func(ctx context.Context, tx TX) error {
+		return nil
+	}, WithRetry(RetryPolicy{MaxAttempts: 2}))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, pg.begins)
+}
+
+func TestManager_TxFunc_retryDeadline(t *testing.T) {
+	pg := &fakePG{commitErrs: []error{pgError(codeSerializationFailure), pgError(codeSerializationFailure)}}
+	m := NewManager(pg, WithRetry(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := m.TxFunc(ctx, func(ctx context.Context, tx TX) error {
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, 1, pg.begins)
+	assert.Equal(t, uint64(1), m.Stats().Exhausted)
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+	for attempt := 1; attempt <= 10; attempt++ {
+		limit := min(p.BaseDelay<<(attempt-1), p.MaxDelay)
+		for range 100 {
+			d := p.backoff(attempt)
+			if d < 0 || d > limit {
+				t.Fatalf("attempt %d: backoff %s out of [0, %s]", attempt, d, limit)
+			}
+		}
+	}
+	assert.Equal(t, time.Duration(0), RetryPolicy{}.backoff(1))
+}
+
+func TestManager_TxFunc_options(t *testing.T) {
+	testCases := []struct {
+		testName string
+		defaults []Option
+		opts     []Option
+		expect   pgx.TxOptions
+	}{
+		{
+			testName: "defaults",
+			expect:   pgx.TxOptions{},
+		},
+		{
+			testName: "repeatable read read only",
+			opts:     []Option{WithIsolation(pgx.RepeatableRead), ReadOnly()},
+			expect:   pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly},
+		},
+		{
+			testName: "serializable deferrable",
+			opts:     []Option{WithIsolation(pgx.Serializable), ReadOnly(), Deferrable()},
+			expect:   pgx.TxOptions{IsoLevel: pgx.Serializable, AccessMode: pgx.ReadOnly, DeferrableMode: pgx.Deferrable},
+		},
+		{
+			testName: "call overrides manager defaults",
+			defaults: []Option{WithIsolation(pgx.RepeatableRead)},
+			opts:     []Option{WithIsolation(pgx.Serializable)},
+			expect:   pgx.TxOptions{IsoLevel: pgx.Serializable},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			pg := &fakePG{}
+			m := NewManager(pg, tc.defaults...)
+
+			err := m.TxFunc(context.Background(), func(ctx context.Context, tx TX) error {
+				return nil
+			}, tc.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, pg.txOptions)
+		})
+	}
+}
+
+func TestManager_TX_savepointIgnoresOptions(t *testing.T) {
+	pg := &fakePG{}
+	m := NewManager(pg)
+
+	err := m.TxFunc(context.Background(), func(ctx context.Context, tx TX) error {
+		// savepoint начинается через pgx.Tx.Begin, а не через пул
+		_, err := m.TX(ctx, WithIsolation(pgx.Serializable))
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pg.begins)
+	assert.Equal(t, pgx.TxOptions{}, pg.txOptions)
+}
+
+func TestManager_DB_routing(t *testing.T) {
+	pg := &fakePG{primary: &pgxpool.Pool{}, replica: &pgxpool.Pool{}}
+	m := NewManager(pg)
+
+	pool := func(e Executor) *pgxpool.Pool {
+		p, ok := e.(*poolExec)
+		if !ok {
+			t.Fatalf("expected pool executor, got %T", e)
+		}
+		return p.pool
+	}
+
+	ctx := context.Background()
+	assert.Same(t, pg.replica, pool(m.DB(ctx)))
+	assert.Same(t, pg.primary, pool(m.Primary(ctx)))
+	assert.Same(t, pg.primary, pool(m.DB(WithPrimary(ctx))))
+
+	// без ReadYourWrites коммит не закрепляет ctx
+	assert.NoError(t, m.TxFunc(ctx, func(ctx context.Context, tx TX) error { return nil }))
+	assert.Same(t, pg.replica, pool(m.DB(ctx)))
+
+	ctx = ReadYourWrites(ctx)
+	assert.Same(t, pg.replica, pool(m.DB(ctx)))
+
+	// read only транзакция ничего не пишет
+	assert.NoError(t, m.TxFunc(ctx, func(ctx context.Context, tx TX) error { return nil }, ReadOnly()))
+	assert.Same(t, pg.replica, pool(m.DB(ctx)))
+
+	err := m.TxFunc(ctx, func(ctx context.Context, tx TX) error {
+		_, ok := m.DB(ctx).(*txExec)
+		assert.True(t, ok)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Same(t, pg.primary, pool(m.DB(ctx)))
+}
+
+func TestManager_DB_rollbackKeepsReplica(t *testing.T) {
+	pg := &fakePG{primary: &pgxpool.Pool{}, replica: &pgxpool.Pool{}}
+	m := NewManager(pg)
+
+	ctx := ReadYourWrites(context.Background())
+	err := m.TxFunc(ctx, func(ctx context.Context, tx TX) error {
+		return errors.New("some error")
+	})
+	assert.Error(t, err)
+	assert.Same(t, pg.replica, m.DB(ctx).(*poolExec).pool)
+}
+
+func TestAfterTx(t *testing.T) {
+	m := NewManager(&fakePG{})
+
+	var calls int
+	AfterTx(context.Background(), func() { calls++ })
+	assert.Equal(t, 1, calls)
+
+	// отложено до коммита внешней транзакции, а не savepoint'а
+	err := m.TxFunc(context.Background(), func(ctx context.Context, tx TX) error {
+		assert.True(t, InTx(ctx))
+		err := m.TxFunc(ctx, func(ctx context.Context, tx TX) error {
+			AfterTx(ctx, func() { calls++ })
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, calls)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	// и после отката
+	err = m.TxFunc(context.Background(), func(ctx context.Context, tx TX) error {
+		AfterTx(ctx, func() { calls++ })
+		return errors.New("some error")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 3, calls)
+
+	// и после неудачного коммита: неизвестно, применился ли он
+	m = NewManager(&fakePG{commitErrs: []error{errors.New("connection reset")}})
+	err = m.TxFunc(context.Background(), func(ctx context.Context, tx TX) error {
+		AfterTx(ctx, func() { calls++ })
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 4, calls)
+	assert.False(t, InTx(context.Background()))
+}
@@ -0,0 +1,152 @@
+package repo
+
+import (
+	"github.com/jackc/pgx/v5"
+	"test_news/internal/model"
+	"time"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+type Webhooks interface {
+	Create(exec Querier, webhook model.Webhook) (int64, error)
+	List(exec Querier) ([]model.Webhook, error)
+	Delete(exec Querier, id int64) error
+	// Enqueue создает доставки для всех подписок на event, чей фильтр категорий пересекается с categories
+	Enqueue(exec Querier, event string, categories []int64, payload []byte) error
+	// Claim берет до limit готовых доставок и откладывает их на lease, чтобы другие воркеры их не взяли
+	Claim(exec Querier, limit int, lease time.Duration) ([]model.WebhookDelivery, error)
+	RecordAttempt(exec Querier, attempt model.WebhookAttempt) error
+	UpdateDelivery(exec Querier, id int64, status string, attempts int, nextAttemptAt time.Time) error
+	FindAttempts(exec Querier, webhookId int64, limit int) ([]model.WebhookAttempt, error)
+}
+
+type webhooksRepo struct{}
+
+func NewWebhooksRepo() Webhooks {
+	return &webhooksRepo{}
+}
+
+func (r *webhooksRepo) Create(exec Querier, webhook model.Webhook) (int64, error) {
+	sql := "INSERT INTO webhooks (url, secret, events, categories) VALUES ($1, $2, $3, $4) RETURNING id"
+
+	categories := webhook.Categories
+	if categories == nil {
+		categories = []int64{}
+	}
+
+	var id int64
+	if err := exec.QueryRow(sql, webhook.URL, webhook.Secret, webhook.Events, categories).Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *webhooksRepo) List(exec Querier) ([]model.Webhook, error) {
+	sql := "SELECT id, url, secret, events, categories, created_at FROM webhooks ORDER BY id"
+
+	rows, err := exec.Query(sql)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[model.Webhook])
+}
+
+func (r *webhooksRepo) Delete(exec Querier, id int64) error {
+	sql := "DELETE FROM webhooks WHERE id = $1"
+
+	result, err := exec.Exec(sql, id)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *webhooksRepo) Enqueue(exec Querier, event string, categories []int64, payload []byte) error {
+	sql := `
+		INSERT INTO webhook_deliveries (webhook_id, event, payload)
+		SELECT id, $1, $3
+		FROM webhooks
+		WHERE $1 = ANY (events)
+		  AND (cardinality(categories) = 0 OR categories && $2::bigint[])
+	`
+
+	_, err := exec.Exec(sql, event, categories, payload)
+	return err
+}
+
+func (r *webhooksRepo) Claim(exec Querier, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	sql := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = now() + $2 * interval '1 millisecond'
+		FROM webhooks w
+		WHERE w.id = d.webhook_id
+		  AND d.id IN (
+		      SELECT id
+		      FROM webhook_deliveries
+		      WHERE status = 'pending' AND next_attempt_at <= now()
+		      ORDER BY next_attempt_at, id
+		      LIMIT $1
+		      FOR UPDATE SKIP LOCKED
+		  )
+		RETURNING d.id, d.webhook_id, d.event, d.payload, d.attempts, w.url, w.secret
+	`
+
+	rows, err := exec.Query(sql, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[model.WebhookDelivery])
+}
+
+func (r *webhooksRepo) RecordAttempt(exec Querier, attempt model.WebhookAttempt) error {
+	sql := "INSERT INTO webhook_attempts (delivery_id, status_code, error, duration_ms) VALUES ($1, $2, $3, $4)"
+
+	_, err := exec.Exec(sql, attempt.DeliveryId, attempt.StatusCode, attempt.Error, attempt.DurationMs)
+	return err
+}
+
+func (r *webhooksRepo) UpdateDelivery(exec Querier, id int64, status string, attempts int, nextAttemptAt time.Time) error {
+	sql := `
+		UPDATE webhook_deliveries
+		SET status = $2,
+		    attempts = $3,
+		    next_attempt_at = $4,
+		    delivered_at = CASE WHEN $2 = 'delivered' THEN now() END
+		WHERE id = $1
+	`
+
+	result, err := exec.Exec(sql, id, status, attempts, nextAttemptAt)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// FindAttempts - журнал попыток доставки подписки, новые первыми
+func (r *webhooksRepo) FindAttempts(exec Querier, webhookId int64, limit int) ([]model.WebhookAttempt, error) {
+	sql := `
+		SELECT a.id, a.delivery_id, d.event, d.status, a.status_code, a.error, a.duration_ms, a.created_at
+		FROM webhook_attempts a
+		JOIN webhook_deliveries d ON d.id = a.delivery_id
+		WHERE d.webhook_id = $1
+		ORDER BY a.id DESC
+		LIMIT $2
+	`
+
+	rows, err := exec.Query(sql, webhookId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[model.WebhookAttempt])
+}
@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"test_news/internal/model"
+	"time"
+)
+
+func (s *pgdbTestSuite) TestWebhooksRepo() {
+	db := s.tx.DB(s.ctx)
+
+	all, err := s.webhooks.Create(db, model.Webhook{URL: "http://localhost/all", Secret: "secret", Events: []string{"created"}})
+	assert.NoError(s.T(), err)
+	_, err = s.webhooks.Create(db, model.Webhook{URL: "http://localhost/filtered", Secret: "secret", Events: []string{"created"}, Categories: []int64{1}})
+	assert.NoError(s.T(), err)
+	_, err = s.webhooks.Create(db, model.Webhook{URL: "http://localhost/deleted", Secret: "secret", Events: []string{"deleted"}})
+	assert.NoError(s.T(), err)
+
+	webhooks, err := s.webhooks.List(db)
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), webhooks, 3)
+	assert.Equal(s.T(), []int64{}, webhooks[0].Categories)
+
+	// подходит только подписка без фильтра категорий
+	assert.NoError(s.T(), s.webhooks.Enqueue(db, "created", []int64{2}, []byte(`{"Event":"created"}`)))
+
+	deliveries, err := s.webhooks.Claim(db, 10, time.Minute)
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), deliveries, 1)
+	delivery := deliveries[0]
+	assert.Equal(s.T(), all, delivery.WebhookId)
+	assert.Equal(s.T(), "http://localhost/all", delivery.URL)
+	assert.JSONEq(s.T(), `{"Event":"created"}`, string(delivery.Payload))
+
+	// взятая доставка не выдается повторно до конца lease
+	deliveries, err = s.webhooks.Claim(db, 10, time.Minute)
+	assert.NoError(s.T(), err)
+	assert.Empty(s.T(), deliveries)
+
+	assert.NoError(s.T(), s.webhooks.RecordAttempt(db, model.WebhookAttempt{DeliveryId: delivery.Id, StatusCode: 200, DurationMs: 15}))
+	assert.NoError(s.T(), s.webhooks.UpdateDelivery(db, delivery.Id, WebhookDeliveryDelivered, 1, time.Now()))
+	assert.Equal(s.T(), ErrNotFound, s.webhooks.UpdateDelivery(db, -1, WebhookDeliveryDead, 1, time.Now()))
+
+	attempts, err := s.webhooks.FindAttempts(db, all, 10)
+	assert.NoError(s.T(), err)
+	assert.Len(s.T(), attempts, 1)
+	assert.Equal(s.T(), WebhookDeliveryDelivered, attempts[0].Status)
+	assert.Equal(s.T(), 200, attempts[0].StatusCode)
+
+	assert.NoError(s.T(), s.webhooks.Delete(db, all))
+	assert.Equal(s.T(), ErrNotFound, s.webhooks.Delete(db, all))
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"test_news/internal/model"
+	"test_news/internal/repo"
+	"test_news/internal/repo/txmanager"
+	"time"
+)
+
+const (
+	APIKeyPrefix = "tn_live_"
+
+	apiKeyIdBytes     = 6
+	apiKeySecretBytes = 24
+)
+
+const (
+	ScopeNewsRead  = "news:read"
+	ScopeNewsWrite = "news:write"
+	ScopeAdmin     = "admin"
+)
+
+var AllScopes = []string{ScopeNewsRead, ScopeNewsWrite, ScopeAdmin}
+
+// JWTScopes - jwt выдает публичный /authorize, поэтому без admin: администрирование только через
+// api ключ со scope admin или ключ оператора
+var JWTScopes = []string{ScopeNewsRead, ScopeNewsWrite}
+
+// SubjectOperator - субъект ключа оператора из конфига
+const SubjectOperator = "operator"
+
+type Principal struct {
+	Subject string
+	Scopes  []string
+}
+
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+type apiKeysService struct {
+	tx          txmanager.Manager
+	keys        repo.APIKeys
+	audit       repo.Audit
+	operatorKey string
+}
+
+// operatorKey - ключ со всеми scope не из БД: с ним создается первый admin ключ; пусто - выключен
+func newAPIKeysService(tx txmanager.Manager, keys repo.APIKeys, audit repo.Audit, operatorKey string) *apiKeysService {
+	return &apiKeysService{
+		tx:          tx,
+		keys:        keys,
+		audit:       audit,
+		operatorKey: operatorKey,
+	}
+}
+
+type APIKeyCreate struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+func (s *apiKeysService) Create(ctx context.Context, input APIKeyCreate) (string, model.APIKey, error) {
+	const op = "service.apikeys.Create"
+
+	for _, scope := range input.Scopes {
+		if !slices.Contains(AllScopes, scope) {
+			return "", model.APIKey{}, ErrUnknownScope
+		}
+	}
+
+	prefix, err := randomHex(apiKeyIdBytes)
+	if err != nil {
+		return "", model.APIKey{}, fmt.Errorf("%s generate prefix error: %w", op, err)
+	}
+	secret, err := randomHex(apiKeySecretBytes)
+	if err != nil {
+		return "", model.APIKey{}, fmt.Errorf("%s generate secret error: %w", op, err)
+	}
+	key := APIKeyPrefix + prefix + "_" + secret
+
+	apiKey := model.APIKey{
+		Name:      input.Name,
+		Prefix:    prefix,
+		Hash:      hashAPIKey(key),
+		Scopes:    input.Scopes,
+		ExpiresAt: input.ExpiresAt,
+	}
+	err = s.tx.TxFunc(ctx, func(ctx context.Context, tx txmanager.TX) error {
+		id, err := s.keys.Create(tx, apiKey)
+		if err != nil {
+			return fmt.Errorf("%s create api key error: %w", op, err)
+		}
+		apiKey.Id = id
+		if err = writeAudit(ctx, s.audit, tx, AuditEntityAPIKey, id, AuditActionCreate, nil, apiKey); err != nil {
+			return fmt.Errorf("%s write audit error: %w", op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", model.APIKey{}, err
+	}
+	return key, apiKey, nil
+}
+
+func (s *apiKeysService) List(ctx context.Context) ([]model.APIKey, error) {
+	const op = "service.apikeys.List"
+
+	keys, err := s.keys.List(s.tx.DB(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return keys, nil
+}
+
+func (s *apiKeysService) Revoke(ctx context.Context, id int64) error {
+	const op = "service.apikeys.Revoke"
+
+	return s.tx.TxFunc(ctx, func(ctx context.Context, tx txmanager.TX) error {
+		if err := s.keys.Revoke(tx, id); err != nil {
+			if errors.Is(err, repo.ErrNotFound) {
+				return ErrAPIKeyNotFound
+			}
+			return fmt.Errorf("%s revoke api key error: %w", op, err)
+		}
+		if err := writeAudit(ctx, s.audit, tx, AuditEntityAPIKey, id, AuditActionRevoke, nil, nil); err != nil {
+			return fmt.Errorf("%s write audit error: %w", op, err)
+		}
+		return nil
+	})
+}
+
+func (s *apiKeysService) Validate(ctx context.Context, key string) (Principal, error) {
+	const op = "service.apikeys.Validate"
+
+	if s.operatorKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(s.operatorKey)) == 1 {
+		return Principal{Subject: SubjectOperator, Scopes: AllScopes}, nil
+	}
+
+	prefix, ok := parseAPIKey(key)
+	if !ok {
+		return Principal{}, ErrAPIKeyInvalid
+	}
+
+	// отозванный ключ не должен проходить из-за отставания реплики
+	apiKey, err := s.keys.FindByPrefix(s.tx.Primary(ctx), prefix)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return Principal{}, ErrAPIKeyInvalid
+		}
+		return Principal{}, fmt.Errorf("%s find api key error: %w", op, err)
+	}
+	if subtle.ConstantTimeCompare([]byte(apiKey.Hash), []byte(hashAPIKey(key))) != 1 {
+		return Principal{}, ErrAPIKeyInvalid
+	}
+	if apiKey.RevokedAt != nil {
+		return Principal{}, ErrAPIKeyInvalid
+	}
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(time.Now()) {
+		return Principal{}, ErrAPIKeyInvalid
+	}
+
+	if err = s.keys.Touch(s.tx.Primary(ctx), apiKey.Id); err != nil {
+		return Principal{}, fmt.Errorf("%s touch api key error: %w", op, err)
+	}
+	return Principal{
+		Subject: fmt.Sprintf("apikey:%d", apiKey.Id),
+		Scopes:  apiKey.Scopes,
+	}, nil
+}
+
+// формат ключа: tn_live_<prefix>_<secret>
+func parseAPIKey(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, APIKeyPrefix)
+	if !ok {
+		return "", false
+	}
+	prefix, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(prefix) != apiKeyIdBytes*2 || len(secret) != apiKeySecretBytes*2 {
+		return "", false
+	}
+	return prefix, true
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"test_news/internal/mocks/repomocks"
+	"test_news/internal/mocks/txmocks"
+	"test_news/internal/model"
+	"test_news/internal/repo"
+	"testing"
+	"time"
+)
+
+func TestAPIKeysService_Create(t *testing.T) {
+	testCases := []struct {
+		testName      string
+		input         APIKeyCreate
+		mockBehaviour func(k *repomocks.MockAPIKeys, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX)
+		expectErr     error
+	}{
+		{
+			testName: "correct test",
+			input: APIKeyCreate{
+				Name:   "bot",
+				Scopes: []string{ScopeNewsRead, ScopeNewsWrite},
+			},
+			mockBehaviour: func(k *repomocks.MockAPIKeys, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX) {
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
+				k.EXPECT().Create(tx, gomock.Any()).Return(int64(1), nil)
+				au.EXPECT().Create(tx, gomock.Any()).Return(nil)
+				tx.EXPECT().Commit(gomock.Any()).Return(nil)
+			},
+			expectErr: nil,
+		},
+		{
+			testName: "unknown scope",
+			input: APIKeyCreate{
+				Name:   "bot",
+				Scopes: []string{"news:delete"},
+			},
+			mockBehaviour: func(k *repomocks.MockAPIKeys, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX) {
+			},
+			expectErr: ErrUnknownScope,
+		},
+		{
+			testName: "unexpected error",
+			input: APIKeyCreate{
+				Name:   "bot",
+				Scopes: []string{ScopeNewsRead},
+			},
+			mockBehaviour: func(k *repomocks.MockAPIKeys, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX) {
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
+				k.EXPECT().Create(tx, gomock.Any()).Return(int64(0), errUnexpectedError)
+				tx.EXPECT().Rollback(gomock.Any()).Return(nil)
+			},
+			expectErr: errUnexpectedError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			k := repomocks.NewMockAPIKeys(ctrl)
+			au := repomocks.NewMockAudit(ctrl)
+			mgr := txmocks.NewMockManager(ctrl)
+			tx := txmocks.NewMockTX(ctrl)
+
+			tc.mockBehaviour(k, au, mgr, tx)
+
+			s := newAPIKeysService(mgr, k, au, "")
+
+			key, apiKey, err := s.Create(context.Background(), tc.input)
+
+			assert.ErrorIs(t, err, tc.expectErr)
+
+			if tc.expectErr == nil {
+				assert.True(t, strings.HasPrefix(key, APIKeyPrefix+apiKey.Prefix+"_"))
+				assert.Equal(t, hashAPIKey(key), apiKey.Hash)
+				assert.Equal(t, int64(1), apiKey.Id)
+
+				prefix, ok := parseAPIKey(key)
+				assert.True(t, ok)
+				assert.Equal(t, apiKey.Prefix, prefix)
+			}
+		})
+	}
+}
+
+func TestAPIKeysService_Validate(t *testing.T) {
+	const (
+		prefix      = "0123456789ab"
+		key         = APIKeyPrefix + prefix + "_000000000000000000000000000000000000000000000000"
+		operatorKey = "operator-secret"
+	)
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	testCases := []struct {
+		testName      string
+		key           string
+		mockBehaviour func(k *repomocks.MockAPIKeys, mgr *txmocks.MockManager, exec *txmocks.MockExecutor)
+		expectOutput  Principal
+		expectErr     error
+	}{
+		{
+			testName: "correct test",
+			key:      key,
+			mockBehaviour: func(k *repomocks.MockAPIKeys, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {
+				mgr.EXPECT().Primary(gomock.Any()).Return(exec).Times(2)
+				k.EXPECT().FindByPrefix(exec, prefix).Return(model.APIKey{
+					Id:        1,
+					Prefix:    prefix,
+					Hash:      hashAPIKey(key),
+					Scopes:    []string{ScopeNewsRead},
+					ExpiresAt: &future,
+				}, nil)
+				k.EXPECT().Touch(exec, int64(1)).Return(nil)
+			},
+			expectOutput: Principal{
+				Subject: "apikey:1",
+				Scopes:  []string{ScopeNewsRead},
+			},
+			expectErr: nil,
+		},
+		{
+			testName:      "operator key",
+			key:           operatorKey,
+			mockBehaviour: func(k *repomocks.MockAPIKeys, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {},
+			expectOutput: Principal{
+				Subject: SubjectOperator,
+				Scopes:  AllScopes,
+			},
+		},
+		{
+			testName:      "incorrect format",
+			key:           "foobar",
+			mockBehaviour: func(k *repomocks.MockAPIKeys, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {},
+			expectErr:     ErrAPIKeyInvalid,
+		},
+		{
+			testName: "key not found",
+			key:      key,
+			mockBehaviour: func(k *repomocks.MockAPIKeys, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {
+				mgr.EXPECT().Primary(gomock.Any()).Return(exec)
+				k.EXPECT().FindByPrefix(exec, prefix).Return(model.APIKey{}, repo.ErrNotFound)
+			},
+			expectErr: ErrAPIKeyInvalid,
+		},
+		{
+			testName: "hash mismatch",
+			key:      key,
+			mockBehaviour: func(k *repomocks.MockAPIKeys, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {
+				mgr.EXPECT().Primary(gomock.Any()).Return(exec)
+				k.EXPECT().FindByPrefix(exec, prefix).Return(model.APIKey{
+					Id:     1,
+					Prefix: prefix,
+					Hash:   hashAPIKey("another key"),
+				}, nil)
+			},
+			expectErr: ErrAPIKeyInvalid,
+		},
+		{
+			testName: "key revoked",
+			key:      key,
+			mockBehaviour: func(k *repomocks.MockAPIKeys, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {
+				mgr.EXPECT().Primary(gomock.Any()).Return(exec)
+				k.EXPECT().FindByPrefix(exec, prefix).Return(model.APIKey{
+					Id:        1,
+					Prefix:    prefix,
+					Hash:      hashAPIKey(key),
+					RevokedAt: &past,
+				}, nil)
+			},
+			expectErr: ErrAPIKeyInvalid,
+		},
+		{
+			testName: "key expired",
+			key:      key,
+			mockBehaviour: func(k *repomocks.MockAPIKeys, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {
+				mgr.EXPECT().Primary(gomock.Any()).Return(exec)
+				k.EXPECT().FindByPrefix(exec, prefix).Return(model.APIKey{
+					Id:        1,
+					Prefix:    prefix,
+					Hash:      hashAPIKey(key),
+					ExpiresAt: &past,
+				}, nil)
+			},
+			expectErr: ErrAPIKeyInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			k := repomocks.NewMockAPIKeys(ctrl)
+			mgr := txmocks.NewMockManager(ctrl)
+			exec := txmocks.NewMockExecutor(ctrl)
+
+			tc.mockBehaviour(k, mgr, exec)
+
+			s := newAPIKeysService(mgr, k, nil, operatorKey)
+
+			actual, err := s.Validate(context.Background(), tc.key)
+
+			assert.ErrorIs(t, err, tc.expectErr)
+			assert.Equal(t, tc.expectOutput, actual)
+		})
+	}
+}
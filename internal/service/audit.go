@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"test_news/internal/model"
+	"test_news/internal/repo"
+	"test_news/internal/repo/txmanager"
+	"time"
+)
+
+const (
+	AuditEntityNews       = "news"
+	AuditEntityCategories = "news_categories"
+	AuditEntityAPIKey     = "api_key"
+	AuditEntityWebhook    = "webhook"
+
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	AuditActionRevoke = "revoke"
+
+	anonymousActor = "anonymous"
+)
+
+type Actor struct {
+	Subject string
+	IP      string
+	Route   string
+}
+
+type actorKey struct{}
+
+func ContextWithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func actorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	if actor.Subject == "" {
+		actor.Subject = anonymousActor
+	}
+	return actor
+}
+
+func writeAudit(ctx context.Context, audit repo.Audit, exec repo.Querier, entity string, id int64, action string, before, after any) error {
+	entry, err := newAuditEntry(ctx, entity, id, action, before, after)
+	if err != nil {
+		return err
+	}
+	return audit.Create(exec, entry)
+}
+
+// before/after == nil пишутся в лог как NULL (нет состояния до создания или после удаления)
+func newAuditEntry(ctx context.Context, entity string, entityId int64, action string, before, after any) (model.AuditEntry, error) {
+	actor := actorFromContext(ctx)
+	entry := model.AuditEntry{
+		Entity:   entity,
+		EntityId: entityId,
+		Action:   action,
+		Actor:    actor.Subject,
+		IP:       actor.IP,
+		Route:    actor.Route,
+	}
+	var err error
+	if before != nil {
+		if entry.Before, err = json.Marshal(before); err != nil {
+			return model.AuditEntry{}, err
+		}
+	}
+	if after != nil {
+		if entry.After, err = json.Marshal(after); err != nil {
+			return model.AuditEntry{}, err
+		}
+	}
+	return entry, nil
+}
+
+type auditService struct {
+	tx    txmanager.Manager
+	audit repo.Audit
+}
+
+func newAuditService(tx txmanager.Manager, audit repo.Audit) *auditService {
+	return &auditService{
+		tx:    tx,
+		audit: audit,
+	}
+}
+
+type AuditQuery struct {
+	Entity   string
+	EntityId int64
+	Actor    string
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+	Offset   int
+}
+
+func (s *auditService) Find(ctx context.Context, query AuditQuery) ([]model.AuditEntry, error) {
+	const op = "service.audit.Find"
+
+	entries, err := s.audit.Find(s.tx.DB(ctx), repo.AuditFilter(query))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return entries, nil
+}
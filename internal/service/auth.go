@@ -21,9 +21,14 @@ func newAuthService(key string) *authService {
 }
 
 func (s *authService) Create() (string, error) {
+	subject, err := randomHex(8)
+	if err != nil {
+		return "", err
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &jwt.StandardClaims{
 		ExpiresAt: time.Now().Add(TTL).Unix(),
 		IssuedAt:  time.Now().Unix(),
+		Subject:   subject,
 	})
 	signedToken, err := token.SignedString(s.key)
 	if err != nil {
@@ -32,15 +37,16 @@ func (s *authService) Create() (string, error) {
 	return signedToken, nil
 }
 
-func (s *authService) Validate(tokenString string) bool {
-	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+func (s *authService) Validate(tokenString string) (string, bool) {
+	claims := &jwt.StandardClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("incorrect sign method")
 		}
 		return s.key, nil
 	})
-	if err != nil {
-		return false
+	if err != nil || !token.Valid {
+		return "", false
 	}
-	return token.Valid
+	return claims.Subject, true
 }
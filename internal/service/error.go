@@ -2,10 +2,49 @@ package service
 
 import "errors"
 
+// категории ошибок: по ним транспорт выбирает код ответа, проверка - errors.Is(err, ErrNotFound)
 var (
-	ErrNewsNotFound            = errors.New("news not found")
-	ErrCategoriesAlreadyExists = errors.New("categories already exists")
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrInvalidInput  = errors.New("invalid input")
+)
 
-	ErrNotFound      = errors.Join(ErrNewsNotFound)
-	ErrAlreadyExists = errors.Join(ErrCategoriesAlreadyExists)
+var (
+	ErrNewsNotFound            = newError(ErrNotFound, "news not found")
+	ErrCategoriesAlreadyExists = newError(ErrAlreadyExists, "categories already exists")
+	ErrAPIKeyNotFound          = newError(ErrNotFound, "api key not found")
+	ErrAPIKeyInvalid           = errors.New("invalid api key")
+	ErrUnknownScope            = newError(ErrInvalidInput, "unknown scope")
+	ErrSitemapNotFound         = newError(ErrNotFound, "sitemap not found")
+	ErrWebhookNotFound         = newError(ErrNotFound, "webhook not found")
+	ErrUnknownWebhookEvent     = newError(ErrInvalidInput, "unknown webhook event")
+	ErrInvalidWebhookURL       = newError(ErrInvalidInput, "invalid webhook url")
+	ErrWebhookURLNotPublic     = newError(ErrInvalidInput, "webhook url must resolve to a public address")
 )
+
+// serviceError - ошибка, которую можно показать клиенту; Unwrap возвращает ее категорию
+type serviceError struct {
+	msg  string
+	kind error
+}
+
+func newError(kind error, msg string) error {
+	return &serviceError{msg: msg, kind: kind}
+}
+
+func (e *serviceError) Error() string {
+	return e.msg
+}
+
+func (e *serviceError) Unwrap() error {
+	return e.kind
+}
+
+// ClientMessage - текст ошибки сервиса без префиксов оберток (op, txmanager) для ответа клиенту
+func ClientMessage(err error) string {
+	var se *serviceError
+	if errors.As(err, &se) {
+		return se.msg
+	}
+	return err.Error()
+}
@@ -2,32 +2,63 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 	"test_news/internal/model"
 	"test_news/internal/repo"
 	"test_news/internal/repo/txmanager"
+	"test_news/pkg/sitemap"
+	"time"
 )
 
+// изменения новостей идут в SERIALIZABLE: конфликтующие транзакции откатываются и повторяются
+// txmanager'ом, а не пишут аудит и события по устаревшему состоянию
+var publishTxOptions = txmanager.WithIsolation(pgx.Serializable)
+
 type newsService struct {
 	tx         txmanager.Manager
 	news       repo.News
 	categories repo.Categories
+	audit      repo.Audit
+	events     repo.NewsEvents
+	webhooks   repo.Webhooks
+	outbox     repo.Outbox
+	hub        *newsHub
 }
 
-func newNewsService(tx txmanager.Manager, news repo.News, categories repo.Categories) *newsService {
+func newNewsService(
+	tx txmanager.Manager,
+	news repo.News,
+	categories repo.Categories,
+	audit repo.Audit,
+	events repo.NewsEvents,
+	webhooks repo.Webhooks,
+	outbox repo.Outbox,
+) *newsService {
 	return &newsService{
 		tx:         tx,
 		news:       news,
 		categories: categories,
+		audit:      audit,
+		events:     events,
+		webhooks:   webhooks,
+		outbox:     outbox,
+		hub:        newNewsHub(),
 	}
 }
 
-func (s *newsService) Create(ctx context.Context, news model.News) (int64, error) {
+func (s *newsService) Create(ctx context.Context, news model.News) (_ int64, err error) {
 	const op = "service.news.Create"
 
+	ctx, end := startSpan(ctx, op)
+	defer func() { end(err) }()
+
 	var result int64
-	err := s.tx.TxFunc(ctx, func(tx txmanager.TX) error {
+	err = s.tx.TxFunc(ctx, func(ctx context.Context, tx txmanager.TX) error {
 		id, err := s.news.Create(tx, news)
 		if err != nil {
 			return fmt.Errorf("%s create user error: %w", op, err)
@@ -41,12 +72,20 @@ func (s *newsService) Create(ctx context.Context, news model.News) (int64, error
 				return fmt.Errorf("%s create categories error: %w", op, err)
 			}
 		}
+		news.Id = id
+		if err = writeAudit(ctx, s.audit, tx, AuditEntityNews, id, AuditActionCreate, nil, news); err != nil {
+			return fmt.Errorf("%s write audit error: %w", op, err)
+		}
+		if err = s.writeEvents(tx, NewsEventCreated, news, news.Categories); err != nil {
+			return fmt.Errorf("%s write events error: %w", op, err)
+		}
 		result = id
 		return nil
-	})
+	}, publishTxOptions)
 	if err != nil {
 		return 0, err
 	}
+	s.notify(ctx, NewsEventCreated, result, news.Categories)
 	return result, nil
 }
 
@@ -55,43 +94,326 @@ type NewsUpdate struct {
 	Title      *string
 	Content    *string
 	Categories []int64
+	// пустой Categories по умолчанию означает "не менять", с этим флагом - очистить
+	ReplaceCategories bool
 }
 
-func (s *newsService) Update(ctx context.Context, input NewsUpdate) error {
+func (s *newsService) Update(ctx context.Context, input NewsUpdate) (err error) {
 	const op = "service.news.Update"
 
-	err := s.tx.TxFunc(ctx, func(tx txmanager.TX) error {
-		err := s.news.Update(tx, input.Id, input.Title, input.Content)
+	ctx, end := startSpan(ctx, op)
+	defer func() { end(err) }()
+
+	// категории до и после изменения: событие получают подписчики и старых, и новых категорий
+	var affected []int64
+	err = s.tx.TxFunc(ctx, func(ctx context.Context, tx txmanager.TX) error {
+		// функция может выполняться повторно при serialization failure
+		affected = affected[:0]
+		// блокируем строку, чтобы состояние "до" в аудите не устарело к моменту обновления
+		if err := s.news.Lock(tx, input.Id); err != nil {
+			if errors.Is(err, repo.ErrNotFound) {
+				return ErrNewsNotFound
+			}
+			return fmt.Errorf("%s lock news error: %w", op, err)
+		}
+		before, err := s.news.FindById(tx, input.Id)
+		if err != nil {
+			return fmt.Errorf("%s find news error: %w", op, err)
+		}
+
+		err = s.news.Update(tx, input.Id, input.Title, input.Content)
 		if err != nil {
 			if errors.Is(err, repo.ErrNotFound) {
 				return ErrNewsNotFound
 			}
 			return fmt.Errorf("%s update news error: %w", op, err)
 		}
+		after := before
+		if input.Title != nil {
+			after.Title = *input.Title
+		}
+		if input.Content != nil {
+			after.Content = *input.Content
+		}
+		if input.Title != nil || input.Content != nil {
+			if err = writeAudit(ctx, s.audit, tx, AuditEntityNews, input.Id, AuditActionUpdate, before, after); err != nil {
+				return fmt.Errorf("%s write audit error: %w", op, err)
+			}
+		}
 
-		if len(input.Categories) != 0 {
+		if input.ReplaceCategories || len(input.Categories) != 0 {
 			if err = s.categories.Delete(tx, input.Id); err != nil {
 				return fmt.Errorf("%s delete categories error: %w", op, err)
 			}
 
-			if err = s.categories.Create(tx, input.Id, input.Categories); err != nil {
-				if errors.Is(err, repo.ErrAlreadyExists) {
-					return ErrCategoriesAlreadyExists
+			if len(input.Categories) != 0 {
+				if err = s.categories.Create(tx, input.Id, input.Categories); err != nil {
+					if errors.Is(err, repo.ErrAlreadyExists) {
+						return ErrCategoriesAlreadyExists
+					}
+					return fmt.Errorf("%s create categories error: %w", op, err)
 				}
-				return fmt.Errorf("%s create categories error: %w", op, err)
 			}
+			err = writeAudit(ctx, s.audit, tx, AuditEntityCategories, input.Id, AuditActionUpdate, before.Categories, input.Categories)
+			if err != nil {
+				return fmt.Errorf("%s write audit error: %w", op, err)
+			}
+			after.Categories = input.Categories
+			affected = append(affected, input.Categories...)
+		}
+		affected = append(affected, before.Categories...)
+		if err = s.writeEvents(tx, NewsEventUpdated, after, affected); err != nil {
+			return fmt.Errorf("%s write events error: %w", op, err)
 		}
 		return nil
+	}, publishTxOptions)
+	if err != nil {
+		return err
+	}
+	s.notify(ctx, NewsEventUpdated, input.Id, affected)
+	return nil
+}
+
+func (s *newsService) Get(ctx context.Context, id int64) (_ model.News, err error) {
+	const op = "service.news.Get"
+
+	ctx, end := startSpan(ctx, op)
+	defer func() { end(err) }()
+
+	news, err := s.news.FindById(s.tx.DB(ctx), id)
+	if err != nil {
+		if errors.Is(err, repo.ErrNotFound) {
+			return model.News{}, ErrNewsNotFound
+		}
+		return model.News{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return news, nil
+}
+
+func (s *newsService) Delete(ctx context.Context, id int64) (err error) {
+	const op = "service.news.Delete"
+
+	ctx, end := startSpan(ctx, op)
+	defer func() { end(err) }()
+
+	var affected []int64
+	err = s.tx.TxFunc(ctx, func(ctx context.Context, tx txmanager.TX) error {
+		if err := s.news.Lock(tx, id); err != nil {
+			if errors.Is(err, repo.ErrNotFound) {
+				return ErrNewsNotFound
+			}
+			return fmt.Errorf("%s lock news error: %w", op, err)
+		}
+		before, err := s.news.FindById(tx, id)
+		if err != nil {
+			return fmt.Errorf("%s find news error: %w", op, err)
+		}
+
+		if err = s.categories.Delete(tx, id); err != nil {
+			return fmt.Errorf("%s delete categories error: %w", op, err)
+		}
+		if err = s.news.Delete(tx, id); err != nil {
+			return fmt.Errorf("%s delete news error: %w", op, err)
+		}
+		if err = writeAudit(ctx, s.audit, tx, AuditEntityNews, id, AuditActionDelete, before, nil); err != nil {
+			return fmt.Errorf("%s write audit error: %w", op, err)
+		}
+		if err = s.writeEvents(tx, NewsEventDeleted, before, before.Categories); err != nil {
+			return fmt.Errorf("%s write events error: %w", op, err)
+		}
+		affected = before.Categories
+		return nil
+	}, publishTxOptions)
+	if err != nil {
+		return err
+	}
+	s.notify(ctx, NewsEventDeleted, id, affected)
+	return nil
+}
+
+// notify вызывается после коммита: изменение уже сохранено, поэтому ошибка уведомления только логируется
+func (s *newsService) notify(ctx context.Context, eventType string, newsId int64, categories []int64) {
+	if err := s.events.Notify(s.tx.Primary(ctx), eventType, newsId, uniqueIds(categories)); err != nil {
+		zerolog.Ctx(ctx).Err(err).Str("type", eventType).Int64("news_id", newsId).Msg("service.news notify error")
+	}
+}
+
+// writeEvents пишет событие в outbox и доставки webhook'ов в той же транзакции, что и изменение:
+// они уходят только после коммита и не теряются
+func (s *newsService) writeEvents(tx txmanager.TX, event string, news model.News, categories []int64) error {
+	payload, err := json.Marshal(news)
+	if err != nil {
+		return err
+	}
+	err = s.outbox.Add(tx, model.OutboxEvent{
+		Aggregate:   OutboxAggregateNews,
+		AggregateId: news.Id,
+		Type:        event,
+		Payload:     payload,
 	})
+	if err != nil {
+		return fmt.Errorf("add outbox event error: %w", err)
+	}
+
+	payload, err = json.Marshal(WebhookPayload{Event: event, News: news, OccurredAt: time.Now().UTC()})
 	if err != nil {
 		return err
 	}
+	if err = s.webhooks.Enqueue(tx, event, uniqueIds(categories), payload); err != nil {
+		return fmt.Errorf("enqueue webhooks error: %w", err)
+	}
 	return nil
 }
 
-func (s *newsService) FindWithCategories(ctx context.Context, limit, offset int) ([]model.News, error) {
+// Watch подписывает на изменения новостей до отмены ctx; закрытый канал без отмены ctx
+// означает, что подписчик не успевал читать события.
+// lastEventId > 0 - продолжение потока: сначала придут сохраненные события после него
+func (s *newsService) Watch(ctx context.Context, lastEventId int64) <-chan NewsEvent {
+	return s.hub.subscribe(ctx, lastEventId)
+}
+
+// Listen получает события всех реплик через postgres и рассылает их подписчикам Watch.
+// При обрыве соединения переподключается; после отмены ctx подписчики отключаются
+func (s *newsService) Listen(ctx context.Context) error {
+	defer s.hub.closeAll()
+
+	for {
+		err := s.events.Listen(ctx, func(n repo.NewsNotification) {
+			s.hub.publish(s.loadEvent(ctx, n))
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Err(err).Msg("service.news listen error, reconnecting")
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+// loadEvent дополняет уведомление состоянием новости: текущим, а для удаления - состоянием до удаления
+// из outbox (в payload NOTIFY новость целиком не помещается). Если загрузить не удалось, передается только id
+func (s *newsService) loadEvent(ctx context.Context, n repo.NewsNotification) NewsEvent {
+	event := NewsEvent{Id: n.Id, Type: n.Type, News: model.News{Id: n.NewsId}, Categories: n.Categories}
+
+	// уведомление пришло с primary, реплика может еще не знать об изменении
+	if n.Type == NewsEventDeleted {
+		e, err := s.outbox.FindLast(s.tx.Primary(ctx), OutboxAggregateNews, n.NewsId, NewsEventDeleted)
+		if err != nil {
+			log.Err(err).Int64("news_id", n.NewsId).Msg("service.news load deleted event error")
+			return event
+		}
+		var before model.News
+		if err = json.Unmarshal(e.Payload, &before); err != nil {
+			log.Err(err).Int64("news_id", n.NewsId).Msg("service.news load deleted event error")
+			return event
+		}
+		event.News = before
+		return event
+	}
+
+	news, err := s.news.FindById(s.tx.Primary(ctx), n.NewsId)
+	if err != nil {
+		if !errors.Is(err, repo.ErrNotFound) {
+			log.Err(err).Int64("news_id", n.NewsId).Msg("service.news load event error")
+		}
+		return event
+	}
+	event.News = news
+	return event
+}
+
+func (s *newsService) List(ctx context.Context, limit, offset int) (_ []model.News, err error) {
+	const op = "service.news.List"
+
+	ctx, end := startSpan(ctx, op)
+	defer func() { end(err) }()
+
+	news, err := s.news.Find(s.tx.DB(ctx), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return news, nil
+}
+
+func (s *newsService) FindCategories(ctx context.Context, newsIds []int64) (_ map[int64][]int64, err error) {
+	const op = "service.news.FindCategories"
+
+	ctx, end := startSpan(ctx, op)
+	defer func() { end(err) }()
+
+	categories, err := s.categories.FindByNewsIds(s.tx.DB(ctx), newsIds)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return categories, nil
+}
+
+func (s *newsService) FindLatest(ctx context.Context, categoryId int64, limit int) (_ []model.News, err error) {
+	const op = "service.news.FindLatest"
+
+	ctx, end := startSpan(ctx, op)
+	defer func() { end(err) }()
+
+	news, err := s.news.FindLatest(s.tx.DB(ctx), categoryId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return news, nil
+}
+
+const (
+	sitemapChunkSize = sitemap.MaxURLs
+	listenRetryDelay = time.Second
+)
+
+func (s *newsService) SitemapIndex(ctx context.Context) (_ []time.Time, err error) {
+	const op = "service.news.SitemapIndex"
+
+	ctx, end := startSpan(ctx, op)
+	defer func() { end(err) }()
+
+	chunks, err := s.news.SitemapChunks(s.tx.DB(ctx), sitemapChunkSize)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return chunks, nil
+}
+
+func (s *newsService) StreamSitemap(ctx context.Context, chunk int, fn func(id int64, updatedAt time.Time) error) (err error) {
+	const op = "service.news.StreamSitemap"
+
+	ctx, end := startSpan(ctx, op)
+	defer func() { end(err) }()
+
+	if chunk < 0 {
+		return ErrSitemapNotFound
+	}
+
+	var n int
+	err = s.news.StreamSitemap(s.tx.DB(ctx), sitemapChunkSize, chunk*sitemapChunkSize, func(id int64, updatedAt time.Time) error {
+		n++
+		return fn(id, updatedAt)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	// пустая первая часть - нормально для пустой БД, остальные части без новостей не существуют
+	if n == 0 && chunk > 0 {
+		return ErrSitemapNotFound
+	}
+	return nil
+}
+
+func (s *newsService) FindWithCategories(ctx context.Context, limit, offset int) (_ []model.News, err error) {
 	const op = "service.news.FindWithCategories"
 
+	ctx, end := startSpan(ctx, op)
+	defer func() { end(err) }()
+
 	news, err := s.news.FindWithCategories(s.tx.DB(ctx), limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"test_news/internal/model"
+	"test_news/internal/repo/txmanager"
+	"test_news/pkg/cache"
+	"time"
+)
+
+type CacheConfig struct {
+	// Size - максимум записей в каждом кэше; 0 - кэш выключен
+	Size int
+	TTL  time.Duration
+}
+
+const (
+	newsListFindWithCategories = "with_categories"
+	newsListList               = "list"
+	newsListLatest             = "latest"
+)
+
+type newsListKey struct {
+	method     string
+	categoryId int64
+	limit      int
+	offset     int
+}
+
+// cachedNewsService кэширует чтение новостей и сбрасывает затронутые записи после изменений через этот сервис.
+// Изменения на других репликах сюда не доходят, поэтому там данные могут устареть не дольше чем на TTL.
+// Возвращаемые срезы общие для всех вызовов и не должны изменяться
+type cachedNewsService struct {
+	News
+	news  *cache.Loader[int64, model.News]
+	lists *cache.Loader[newsListKey, []model.News]
+}
+
+func newCachedNewsService(news News, cfg CacheConfig) *cachedNewsService {
+	return &cachedNewsService{
+		News:  news,
+		news:  cache.NewLoader(cache.NewLRU[int64, model.News](cfg.Size, cfg.TTL)),
+		lists: cache.NewLoader(cache.NewLRU[newsListKey, []model.News](cfg.Size, cfg.TTL)),
+	}
+}
+
+// при ошибке (например, коммита) неизвестно, применилось ли изменение, поэтому кэш сбрасывается в любом случае.
+// Внутри внешней транзакции сброс откладывается до ее завершения: до коммита другие запросы видят старые данные
+
+func (s *cachedNewsService) Create(ctx context.Context, news model.News) (int64, error) {
+	id, err := s.News.Create(ctx, news)
+	// новая новость сдвигает все страницы
+	txmanager.AfterTx(ctx, s.lists.Purge)
+	return id, err
+}
+
+func (s *cachedNewsService) Update(ctx context.Context, input NewsUpdate) error {
+	err := s.News.Update(ctx, input)
+	txmanager.AfterTx(ctx, func() {
+		s.news.Delete(input.Id)
+		if input.ReplaceCategories || len(input.Categories) != 0 {
+			// новость может появиться в списках категорий, где ее раньше не было
+			s.lists.Purge()
+		} else {
+			s.lists.DeleteFunc(func(_ newsListKey, news []model.News) bool {
+				return slices.ContainsFunc(news, func(n model.News) bool { return n.Id == input.Id })
+			})
+		}
+	})
+	return err
+}
+
+func (s *cachedNewsService) Delete(ctx context.Context, id int64) error {
+	err := s.News.Delete(ctx, id)
+	txmanager.AfterTx(ctx, func() {
+		s.news.Delete(id)
+		s.lists.Purge()
+	})
+	return err
+}
+
+// loadCtx - ctx загрузки промаха. Загрузка не отменяется вместе с ctx первого запроса: ее результат ждут
+// и другие запросы. Читается primary: данные с отстающей реплики остались бы в кэше до TTL, хотя запись
+// уже сброшена изменением
+func loadCtx(ctx context.Context) context.Context {
+	return txmanager.WithPrimary(context.WithoutCancel(ctx))
+}
+
+// внутри транзакции чтение идет мимо кэша: транзакция видит свои незакоммиченные изменения
+
+func (s *cachedNewsService) Get(ctx context.Context, id int64) (model.News, error) {
+	if txmanager.InTx(ctx) {
+		return s.News.Get(ctx, id)
+	}
+	return s.news.Get(id, func() (model.News, error) {
+		return s.News.Get(loadCtx(ctx), id)
+	})
+}
+
+func (s *cachedNewsService) FindWithCategories(ctx context.Context, limit, offset int) ([]model.News, error) {
+	if txmanager.InTx(ctx) {
+		return s.News.FindWithCategories(ctx, limit, offset)
+	}
+	key := newsListKey{method: newsListFindWithCategories, limit: limit, offset: offset}
+	return s.lists.Get(key, func() ([]model.News, error) {
+		return s.News.FindWithCategories(loadCtx(ctx), limit, offset)
+	})
+}
+
+func (s *cachedNewsService) List(ctx context.Context, limit, offset int) ([]model.News, error) {
+	if txmanager.InTx(ctx) {
+		return s.News.List(ctx, limit, offset)
+	}
+	key := newsListKey{method: newsListList, limit: limit, offset: offset}
+	return s.lists.Get(key, func() ([]model.News, error) {
+		return s.News.List(loadCtx(ctx), limit, offset)
+	})
+}
+
+func (s *cachedNewsService) FindLatest(ctx context.Context, categoryId int64, limit int) ([]model.News, error) {
+	if txmanager.InTx(ctx) {
+		return s.News.FindLatest(ctx, categoryId, limit)
+	}
+	key := newsListKey{method: newsListLatest, categoryId: categoryId, limit: limit}
+	return s.lists.Get(key, func() ([]model.News, error) {
+		return s.News.FindLatest(loadCtx(ctx), categoryId, limit)
+	})
+}
+
+func (s *cachedNewsService) Stats() cache.Stats {
+	return s.news.Stats().Add(s.lists.Stats())
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"test_news/internal/model"
+	"testing"
+	"time"
+)
+
+// stubNews считает обращения к источнику; остальные методы News не используются
+type stubNews struct {
+	News
+	news  map[int64]model.News
+	calls int
+}
+
+func (s *stubNews) Get(_ context.Context, id int64) (model.News, error) {
+	s.calls++
+	news, ok := s.news[id]
+	if !ok {
+		return model.News{}, ErrNewsNotFound
+	}
+	return news, nil
+}
+
+func (s *stubNews) FindWithCategories(_ context.Context, limit, offset int) ([]model.News, error) {
+	s.calls++
+	var news []model.News
+	for id := int64(offset + 1); id <= int64(offset+limit); id++ {
+		if n, ok := s.news[id]; ok {
+			news = append(news, n)
+		}
+	}
+	return news, nil
+}
+
+func (s *stubNews) Create(_ context.Context, news model.News) (int64, error) {
+	news.Id = int64(len(s.news) + 1)
+	s.news[news.Id] = news
+	return news.Id, nil
+}
+
+func (s *stubNews) Update(_ context.Context, input NewsUpdate) error {
+	news, ok := s.news[input.Id]
+	if !ok {
+		return ErrNewsNotFound
+	}
+	news.Title = *input.Title
+	s.news[input.Id] = news
+	return nil
+}
+
+func TestCachedNewsService_Get(t *testing.T) {
+	n := &stubNews{news: map[int64]model.News{1: {Id: 1, Title: "old"}}}
+	s := newCachedNewsService(n, CacheConfig{Size: 10, TTL: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		news, err := s.Get(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "old", news.Title)
+
+		// ошибки не кэшируются
+		_, err = s.Get(context.Background(), 2)
+		assert.ErrorIs(t, err, ErrNewsNotFound)
+	}
+	assert.Equal(t, 3, n.calls)
+
+	// после изменения новость загружается заново
+	title := "new"
+	assert.NoError(t, s.Update(context.Background(), NewsUpdate{Id: 1, Title: &title}))
+	news, err := s.Get(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", news.Title)
+	assert.Equal(t, 4, n.calls)
+
+	assert.Equal(t, uint64(1), s.Stats().Hits)
+}
+
+func TestCachedNewsService_lists(t *testing.T) {
+	n := &stubNews{news: map[int64]model.News{1: {Id: 1}, 2: {Id: 2}, 3: {Id: 3}}}
+	s := newCachedNewsService(n, CacheConfig{Size: 10, TTL: time.Minute})
+
+	load := func() {
+		news, err := s.FindWithCategories(context.Background(), 2, 0)
+		assert.NoError(t, err)
+		assert.Len(t, news, 2)
+		news, err = s.FindWithCategories(context.Background(), 2, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, n.news[3], news[0])
+	}
+	load()
+	load()
+	assert.Equal(t, 2, n.calls)
+
+	// изменение текста сбрасывает только страницы с этой новостью
+	title := "new"
+	assert.NoError(t, s.Update(context.Background(), NewsUpdate{Id: 3, Title: &title}))
+	load()
+	assert.Equal(t, 3, n.calls)
+
+	// новая новость сдвигает все страницы
+	_, err := s.Create(context.Background(), model.News{Title: "created"})
+	assert.NoError(t, err)
+	load()
+	assert.Equal(t, 5, n.calls)
+}
@@ -5,12 +5,18 @@ import (
 	"errors"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 	"test_news/internal/mocks/repomocks"
 	"test_news/internal/mocks/txmocks"
 	"test_news/internal/model"
 	"test_news/internal/repo"
 	"test_news/internal/repo/txmanager"
 	"testing"
+	"time"
 )
 
 var errUnexpectedError = errors.New("some error")
@@ -24,6 +30,7 @@ func TestNewsService_Create(t *testing.T) {
 	type mockBehaviour func(
 		n *repomocks.MockNews,
 		c *repomocks.MockCategories,
+		au *repomocks.MockAudit,
 		mgr *txmocks.MockManager,
 		tx *txmocks.MockTX,
 		a args,
@@ -46,14 +53,21 @@ func TestNewsService_Create(t *testing.T) {
 					Categories: nil,
 				},
 			},
-			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
-				mgr.EXPECT().TxFunc(a.ctx, gomock.Any()).DoAndReturn(mockTX(tx))
+			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
 
 				n.EXPECT().Create(tx, model.News{
 					Title:   a.input.Title,
 					Content: a.input.Content,
 				}).Return(int64(1), nil)
-				tx.EXPECT().Commit(a.ctx).Return(nil)
+				au.EXPECT().Create(tx, model.AuditEntry{
+					Entity:   AuditEntityNews,
+					EntityId: 1,
+					Action:   AuditActionCreate,
+					Actor:    anonymousActor,
+					After:    []byte(`{"Id":1,"Title":"FOOBAR","Content":"CONTENT","Categories":null}`),
+				}).Return(nil)
+				tx.EXPECT().Commit(gomock.Any()).Return(nil)
 			},
 			expectOutput: 1,
 			expectErr:    nil,
@@ -68,15 +82,16 @@ func TestNewsService_Create(t *testing.T) {
 					Categories: []int64{1, 2, 3, 4},
 				},
 			},
-			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
-				mgr.EXPECT().TxFunc(a.ctx, gomock.Any()).DoAndReturn(mockTX(tx))
+			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
 				n.EXPECT().Create(tx, model.News{
 					Title:      a.input.Title,
 					Content:    a.input.Content,
 					Categories: a.input.Categories,
 				}).Return(int64(1), nil)
 				c.EXPECT().Create(tx, int64(1), a.input.Categories).Return(nil)
-				tx.EXPECT().Commit(a.ctx).Return(nil)
+				au.EXPECT().Create(tx, gomock.Any()).Return(nil)
+				tx.EXPECT().Commit(gomock.Any()).Return(nil)
 			},
 			expectOutput: 1,
 			expectErr:    nil,
@@ -91,14 +106,14 @@ func TestNewsService_Create(t *testing.T) {
 					Categories: []int64{1, 2, 3, 4},
 				},
 			},
-			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
-				mgr.EXPECT().TxFunc(a.ctx, gomock.Any()).DoAndReturn(mockTX(tx))
+			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
 				n.EXPECT().Create(tx, model.News{
 					Title:      a.input.Title,
 					Content:    a.input.Content,
 					Categories: a.input.Categories,
 				}).Return(int64(0), errUnexpectedError)
-				tx.EXPECT().Rollback(a.ctx).Return(nil)
+				tx.EXPECT().Rollback(gomock.Any()).Return(nil)
 			},
 			expectOutput: 0,
 			expectErr:    errUnexpectedError,
@@ -113,15 +128,15 @@ func TestNewsService_Create(t *testing.T) {
 					Categories: []int64{1, 2, 2},
 				},
 			},
-			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
-				mgr.EXPECT().TxFunc(a.ctx, gomock.Any()).DoAndReturn(mockTX(tx))
+			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
 				n.EXPECT().Create(tx, model.News{
 					Title:      a.input.Title,
 					Content:    a.input.Content,
 					Categories: a.input.Categories,
 				}).Return(int64(1), nil)
 				c.EXPECT().Create(tx, int64(1), a.input.Categories).Return(repo.ErrAlreadyExists)
-				tx.EXPECT().Rollback(a.ctx).Return(nil)
+				tx.EXPECT().Rollback(gomock.Any()).Return(nil)
 			},
 			expectOutput: 0,
 			expectErr:    ErrCategoriesAlreadyExists,
@@ -136,16 +151,17 @@ func TestNewsService_Create(t *testing.T) {
 					Categories: []int64{1, 2, 3, 4},
 				},
 			},
-			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
-				mgr.EXPECT().TxFunc(a.ctx, gomock.Any()).DoAndReturn(mockTX(tx))
+			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
 				n.EXPECT().Create(tx, model.News{
 					Title:      a.input.Title,
 					Content:    a.input.Content,
 					Categories: a.input.Categories,
 				}).Return(int64(1), nil)
 				c.EXPECT().Create(tx, int64(1), a.input.Categories).Return(nil)
-				tx.EXPECT().Commit(a.ctx).Return(errUnexpectedError)
-				tx.EXPECT().Rollback(a.ctx).Return(nil)
+				au.EXPECT().Create(tx, gomock.Any()).Return(nil)
+				tx.EXPECT().Commit(gomock.Any()).Return(errUnexpectedError)
+				tx.EXPECT().Rollback(gomock.Any()).Return(nil)
 			},
 			expectOutput: 0,
 			expectErr:    errUnexpectedError,
@@ -158,12 +174,28 @@ func TestNewsService_Create(t *testing.T) {
 
 			n := repomocks.NewMockNews(ctrl)
 			c := repomocks.NewMockCategories(ctrl)
+			au := repomocks.NewMockAudit(ctrl)
 			mgr := txmocks.NewMockManager(ctrl)
 			tx := txmocks.NewMockTX(ctrl)
 
-			tc.mockBehaviour(n, c, mgr, tx, tc.args)
+			ev := repomocks.NewMockNewsEvents(ctrl)
+			wh := repomocks.NewMockWebhooks(ctrl)
+			ob := repomocks.NewMockOutbox(ctrl)
+			exec := txmocks.NewMockExecutor(ctrl)
+
+			tc.mockBehaviour(n, c, au, mgr, tx, tc.args)
+			// события пишутся в транзакции до коммита, поэтому при ошибке коммита они тоже вызываются
+			add := ob.EXPECT().Add(tx, gomock.Any()).Return(nil)
+			enqueue := wh.EXPECT().Enqueue(tx, NewsEventCreated, gomock.Any(), gomock.Any()).Return(nil)
+			if tc.expectErr == nil {
+				mgr.EXPECT().Primary(gomock.Any()).Return(exec)
+				ev.EXPECT().Notify(exec, NewsEventCreated, tc.expectOutput, gomock.Any()).Return(nil)
+			} else {
+				add.MaxTimes(1)
+				enqueue.MaxTimes(1)
+			}
 
-			s := newNewsService(mgr, n, c)
+			s := newNewsService(mgr, n, c, au, ev, wh, ob)
 
 			id, err := s.Create(tc.args.ctx, tc.args.input)
 
@@ -182,6 +214,7 @@ func TestNewsService_Update(t *testing.T) {
 	type mockBehaviour func(
 		n *repomocks.MockNews,
 		c *repomocks.MockCategories,
+		au *repomocks.MockAudit,
 		mgr *txmocks.MockManager,
 		tx *txmocks.MockTX,
 		a args,
@@ -192,6 +225,7 @@ func TestNewsService_Update(t *testing.T) {
 		args          args
 		mockBehaviour mockBehaviour
 		expectErr     error
+		expectNotify  []int64
 	}{
 		{
 			testName: "correct test",
@@ -204,13 +238,29 @@ func TestNewsService_Update(t *testing.T) {
 					Categories: nil,
 				},
 			},
-			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
-				mgr.EXPECT().TxFunc(a.ctx, gomock.Any()).DoAndReturn(mockTX(tx))
+			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
 
+				n.EXPECT().Lock(tx, a.input.Id).Return(nil)
+				n.EXPECT().FindById(tx, a.input.Id).Return(model.News{
+					Id:         1,
+					Title:      "Title",
+					Content:    "Content",
+					Categories: []int64{1},
+				}, nil)
 				n.EXPECT().Update(tx, a.input.Id, a.input.Title, a.input.Content).Return(nil)
-				tx.EXPECT().Commit(a.ctx).Return(nil)
+				au.EXPECT().Create(tx, model.AuditEntry{
+					Entity:   AuditEntityNews,
+					EntityId: 1,
+					Action:   AuditActionUpdate,
+					Actor:    anonymousActor,
+					Before:   []byte(`{"Id":1,"Title":"Title","Content":"Content","Categories":[1]}`),
+					After:    []byte(`{"Id":1,"Title":"Foobar","Content":"New content","Categories":[1]}`),
+				}).Return(nil)
+				tx.EXPECT().Commit(gomock.Any()).Return(nil)
 			},
-			expectErr: nil,
+			expectErr:    nil,
+			expectNotify: []int64{1},
 		},
 		{
 			testName: "correct test with categories",
@@ -223,15 +273,66 @@ func TestNewsService_Update(t *testing.T) {
 					Categories: []int64{1, 2, 3},
 				},
 			},
-			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
-				mgr.EXPECT().TxFunc(a.ctx, gomock.Any()).DoAndReturn(mockTX(tx))
+			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
 
+				n.EXPECT().Lock(tx, a.input.Id).Return(nil)
+				n.EXPECT().FindById(tx, a.input.Id).Return(model.News{
+					Id:         1,
+					Title:      "Title",
+					Content:    "Content",
+					Categories: []int64{1},
+				}, nil)
 				n.EXPECT().Update(tx, a.input.Id, a.input.Title, a.input.Content).Return(nil)
+				au.EXPECT().Create(tx, gomock.Any()).Return(nil)
 				c.EXPECT().Delete(tx, a.input.Id).Return(nil)
 				c.EXPECT().Create(tx, a.input.Id, a.input.Categories).Return(nil)
-				tx.EXPECT().Commit(a.ctx).Return(nil)
+				au.EXPECT().Create(tx, model.AuditEntry{
+					Entity:   AuditEntityCategories,
+					EntityId: 1,
+					Action:   AuditActionUpdate,
+					Actor:    anonymousActor,
+					Before:   []byte(`[1]`),
+					After:    []byte(`[1,2,3]`),
+				}).Return(nil)
+				tx.EXPECT().Commit(gomock.Any()).Return(nil)
 			},
-			expectErr: nil,
+			expectErr:    nil,
+			expectNotify: []int64{1, 2, 3},
+		},
+		{
+			testName: "clear categories",
+			args: args{
+				ctx: context.Background(),
+				input: NewsUpdate{
+					Id:                1,
+					ReplaceCategories: true,
+				},
+			},
+			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
+
+				n.EXPECT().Lock(tx, a.input.Id).Return(nil)
+				n.EXPECT().FindById(tx, a.input.Id).Return(model.News{
+					Id:         1,
+					Title:      "Title",
+					Content:    "Content",
+					Categories: []int64{1, 2},
+				}, nil)
+				n.EXPECT().Update(tx, a.input.Id, nil, nil).Return(nil)
+				c.EXPECT().Delete(tx, a.input.Id).Return(nil)
+				au.EXPECT().Create(tx, model.AuditEntry{
+					Entity:   AuditEntityCategories,
+					EntityId: 1,
+					Action:   AuditActionUpdate,
+					Actor:    anonymousActor,
+					Before:   []byte(`[1,2]`),
+					After:    []byte(`null`),
+				}).Return(nil)
+				tx.EXPECT().Commit(gomock.Any()).Return(nil)
+			},
+			expectErr:    nil,
+			expectNotify: []int64{1, 2},
 		},
 		{
 			testName: "news not found",
@@ -244,11 +345,11 @@ func TestNewsService_Update(t *testing.T) {
 					Categories: nil,
 				},
 			},
-			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
-				mgr.EXPECT().TxFunc(a.ctx, gomock.Any()).DoAndReturn(mockTX(tx))
+			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX, a args) {
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
 
-				n.EXPECT().Update(tx, a.input.Id, a.input.Title, a.input.Content).Return(repo.ErrNotFound)
-				tx.EXPECT().Rollback(a.ctx).Return(nil)
+				n.EXPECT().Lock(tx, a.input.Id).Return(repo.ErrNotFound)
+				tx.EXPECT().Rollback(gomock.Any()).Return(nil)
 			},
 			expectErr: ErrNewsNotFound,
 		},
@@ -260,12 +361,24 @@ func TestNewsService_Update(t *testing.T) {
 
 			n := repomocks.NewMockNews(ctrl)
 			c := repomocks.NewMockCategories(ctrl)
+			au := repomocks.NewMockAudit(ctrl)
 			mgr := txmocks.NewMockManager(ctrl)
 			tx := txmocks.NewMockTX(ctrl)
 
-			tc.mockBehaviour(n, c, mgr, tx, tc.args)
+			ev := repomocks.NewMockNewsEvents(ctrl)
+			wh := repomocks.NewMockWebhooks(ctrl)
+			ob := repomocks.NewMockOutbox(ctrl)
+			exec := txmocks.NewMockExecutor(ctrl)
 
-			s := newNewsService(mgr, n, c)
+			tc.mockBehaviour(n, c, au, mgr, tx, tc.args)
+			if tc.expectErr == nil {
+				ob.EXPECT().Add(tx, gomock.Any()).Return(nil)
+				wh.EXPECT().Enqueue(tx, NewsEventUpdated, tc.expectNotify, gomock.Any()).Return(nil)
+				mgr.EXPECT().Primary(gomock.Any()).Return(exec)
+				ev.EXPECT().Notify(exec, NewsEventUpdated, tc.args.input.Id, tc.expectNotify).Return(nil)
+			}
+
+			s := newNewsService(mgr, n, c, au, ev, wh, ob)
 
 			err := s.Update(tc.args.ctx, tc.args.input)
 
@@ -305,7 +418,7 @@ func TestNewsService_FindWithCategories(t *testing.T) {
 				offset: 0,
 			},
 			mockBehaviour: func(n *repomocks.MockNews, mgr *txmocks.MockManager, exec *txmocks.MockExecutor, a args) {
-				mgr.EXPECT().DB(a.ctx).Return(exec)
+				mgr.EXPECT().DB(gomock.Any()).Return(exec)
 				n.EXPECT().FindWithCategories(exec, a.limit, a.offset).Return([]model.News{
 					{
 						Id:         1,
@@ -345,7 +458,7 @@ func TestNewsService_FindWithCategories(t *testing.T) {
 				offset: 0,
 			},
 			mockBehaviour: func(n *repomocks.MockNews, mgr *txmocks.MockManager, exec *txmocks.MockExecutor, a args) {
-				mgr.EXPECT().DB(a.ctx).Return(exec)
+				mgr.EXPECT().DB(gomock.Any()).Return(exec)
 				n.EXPECT().FindWithCategories(exec, a.limit, a.offset).Return(nil, errUnexpectedError)
 			},
 			expectOutput: nil,
@@ -363,7 +476,7 @@ func TestNewsService_FindWithCategories(t *testing.T) {
 
 			tc.mockBehaviour(n, mgr, exec, tc.args)
 
-			s := newNewsService(mgr, n, nil)
+			s := newNewsService(mgr, n, nil, nil, nil, nil, nil)
 
 			actual, err := s.FindWithCategories(tc.args.ctx, tc.args.limit, tc.args.offset)
 
@@ -376,8 +489,434 @@ func TestNewsService_FindWithCategories(t *testing.T) {
 	}
 }
 
-func mockTX(tx *txmocks.MockTX) func(ctx context.Context, f func(tx txmanager.TX) error) (err error) {
-	return func(ctx context.Context, f func(tx txmanager.TX) error) (err error) {
+func TestNewsService_Get(t *testing.T) {
+	testCases := []struct {
+		testName      string
+		id            int64
+		mockBehaviour func(n *repomocks.MockNews, mgr *txmocks.MockManager, exec *txmocks.MockExecutor)
+		expectOutput  model.News
+		expectErr     error
+	}{
+		{
+			testName: "correct test",
+			id:       1,
+			mockBehaviour: func(n *repomocks.MockNews, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {
+				mgr.EXPECT().DB(gomock.Any()).Return(exec)
+				n.EXPECT().FindById(exec, int64(1)).Return(model.News{
+					Id:         1,
+					Title:      "Title",
+					Content:    "Content",
+					Categories: []int64{1},
+				}, nil)
+			},
+			expectOutput: model.News{
+				Id:         1,
+				Title:      "Title",
+				Content:    "Content",
+				Categories: []int64{1},
+			},
+			expectErr: nil,
+		},
+		{
+			testName: "news not found",
+			id:       2,
+			mockBehaviour: func(n *repomocks.MockNews, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {
+				mgr.EXPECT().DB(gomock.Any()).Return(exec)
+				n.EXPECT().FindById(exec, int64(2)).Return(model.News{}, repo.ErrNotFound)
+			},
+			expectOutput: model.News{},
+			expectErr:    ErrNewsNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			n := repomocks.NewMockNews(ctrl)
+			mgr := txmocks.NewMockManager(ctrl)
+			exec := txmocks.NewMockExecutor(ctrl)
+
+			tc.mockBehaviour(n, mgr, exec)
+
+			s := newNewsService(mgr, n, nil, nil, nil, nil, nil)
+
+			actual, err := s.Get(context.Background(), tc.id)
+
+			assert.ErrorIs(t, err, tc.expectErr)
+			assert.Equal(t, tc.expectOutput, actual)
+		})
+	}
+}
+
+func TestNewsService_Get_span(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	ctrl := gomock.NewController(t)
+	n := repomocks.NewMockNews(ctrl)
+	mgr := txmocks.NewMockManager(ctrl)
+	exec := txmocks.NewMockExecutor(ctrl)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "GET /api/v2/news/:id")
+	// ctx запроса к репозиторию несет span сервиса, а не span http
+	mgr.EXPECT().DB(gomock.Any()).DoAndReturn(func(ctx context.Context) txmanager.Executor {
+		assert.NotEqual(t, parent.SpanContext().SpanID(), trace.SpanContextFromContext(ctx).SpanID())
+		return exec
+	}).Times(2)
+	n.EXPECT().FindById(exec, int64(1)).Return(model.News{}, repo.ErrNotFound)
+	n.EXPECT().FindById(exec, int64(2)).Return(model.News{}, errUnexpectedError)
+
+	s := newNewsService(mgr, n, nil, nil, nil, nil, nil)
+	_, err := s.Get(ctx, 1)
+	assert.ErrorIs(t, err, ErrNewsNotFound)
+	_, err = s.Get(ctx, 2)
+	assert.ErrorIs(t, err, errUnexpectedError)
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	for _, span := range spans[:2] {
+		assert.Equal(t, "service.news.Get", span.Name)
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID())
+	}
+	// не найдено - ответ клиенту, а не сбой
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+}
+
+func TestNewsService_Delete(t *testing.T) {
+	type mockBehaviour func(
+		n *repomocks.MockNews,
+		c *repomocks.MockCategories,
+		au *repomocks.MockAudit,
+		mgr *txmocks.MockManager,
+		tx *txmocks.MockTX,
+	)
+
+	testCases := []struct {
+		testName      string
+		id            int64
+		mockBehaviour mockBehaviour
+		expectErr     error
+	}{
+		{
+			testName: "correct test",
+			id:       1,
+			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX) {
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
+
+				n.EXPECT().Lock(tx, int64(1)).Return(nil)
+				n.EXPECT().FindById(tx, int64(1)).Return(model.News{
+					Id:         1,
+					Title:      "Title",
+					Content:    "Content",
+					Categories: []int64{1},
+				}, nil)
+				c.EXPECT().Delete(tx, int64(1)).Return(nil)
+				n.EXPECT().Delete(tx, int64(1)).Return(nil)
+				au.EXPECT().Create(tx, model.AuditEntry{
+					Entity:   AuditEntityNews,
+					EntityId: 1,
+					Action:   AuditActionDelete,
+					Actor:    anonymousActor,
+					Before:   []byte(`{"Id":1,"Title":"Title","Content":"Content","Categories":[1]}`),
+				}).Return(nil)
+				tx.EXPECT().Commit(gomock.Any()).Return(nil)
+			},
+			expectErr: nil,
+		},
+		{
+			testName: "news not found",
+			id:       2,
+			mockBehaviour: func(n *repomocks.MockNews, c *repomocks.MockCategories, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX) {
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
+
+				n.EXPECT().Lock(tx, int64(2)).Return(repo.ErrNotFound)
+				tx.EXPECT().Rollback(gomock.Any()).Return(nil)
+			},
+			expectErr: ErrNewsNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			n := repomocks.NewMockNews(ctrl)
+			c := repomocks.NewMockCategories(ctrl)
+			au := repomocks.NewMockAudit(ctrl)
+			mgr := txmocks.NewMockManager(ctrl)
+			tx := txmocks.NewMockTX(ctrl)
+
+			ev := repomocks.NewMockNewsEvents(ctrl)
+			wh := repomocks.NewMockWebhooks(ctrl)
+			ob := repomocks.NewMockOutbox(ctrl)
+			exec := txmocks.NewMockExecutor(ctrl)
+
+			tc.mockBehaviour(n, c, au, mgr, tx)
+			if tc.expectErr == nil {
+				ob.EXPECT().Add(tx, gomock.Any()).DoAndReturn(func(_ repo.Querier, e model.OutboxEvent) error {
+					assert.Equal(t, OutboxAggregateNews, e.Aggregate)
+					assert.Equal(t, tc.id, e.AggregateId)
+					assert.Equal(t, NewsEventDeleted, e.Type)
+					return nil
+				})
+				wh.EXPECT().Enqueue(tx, NewsEventDeleted, gomock.Any(), gomock.Any()).Return(nil)
+				mgr.EXPECT().Primary(gomock.Any()).Return(exec)
+				ev.EXPECT().Notify(exec, NewsEventDeleted, tc.id, gomock.Any()).Return(nil)
+			}
+
+			s := newNewsService(mgr, n, c, au, ev, wh, ob)
+
+			err := s.Delete(context.Background(), tc.id)
+
+			assert.ErrorIs(t, err, tc.expectErr)
+		})
+	}
+}
+
+func TestNewsService_FindCategories(t *testing.T) {
+	testCases := []struct {
+		testName      string
+		newsIds       []int64
+		mockBehaviour func(c *repomocks.MockCategories, mgr *txmocks.MockManager, exec *txmocks.MockExecutor)
+		expectOutput  map[int64][]int64
+		expectErr     error
+	}{
+		{
+			testName: "correct test",
+			newsIds:  []int64{1, 2},
+			mockBehaviour: func(c *repomocks.MockCategories, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {
+				mgr.EXPECT().DB(gomock.Any()).Return(exec)
+				c.EXPECT().FindByNewsIds(exec, []int64{1, 2}).Return(map[int64][]int64{1: {1, 2}}, nil)
+			},
+			expectOutput: map[int64][]int64{1: {1, 2}},
+			expectErr:    nil,
+		},
+		{
+			testName: "unexpected error",
+			newsIds:  []int64{1},
+			mockBehaviour: func(c *repomocks.MockCategories, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {
+				mgr.EXPECT().DB(gomock.Any()).Return(exec)
+				c.EXPECT().FindByNewsIds(exec, []int64{1}).Return(nil, errUnexpectedError)
+			},
+			expectOutput: nil,
+			expectErr:    errUnexpectedError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			c := repomocks.NewMockCategories(ctrl)
+			mgr := txmocks.NewMockManager(ctrl)
+			exec := txmocks.NewMockExecutor(ctrl)
+
+			tc.mockBehaviour(c, mgr, exec)
+
+			s := newNewsService(mgr, nil, c, nil, nil, nil, nil)
+
+			actual, err := s.FindCategories(context.Background(), tc.newsIds)
+
+			assert.ErrorIs(t, err, tc.expectErr)
+			assert.Equal(t, tc.expectOutput, actual)
+		})
+	}
+}
+
+func TestNewsService_FindLatest(t *testing.T) {
+	testCases := []struct {
+		testName      string
+		categoryId    int64
+		mockBehaviour func(n *repomocks.MockNews, mgr *txmocks.MockManager, exec *txmocks.MockExecutor)
+		expectOutput  []model.News
+		expectErr     error
+	}{
+		{
+			testName:   "correct test",
+			categoryId: 2,
+			mockBehaviour: func(n *repomocks.MockNews, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {
+				mgr.EXPECT().DB(gomock.Any()).Return(exec)
+				n.EXPECT().FindLatest(exec, int64(2), 10).Return([]model.News{{Id: 2}, {Id: 1}}, nil)
+			},
+			expectOutput: []model.News{{Id: 2}, {Id: 1}},
+			expectErr:    nil,
+		},
+		{
+			testName:   "unexpected error",
+			categoryId: 0,
+			mockBehaviour: func(n *repomocks.MockNews, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {
+				mgr.EXPECT().DB(gomock.Any()).Return(exec)
+				n.EXPECT().FindLatest(exec, int64(0), 10).Return(nil, errUnexpectedError)
+			},
+			expectOutput: nil,
+			expectErr:    errUnexpectedError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			n := repomocks.NewMockNews(ctrl)
+			mgr := txmocks.NewMockManager(ctrl)
+			exec := txmocks.NewMockExecutor(ctrl)
+
+			tc.mockBehaviour(n, mgr, exec)
+
+			s := newNewsService(mgr, n, nil, nil, nil, nil, nil)
+
+			actual, err := s.FindLatest(context.Background(), tc.categoryId, 10)
+
+			assert.ErrorIs(t, err, tc.expectErr)
+			assert.Equal(t, tc.expectOutput, actual)
+		})
+	}
+}
+
+func TestNewsService_StreamSitemap(t *testing.T) {
+	updatedAt := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		testName      string
+		chunk         int
+		mockBehaviour func(n *repomocks.MockNews, mgr *txmocks.MockManager, exec *txmocks.MockExecutor)
+		expectIds     []int64
+		expectErr     error
+	}{
+		{
+			testName: "correct test",
+			chunk:    1,
+			mockBehaviour: func(n *repomocks.MockNews, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {
+				mgr.EXPECT().DB(gomock.Any()).Return(exec)
+				n.EXPECT().StreamSitemap(exec, sitemapChunkSize, sitemapChunkSize, gomock.Any()).DoAndReturn(
+					func(_ repo.Querier, _, _ int, fn func(int64, time.Time) error) error {
+						for _, id := range []int64{50001, 50002} {
+							if err := fn(id, updatedAt); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+			},
+			expectIds: []int64{50001, 50002},
+			expectErr: nil,
+		},
+		{
+			testName: "empty first chunk",
+			chunk:    0,
+			mockBehaviour: func(n *repomocks.MockNews, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {
+				mgr.EXPECT().DB(gomock.Any()).Return(exec)
+				n.EXPECT().StreamSitemap(exec, sitemapChunkSize, 0, gomock.Any()).Return(nil)
+			},
+			expectIds: nil,
+			expectErr: nil,
+		},
+		{
+			testName: "chunk not found",
+			chunk:    2,
+			mockBehaviour: func(n *repomocks.MockNews, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {
+				mgr.EXPECT().DB(gomock.Any()).Return(exec)
+				n.EXPECT().StreamSitemap(exec, sitemapChunkSize, 2*sitemapChunkSize, gomock.Any()).Return(nil)
+			},
+			expectIds: nil,
+			expectErr: ErrSitemapNotFound,
+		},
+		{
+			testName:      "negative chunk",
+			chunk:         -1,
+			mockBehaviour: func(n *repomocks.MockNews, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {},
+			expectIds:     nil,
+			expectErr:     ErrSitemapNotFound,
+		},
+		{
+			testName: "unexpected error",
+			chunk:    0,
+			mockBehaviour: func(n *repomocks.MockNews, mgr *txmocks.MockManager, exec *txmocks.MockExecutor) {
+				mgr.EXPECT().DB(gomock.Any()).Return(exec)
+				n.EXPECT().StreamSitemap(exec, sitemapChunkSize, 0, gomock.Any()).Return(errUnexpectedError)
+			},
+			expectIds: nil,
+			expectErr: errUnexpectedError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			n := repomocks.NewMockNews(ctrl)
+			mgr := txmocks.NewMockManager(ctrl)
+			exec := txmocks.NewMockExecutor(ctrl)
+
+			tc.mockBehaviour(n, mgr, exec)
+
+			s := newNewsService(mgr, n, nil, nil, nil, nil, nil)
+
+			var ids []int64
+			err := s.StreamSitemap(context.Background(), tc.chunk, func(id int64, _ time.Time) error {
+				ids = append(ids, id)
+				return nil
+			})
+
+			assert.ErrorIs(t, err, tc.expectErr)
+			assert.Equal(t, tc.expectIds, ids)
+		})
+	}
+}
+
+func TestNewsService_Listen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	n := repomocks.NewMockNews(ctrl)
+	ev := repomocks.NewMockNewsEvents(ctrl)
+	o := repomocks.NewMockOutbox(ctrl)
+	mgr := txmocks.NewMockManager(ctrl)
+	exec := txmocks.NewMockExecutor(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	news := model.News{Id: 1, Title: "hello world", Categories: []int64{1}}
+	deleted := model.News{Id: 3, Title: "old title", Content: "old content", Categories: []int64{1, 2}}
+
+	mgr.EXPECT().Primary(gomock.Any()).Return(exec).Times(3)
+	n.EXPECT().FindById(exec, int64(1)).Return(news, nil)
+	n.EXPECT().FindById(exec, int64(2)).Return(model.News{}, repo.ErrNotFound)
+	// состояние до удаления - из outbox
+	o.EXPECT().FindLast(exec, OutboxAggregateNews, int64(3), NewsEventDeleted).Return(model.OutboxEvent{
+		Payload: []byte(`{"Id":3,"Title":"old title","Content":"old content","Categories":[1,2]}`),
+	}, nil)
+	ev.EXPECT().Listen(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(repo.NewsNotification)) error {
+		fn(repo.NewsNotification{Id: 10, Type: NewsEventCreated, NewsId: 1})
+		fn(repo.NewsNotification{Id: 11, Type: NewsEventUpdated, NewsId: 2}) // удалена до загрузки
+		fn(repo.NewsNotification{Id: 12, Type: NewsEventDeleted, NewsId: 3, Categories: []int64{1, 2}})
+		cancel()
+		return ctx.Err()
+	})
+
+	s := newNewsService(mgr, n, nil, nil, ev, nil, o)
+	events := s.Watch(context.Background(), 0)
+
+	assert.NoError(t, s.Listen(ctx))
+
+	assert.Equal(t, NewsEvent{Id: 10, Type: NewsEventCreated, News: news}, <-events)
+	assert.Equal(t, NewsEvent{Id: 11, Type: NewsEventUpdated, News: model.News{Id: 2}}, <-events)
+	assert.Equal(t, NewsEvent{Id: 12, Type: NewsEventDeleted, News: deleted, Categories: []int64{1, 2}}, <-events)
+
+	_, ok := <-events
+	assert.False(t, ok) // после остановки Listen подписчики отключены
+}
+
+func mockTX(tx *txmocks.MockTX) func(ctx context.Context, f func(ctx context.Context, tx txmanager.TX) error, _ ...txmanager.Option) (err error) {
+	return func(ctx context.Context, f func(ctx context.Context, tx txmanager.TX) error, _ ...txmanager.Option) (err error) {
 		if f == nil {
 			return errors.New("nil tx func")
 		}
@@ -389,7 +928,7 @@ func mockTX(tx *txmocks.MockTX) func(ctx context.Context, f func(tx txmanager.TX
 				err = e
 			}
 		}()
-		if err = f(tx); err != nil {
+		if err = f(ctx, tx); err != nil {
 			return
 		}
 		if err = tx.Commit(ctx); err != nil {
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"test_news/internal/model"
+	"test_news/internal/repo"
+	"test_news/internal/repo/txmanager"
+	"test_news/pkg/events"
+	"time"
+)
+
+const (
+	OutboxAggregateNews = "news"
+
+	outboxCleanupInterval = time.Hour
+	// outboxLease - на сколько событие откладывается при взятии в работу; если relay упал, его возьмет другой
+	outboxLease = time.Minute
+)
+
+type OutboxConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	// Retention - сколько хранить опубликованные события; 0 - не удалять
+	Retention time.Duration
+}
+
+type outboxService struct {
+	tx        txmanager.Manager
+	outbox    repo.Outbox
+	publisher events.Publisher
+	cfg       OutboxConfig
+}
+
+func newOutboxService(tx txmanager.Manager, outbox repo.Outbox, publisher events.Publisher, cfg OutboxConfig) *outboxService {
+	return &outboxService{
+		tx:        tx,
+		outbox:    outbox,
+		publisher: publisher,
+		cfg:       cfg,
+	}
+}
+
+// Run публикует события из outbox до отмены ctx. Relay может работать на нескольких репликах одновременно:
+// события разбираются через SKIP LOCKED и откладываются на lease
+func (s *outboxService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	var lastCleanup time.Time
+	for {
+		claimed, published, err := s.relayBatch(ctx)
+		if err != nil {
+			log.Err(err).Msg("service.outbox relay error")
+		}
+		if s.cfg.Retention > 0 && time.Since(lastCleanup) >= outboxCleanupInterval {
+			s.cleanup(ctx)
+			lastCleanup = time.Now()
+		}
+		// полная пачка, в которой что-то опубликовано, - вероятно, есть еще готовые события;
+		// если не опубликовано ничего (брокер недоступен), ждем тика
+		if err == nil && claimed == s.cfg.BatchSize && published > 0 {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// relayBatch публикует события вне транзакции и затем отмечает результат. Если отметка не прошла,
+// события будут опубликованы повторно после lease (at-least-once)
+func (s *outboxService) relayBatch(ctx context.Context) (int, int, error) {
+	const op = "service.outbox.relayBatch"
+
+	batch, err := s.outbox.Claim(s.tx.Primary(ctx), s.cfg.BatchSize, outboxLease)
+	if err != nil {
+		return 0, 0, fmt.Errorf("%s claim error: %w", op, err)
+	}
+	if len(batch) == 0 {
+		return 0, 0, nil
+	}
+
+	var (
+		publishedIds []int64
+		failed       = make(map[int64]error)
+	)
+	for _, e := range batch {
+		if err = s.publisher.Publish(ctx, outboxEvent(e)); err != nil {
+			// остальные события агрегата ждут, пока это не будет опубликовано или не уйдет в dead letter
+			log.Err(err).Int64("event_id", e.Id).Int("attempts", e.Attempts+1).Msg("service.outbox publish error")
+			failed[e.Id] = err
+			continue
+		}
+		publishedIds = append(publishedIds, e.Id)
+	}
+
+	err = s.tx.TxFunc(ctx, func(ctx context.Context, tx txmanager.TX) error {
+		if len(publishedIds) != 0 {
+			if err := s.outbox.MarkPublished(tx, publishedIds); err != nil {
+				return fmt.Errorf("%s mark published error: %w", op, err)
+			}
+		}
+		for _, e := range batch {
+			publishErr, ok := failed[e.Id]
+			if !ok {
+				continue
+			}
+			if err := s.markFailed(tx, e, publishErr); err != nil {
+				return fmt.Errorf("%s mark failed error: %w", op, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return len(batch), 0, err
+	}
+	return len(batch), len(publishedIds), nil
+}
+
+// markFailed откладывает событие с экспоненциальной задержкой, после MaxAttempts неудач - в dead letter
+func (s *outboxService) markFailed(tx txmanager.TX, e model.OutboxEvent, publishErr error) error {
+	attempts := e.Attempts + 1
+	if attempts >= s.cfg.MaxAttempts {
+		log.Error().Int64("event_id", e.Id).Int("attempts", attempts).Msg("service.outbox event moved to dead letter")
+		return s.outbox.MarkDead(tx, e.Id, publishErr.Error())
+	}
+	return s.outbox.MarkFailed(tx, e.Id, publishErr.Error(), time.Now().Add(retryBackoff(s.cfg.BackoffBase, s.cfg.BackoffMax, attempts)))
+}
+
+func (s *outboxService) cleanup(ctx context.Context) {
+	deleted, err := s.outbox.DeletePublished(s.tx.Primary(ctx), time.Now().Add(-s.cfg.Retention))
+	if err != nil {
+		log.Err(err).Msg("service.outbox cleanup error")
+		return
+	}
+	if deleted != 0 {
+		log.Debug().Int64("deleted", deleted).Msg("service.outbox cleanup")
+	}
+}
+
+func outboxEvent(e model.OutboxEvent) events.Event {
+	return events.Event{
+		Id:          e.Id,
+		Aggregate:   e.Aggregate,
+		AggregateId: e.AggregateId,
+		Type:        e.Type,
+		Payload:     e.Payload,
+		CreatedAt:   e.CreatedAt,
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"slices"
+	"test_news/internal/mocks/repomocks"
+	"test_news/internal/mocks/txmocks"
+	"test_news/internal/model"
+	"test_news/internal/repo"
+	"test_news/pkg/events"
+	"testing"
+	"time"
+)
+
+type failingPublisher struct {
+	events.Publisher
+	failIds []int64
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, event events.Event) error {
+	if slices.Contains(p.failIds, event.Id) {
+		return errUnexpectedError
+	}
+	return p.Publisher.Publish(ctx, event)
+}
+
+func TestOutboxService_relayBatch(t *testing.T) {
+	claimed := []model.OutboxEvent{
+		{Id: 1, Aggregate: OutboxAggregateNews, AggregateId: 10, Type: NewsEventCreated, Attempts: 2},
+		{Id: 3, Aggregate: OutboxAggregateNews, AggregateId: 11, Type: NewsEventUpdated},
+	}
+
+	testCases := []struct {
+		testName        string
+		failIds         []int64
+		maxAttempts     int
+		mockBehaviour   func(o *repomocks.MockOutbox, mgr *txmocks.MockManager, exec *txmocks.MockExecutor, tx *txmocks.MockTX)
+		expectIds       []int64
+		expectClaimed   int
+		expectPublished int
+		expectErr       error
+	}{
+		{
+			testName:    "correct test",
+			maxAttempts: 10,
+			mockBehaviour: func(o *repomocks.MockOutbox, mgr *txmocks.MockManager, exec *txmocks.MockExecutor, tx *txmocks.MockTX) {
+				mgr.EXPECT().Primary(gomock.Any()).Return(exec)
+				o.EXPECT().Claim(exec, 10, outboxLease).Return(claimed, nil)
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
+				o.EXPECT().MarkPublished(tx, []int64{1, 3}).Return(nil)
+				tx.EXPECT().Commit(gomock.Any()).Return(nil)
+			},
+			expectIds:       []int64{1, 3},
+			expectClaimed:   2,
+			expectPublished: 2,
+		},
+		{
+			testName:    "publish error with backoff",
+			failIds:     []int64{1},
+			maxAttempts: 10,
+			mockBehaviour: func(o *repomocks.MockOutbox, mgr *txmocks.MockManager, exec *txmocks.MockExecutor, tx *txmocks.MockTX) {
+				mgr.EXPECT().Primary(gomock.Any()).Return(exec)
+				o.EXPECT().Claim(exec, 10, outboxLease).Return(claimed, nil)
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
+				o.EXPECT().MarkPublished(tx, []int64{3}).Return(nil)
+				start := time.Now()
+				o.EXPECT().MarkFailed(tx, int64(1), errUnexpectedError.Error(), gomock.Any()).
+					DoAndReturn(func(_ repo.Querier, _ int64, _ string, next time.Time) error {
+						// третья попытка: base * 4
+						assert.WithinDuration(t, start.Add(4*time.Second), next, time.Second)
+						return nil
+					})
+				tx.EXPECT().Commit(gomock.Any()).Return(nil)
+			},
+			expectIds:       []int64{3},
+			expectClaimed:   2,
+			expectPublished: 1,
+		},
+		{
+			testName:    "dead after max attempts",
+			failIds:     []int64{1, 3},
+			maxAttempts: 3,
+			mockBehaviour: func(o *repomocks.MockOutbox, mgr *txmocks.MockManager, exec *txmocks.MockExecutor, tx *txmocks.MockTX) {
+				mgr.EXPECT().Primary(gomock.Any()).Return(exec)
+				o.EXPECT().Claim(exec, 10, outboxLease).Return(claimed, nil)
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
+				o.EXPECT().MarkDead(tx, int64(1), errUnexpectedError.Error()).Return(nil)
+				o.EXPECT().MarkFailed(tx, int64(3), errUnexpectedError.Error(), gomock.Any()).Return(nil)
+				tx.EXPECT().Commit(gomock.Any()).Return(nil)
+			},
+			expectClaimed:   2,
+			expectPublished: 0,
+		},
+		{
+			testName: "empty",
+			mockBehaviour: func(o *repomocks.MockOutbox, mgr *txmocks.MockManager, exec *txmocks.MockExecutor, tx *txmocks.MockTX) {
+				mgr.EXPECT().Primary(gomock.Any()).Return(exec)
+				o.EXPECT().Claim(exec, 10, outboxLease).Return(nil, nil)
+			},
+		},
+		{
+			testName: "claim error",
+			mockBehaviour: func(o *repomocks.MockOutbox, mgr *txmocks.MockManager, exec *txmocks.MockExecutor, tx *txmocks.MockTX) {
+				mgr.EXPECT().Primary(gomock.Any()).Return(exec)
+				o.EXPECT().Claim(exec, 10, outboxLease).Return(nil, errUnexpectedError)
+			},
+			expectErr: errUnexpectedError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			o := repomocks.NewMockOutbox(ctrl)
+			mgr := txmocks.NewMockManager(ctrl)
+			exec := txmocks.NewMockExecutor(ctrl)
+			tx := txmocks.NewMockTX(ctrl)
+
+			tc.mockBehaviour(o, mgr, exec, tx)
+
+			memory := events.NewMemoryPublisher()
+			s := newOutboxService(mgr, o, &failingPublisher{Publisher: memory, failIds: tc.failIds}, OutboxConfig{
+				BatchSize:   10,
+				MaxAttempts: tc.maxAttempts,
+				BackoffBase: time.Second,
+				BackoffMax:  time.Minute,
+			})
+
+			claimed, published, err := s.relayBatch(context.Background())
+
+			assert.ErrorIs(t, err, tc.expectErr)
+			assert.Equal(t, tc.expectClaimed, claimed)
+			assert.Equal(t, tc.expectPublished, published)
+
+			var ids []int64
+			for _, e := range memory.Events() {
+				ids = append(ids, e.Id)
+			}
+			assert.Equal(t, tc.expectIds, ids)
+		})
+	}
+}
+
+func TestOutboxService_Run_noProgress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	o := repomocks.NewMockOutbox(ctrl)
+	mgr := txmocks.NewMockManager(ctrl)
+	exec := txmocks.NewMockExecutor(ctrl)
+	tx := txmocks.NewMockTX(ctrl)
+
+	// полная пачка без единой публикации: следующий Claim только после тика
+	mgr.EXPECT().Primary(gomock.Any()).Return(exec)
+	o.EXPECT().Claim(exec, 1, outboxLease).Return([]model.OutboxEvent{{Id: 1}}, nil)
+	mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
+	o.EXPECT().MarkFailed(tx, int64(1), errUnexpectedError.Error(), gomock.Any()).Return(nil)
+	tx.EXPECT().Commit(gomock.Any()).Return(nil)
+
+	s := newOutboxService(mgr, o, &failingPublisher{Publisher: events.NewMemoryPublisher(), failIds: []int64{1}}, OutboxConfig{
+		PollInterval: time.Hour,
+		BatchSize:    1,
+		MaxAttempts:  10,
+		BackoffBase:  time.Second,
+		BackoffMax:   time.Minute,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, s.Run(ctx))
+}
@@ -5,35 +5,107 @@ import (
 	"test_news/internal/model"
 	"test_news/internal/repo"
 	"test_news/internal/repo/txmanager"
+	"test_news/pkg/cache"
+	"test_news/pkg/events"
+	"time"
 )
 
 type News interface {
 	Create(ctx context.Context, news model.News) (int64, error)
 	Update(ctx context.Context, input NewsUpdate) error
+	Get(ctx context.Context, id int64) (model.News, error)
+	Delete(ctx context.Context, id int64) error
+	Watch(ctx context.Context, lastEventId int64) <-chan NewsEvent
+	Listen(ctx context.Context) error
 	FindWithCategories(ctx context.Context, limit, offset int) ([]model.News, error)
+	// List и FindCategories загружают новости и категории раздельно, для пакетной загрузки (graphql)
+	List(ctx context.Context, limit, offset int) ([]model.News, error)
+	FindCategories(ctx context.Context, newsIds []int64) (map[int64][]int64, error)
+	// FindLatest - последние новости, сначала новые; categoryId == 0 - все категории
+	FindLatest(ctx context.Context, categoryId int64, limit int) ([]model.News, error)
+	// SitemapIndex - время последнего изменения каждой части sitemap (по sitemap.MaxURLs новостей)
+	SitemapIndex(ctx context.Context) ([]time.Time, error)
+	// StreamSitemap передает в fn новости части chunk по порядку id
+	StreamSitemap(ctx context.Context, chunk int, fn func(id int64, updatedAt time.Time) error) error
 }
 
 type Auth interface {
 	Create() (string, error)
-	Validate(tokenString string) bool
+	Validate(tokenString string) (string, bool)
+}
+
+type APIKeys interface {
+	Create(ctx context.Context, input APIKeyCreate) (string, model.APIKey, error)
+	List(ctx context.Context) ([]model.APIKey, error)
+	Revoke(ctx context.Context, id int64) error
+	Validate(ctx context.Context, key string) (Principal, error)
+}
+
+type Audit interface {
+	Find(ctx context.Context, query AuditQuery) ([]model.AuditEntry, error)
+}
+
+type Webhooks interface {
+	Create(ctx context.Context, input WebhookCreate) (model.Webhook, error)
+	List(ctx context.Context) ([]model.Webhook, error)
+	Delete(ctx context.Context, id int64) error
+	// Attempts - журнал попыток доставки подписки, сначала новые
+	Attempts(ctx context.Context, webhookId int64, limit int) ([]model.WebhookAttempt, error)
+	// Run доставляет накопленные webhook'и до отмены ctx
+	Run(ctx context.Context) error
+}
+
+type Outbox interface {
+	// Run публикует события из outbox до отмены ctx
+	Run(ctx context.Context) error
+}
+
+type Cache interface {
+	Stats() cache.Stats
 }
 
 type (
 	Services struct {
-		Auth Auth
-		News News
+		Auth     Auth
+		News     News
+		APIKeys  APIKeys
+		Audit    Audit
+		Webhooks Webhooks
+		Outbox   Outbox
+		// Cache - счетчики кэша новостей; nil, если кэш выключен
+		Cache Cache
 	}
 	ServicesDependencies struct {
 		NewsRepo       repo.News
 		CategoriesRepo repo.Categories
+		APIKeysRepo    repo.APIKeys
+		AuditRepo      repo.Audit
+		NewsEventsRepo repo.NewsEvents
+		WebhooksRepo   repo.Webhooks
+		OutboxRepo     repo.Outbox
 		TxManager      txmanager.Manager
+		Publisher      events.Publisher
 		JWTKey         string
+		OperatorKey    string
+		Webhooks       WebhookConfig
+		Outbox         OutboxConfig
+		Cache          CacheConfig
 	}
 )
 
 func NewServices(d *ServicesDependencies) *Services {
-	return &Services{
-		Auth: newAuthService(d.JWTKey),
-		News: newNewsService(d.TxManager, d.NewsRepo, d.CategoriesRepo),
+	services := &Services{
+		Auth:     newAuthService(d.JWTKey),
+		News:     newNewsService(d.TxManager, d.NewsRepo, d.CategoriesRepo, d.AuditRepo, d.NewsEventsRepo, d.WebhooksRepo, d.OutboxRepo),
+		APIKeys:  newAPIKeysService(d.TxManager, d.APIKeysRepo, d.AuditRepo, d.OperatorKey),
+		Audit:    newAuditService(d.TxManager, d.AuditRepo),
+		Webhooks: newWebhooksService(d.TxManager, d.WebhooksRepo, d.AuditRepo, d.Webhooks),
+		Outbox:   newOutboxService(d.TxManager, d.OutboxRepo, d.Publisher, d.Outbox),
+	}
+	if d.Cache.Size > 0 {
+		news := newCachedNewsService(services.News, d.Cache)
+		services.News = news
+		services.Cache = news
 	}
+	return services
 }
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const tracerName = "test_news/internal/service"
+
+// startSpan - span метода сервиса с именем op. Вызывается как
+//
+//	ctx, end := startSpan(ctx, op)
+//	defer func() { end(err) }()
+//
+// ошибки, которые отдаются клиенту как 4xx (не найдено, уже существует, неверный ввод), span ошибкой не помечают
+func startSpan(ctx context.Context, op string) (context.Context, func(err error)) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, op)
+	return ctx, func(err error) {
+		if err != nil && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrAlreadyExists) && !errors.Is(err, ErrInvalidInput) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"test_news/internal/model"
+)
+
+const (
+	NewsEventCreated = "created"
+	NewsEventUpdated = "updated"
+	NewsEventDeleted = "deleted"
+
+	watchBuffer  = 64
+	replayBuffer = 256
+)
+
+type NewsEvent struct {
+	Id   int64 // сквозной номер события, общий для всех реплик
+	Type string
+	News model.News
+	// Categories - категории новости до и после изменения, по ним фильтруются подписки
+	Categories []int64
+}
+
+// newsHub рассылает события подписчикам внутри процесса и хранит последние replayBuffer событий
+// для продолжения потока после переподключения.
+// Подписчик, не успевающий читать, отключается: канал закрывается без ожидания
+type newsHub struct {
+	mu     sync.Mutex
+	subs   map[chan NewsEvent]struct{}
+	replay []NewsEvent
+}
+
+func newNewsHub() *newsHub {
+	return &newsHub{
+		subs:   make(map[chan NewsEvent]struct{}),
+		replay: make([]NewsEvent, 0, replayBuffer),
+	}
+}
+
+// subscribe при lastEventId > 0 сначала отдает сохраненные события, пришедшие после него. Номера выдаются
+// до NOTIFY, поэтому события могут прийти не по порядку номеров (11 раньше 10); порядок прихода одинаков
+// на всех репликах, так что продолжение идет по нему. Если lastEventId уже вытеснен из буфера,
+// отдаются события с большим номером
+func (h *newsHub) subscribe(ctx context.Context, lastEventId int64) <-chan NewsEvent {
+	h.mu.Lock()
+	var missed []NewsEvent
+	if lastEventId > 0 {
+		if i := slices.IndexFunc(h.replay, func(e NewsEvent) bool { return e.Id == lastEventId }); i >= 0 {
+			missed = slices.Clone(h.replay[i+1:])
+		} else {
+			for _, event := range h.replay {
+				if event.Id > lastEventId {
+					missed = append(missed, event)
+				}
+			}
+		}
+	}
+	ch := make(chan NewsEvent, watchBuffer+len(missed))
+	for _, event := range missed {
+		ch <- event
+	}
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		h.remove(ch)
+		h.mu.Unlock()
+	}()
+	return ch
+}
+
+func (h *newsHub) publish(event NewsEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.replay) == replayBuffer {
+		copy(h.replay, h.replay[1:])
+		h.replay = h.replay[:replayBuffer-1]
+	}
+	h.replay = append(h.replay, event)
+
+	for ch := range h.subs {
+		select {
+		case ch <- event:
+		default:
+			h.remove(ch)
+		}
+	}
+}
+
+// closeAll отключает всех подписчиков, например при остановке сервиса
+func (h *newsHub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs {
+		h.remove(ch)
+	}
+}
+
+func (h *newsHub) remove(ch chan NewsEvent) {
+	if _, ok := h.subs[ch]; ok {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+func uniqueIds(ids []int64) []int64 {
+	ids = slices.Clone(ids)
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"test_news/internal/model"
+	"testing"
+	"time"
+)
+
+func TestNewsHub(t *testing.T) {
+	h := newNewsHub()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := h.subscribe(ctx, 0)
+
+	event := NewsEvent{Type: NewsEventCreated, News: model.News{Id: 1}}
+	h.publish(event)
+	assert.Equal(t, event, <-events)
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("channel is not closed after ctx cancel")
+	}
+}
+
+func TestNewsHub_slowSubscriber(t *testing.T) {
+	h := newNewsHub()
+
+	events := h.subscribe(context.Background(), 0)
+	for i := 0; i <= watchBuffer; i++ {
+		h.publish(NewsEvent{Type: NewsEventUpdated, News: model.News{Id: int64(i)}})
+	}
+
+	var received int
+	for range events {
+		received++
+	}
+	assert.Equal(t, watchBuffer, received)
+	assert.Empty(t, h.subs)
+}
+
+func TestNewsHub_replay(t *testing.T) {
+	h := newNewsHub()
+
+	for i := 1; i <= replayBuffer+10; i++ {
+		h.publish(NewsEvent{Id: int64(i), Type: NewsEventUpdated, News: model.News{Id: 1}})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// пропущенные события отдаются до новых
+	events := h.subscribe(ctx, replayBuffer+7)
+	h.publish(NewsEvent{Id: replayBuffer + 11, Type: NewsEventDeleted})
+
+	var ids []int64
+	for i := 0; i < 4; i++ {
+		ids = append(ids, (<-events).Id)
+	}
+	assert.Equal(t, []int64{replayBuffer + 8, replayBuffer + 9, replayBuffer + 10, replayBuffer + 11}, ids)
+
+	// слишком старый Last-Event-ID: отдается весь буфер
+	events = h.subscribe(ctx, 1)
+	assert.Len(t, events, replayBuffer)
+	assert.Equal(t, int64(12), (<-events).Id)
+}
+
+func TestNewsHub_replayArrivalOrder(t *testing.T) {
+	h := newNewsHub()
+
+	// номер 3 получен раньше, а NOTIFY пришел позже 4
+	for _, id := range []int64{1, 2, 4, 3, 5} {
+		h.publish(NewsEvent{Id: id, Type: NewsEventUpdated})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := h.subscribe(ctx, 4)
+	var ids []int64
+	for len(events) > 0 {
+		ids = append(ids, (<-events).Id)
+	}
+	assert.Equal(t, []int64{3, 5}, ids)
+}
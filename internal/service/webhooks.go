@@ -0,0 +1,353 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"syscall"
+	"test_news/internal/model"
+	"test_news/internal/repo"
+	"test_news/internal/repo/txmanager"
+	"time"
+)
+
+const (
+	WebhookSecretPrefix = "whsec_"
+
+	HeaderWebhookId        = "X-Webhook-Id"
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+
+	webhookSecretBytes = 24
+	// webhookLease - на сколько доставка откладывается при взятии в работу; если воркер упал, ее возьмет другой
+	webhookLease = 5 * time.Minute
+)
+
+var WebhookEvents = []string{NewsEventCreated, NewsEventUpdated, NewsEventDeleted}
+
+var (
+	errWebhookAddressNotPublic = errors.New("webhook address is not public")
+
+	// nonPublicPrefixes - диапазоны, которые netip.Addr не относит к частным, но в интернете не маршрутизируются
+	nonPublicPrefixes = []netip.Prefix{
+		netip.MustParsePrefix("0.0.0.0/8"),
+		netip.MustParsePrefix("100.64.0.0/10"),
+		netip.MustParsePrefix("192.0.0.0/24"),
+		netip.MustParsePrefix("198.18.0.0/15"),
+		netip.MustParsePrefix("240.0.0.0/4"),
+	}
+)
+
+// WebhookPayload - тело POST запроса подписчику
+type WebhookPayload struct {
+	Event      string     `json:"Event"`
+	News       model.News `json:"News"`
+	OccurredAt time.Time  `json:"OccurredAt"`
+}
+
+type WebhookConfig struct {
+	MaxAttempts  int
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+	PollInterval time.Duration
+	Timeout      time.Duration
+	BatchSize    int
+}
+
+type webhooksService struct {
+	tx       txmanager.Manager
+	webhooks repo.Webhooks
+	audit    repo.Audit
+	client   *http.Client
+	cfg      WebhookConfig
+	lookup   func(ctx context.Context, host string) ([]netip.Addr, error)
+}
+
+func newWebhooksService(tx txmanager.Manager, webhooks repo.Webhooks, audit repo.Audit, cfg WebhookConfig) *webhooksService {
+	return &webhooksService{
+		tx:       tx,
+		webhooks: webhooks,
+		audit:    audit,
+		client:   newWebhookClient(cfg.Timeout),
+		cfg:      cfg,
+		lookup: func(ctx context.Context, host string) ([]netip.Addr, error) {
+			return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		},
+	}
+}
+
+// newWebhookClient - клиент, который соединяется только с публичными адресами. Проверка в Control идет после
+// резолва, поэтому ее не обойти DNS rebinding'ом или редиректом на внутренний адрес; прокси из окружения не используется
+func newWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !publicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", errWebhookAddressNotPublic, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// publicAddr - не loopback, не частная сеть, не link-local (в т.ч. metadata 169.254.169.254), не multicast
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkWebhookHost - все адреса host должны быть публичными, иначе подписка позволила бы слать запросы во внутреннюю сеть
+func (s *webhooksService) checkWebhookHost(ctx context.Context, host string) error {
+	addr, err := netip.ParseAddr(host)
+	addrs := []netip.Addr{addr}
+	if err != nil {
+		if addrs, err = s.lookup(ctx, host); err != nil || len(addrs) == 0 {
+			return ErrInvalidWebhookURL
+		}
+	}
+	for _, addr := range addrs {
+		if !publicAddr(addr) {
+			return ErrWebhookURLNotPublic
+		}
+	}
+	return nil
+}
+
+type WebhookCreate struct {
+	URL        string
+	Events     []string
+	Categories []int64
+}
+
+// Create возвращает подписку вместе с секретом; секрет показывается только при создании
+func (s *webhooksService) Create(ctx context.Context, input WebhookCreate) (model.Webhook, error) {
+	const op = "service.webhooks.Create"
+
+	u, err := url.Parse(input.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return model.Webhook{}, ErrInvalidWebhookURL
+	}
+	if err = s.checkWebhookHost(ctx, u.Hostname()); err != nil {
+		return model.Webhook{}, err
+	}
+	for _, event := range input.Events {
+		if !slices.Contains(WebhookEvents, event) {
+			return model.Webhook{}, ErrUnknownWebhookEvent
+		}
+	}
+
+	secret, err := randomHex(webhookSecretBytes)
+	if err != nil {
+		return model.Webhook{}, fmt.Errorf("%s generate secret error: %w", op, err)
+	}
+	webhook := model.Webhook{
+		URL:        input.URL,
+		Secret:     WebhookSecretPrefix + secret,
+		Events:     input.Events,
+		Categories: uniqueIds(input.Categories),
+	}
+	err = s.tx.TxFunc(ctx, func(ctx context.Context, tx txmanager.TX) error {
+		id, err := s.webhooks.Create(tx, webhook)
+		if err != nil {
+			return fmt.Errorf("%s create webhook error: %w", op, err)
+		}
+		webhook.Id = id
+		if err = writeAudit(ctx, s.audit, tx, AuditEntityWebhook, id, AuditActionCreate, nil, webhook); err != nil {
+			return fmt.Errorf("%s write audit error: %w", op, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+func (s *webhooksService) List(ctx context.Context) ([]model.Webhook, error) {
+	const op = "service.webhooks.List"
+
+	webhooks, err := s.webhooks.List(s.tx.DB(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return webhooks, nil
+}
+
+func (s *webhooksService) Delete(ctx context.Context, id int64) error {
+	const op = "service.webhooks.Delete"
+
+	return s.tx.TxFunc(ctx, func(ctx context.Context, tx txmanager.TX) error {
+		if err := s.webhooks.Delete(tx, id); err != nil {
+			if errors.Is(err, repo.ErrNotFound) {
+				return ErrWebhookNotFound
+			}
+			return fmt.Errorf("%s delete webhook error: %w", op, err)
+		}
+		if err := writeAudit(ctx, s.audit, tx, AuditEntityWebhook, id, AuditActionDelete, nil, nil); err != nil {
+			return fmt.Errorf("%s write audit error: %w", op, err)
+		}
+		return nil
+	})
+}
+
+func (s *webhooksService) Attempts(ctx context.Context, webhookId int64, limit int) ([]model.WebhookAttempt, error) {
+	const op = "service.webhooks.Attempts"
+
+	attempts, err := s.webhooks.FindAttempts(s.tx.DB(ctx), webhookId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return attempts, nil
+}
+
+// Run доставляет webhook'и из outbox до отмены ctx. Воркеров может быть несколько (по одному на реплику):
+// доставки разбираются через SKIP LOCKED
+func (s *webhooksService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.deliverBatch(ctx)
+		if err != nil {
+			log.Err(err).Msg("service.webhooks deliver error")
+		}
+		// полная пачка - вероятно, есть еще готовые доставки
+		if err == nil && n == s.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *webhooksService) deliverBatch(ctx context.Context) (int, error) {
+	const op = "service.webhooks.deliverBatch"
+
+	deliveries, err := s.webhooks.Claim(s.tx.Primary(ctx), s.cfg.BatchSize, webhookLease)
+	if err != nil {
+		return 0, fmt.Errorf("%s claim error: %w", op, err)
+	}
+	for _, d := range deliveries {
+		if err = s.deliver(ctx, d); err != nil {
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return len(deliveries), nil
+}
+
+// deliver делает одну попытку и записывает ее в журнал; после MaxAttempts неудач доставка становится dead
+func (s *webhooksService) deliver(ctx context.Context, d model.WebhookDelivery) error {
+	start := time.Now()
+	code, sendErr := s.send(ctx, d)
+
+	attempt := model.WebhookAttempt{
+		DeliveryId: d.Id,
+		StatusCode: code,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	attempts := d.Attempts + 1
+	status, next := repo.WebhookDeliveryDelivered, time.Now()
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+		if attempts >= s.cfg.MaxAttempts {
+			status = repo.WebhookDeliveryDead
+		} else {
+			status, next = repo.WebhookDeliveryPending, time.Now().Add(retryBackoff(s.cfg.BackoffBase, s.cfg.BackoffMax, attempts))
+		}
+	}
+
+	return s.tx.TxFunc(ctx, func(ctx context.Context, tx txmanager.TX) error {
+		if err := s.webhooks.RecordAttempt(tx, attempt); err != nil {
+			return fmt.Errorf("record attempt error: %w", err)
+		}
+		if err := s.webhooks.UpdateDelivery(tx, d.Id, status, attempts, next); err != nil {
+			return fmt.Errorf("update delivery error: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *webhooksService) send(ctx context.Context, d model.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookId, strconv.FormatInt(d.Id, 10))
+	req.Header.Set(HeaderWebhookEvent, d.Event)
+	req.Header.Set(HeaderWebhookTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderWebhookSignature, SignWebhook(d.Secret, timestamp, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhook - значение заголовка X-Webhook-Signature: HMAC-SHA256 от "<timestamp>.<body>".
+// Timestamp входит в подпись, чтобы получатель мог отбрасывать повторы старых запросов
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryBackoff - экспоненциальная задержка перед попыткой attempts+1 (webhooks, outbox): base, 2*base, 4*base... не больше max
+func retryBackoff(base, max time.Duration, attempts int) time.Duration {
+	d := base
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= max {
+			return max
+		}
+	}
+	return min(d, max)
+}
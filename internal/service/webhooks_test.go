@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"strings"
+	"test_news/internal/mocks/repomocks"
+	"test_news/internal/mocks/txmocks"
+	"test_news/internal/model"
+	"test_news/internal/repo"
+	"testing"
+	"time"
+)
+
+func TestWebhooksService_Create(t *testing.T) {
+	testCases := []struct {
+		testName      string
+		input         WebhookCreate
+		mockBehaviour func(w *repomocks.MockWebhooks, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX)
+		expectErr     error
+	}{
+		{
+			testName: "correct test",
+			input: WebhookCreate{
+				URL:        "https://example.com/hook",
+				Events:     []string{NewsEventCreated},
+				Categories: []int64{2, 1, 2},
+			},
+			mockBehaviour: func(w *repomocks.MockWebhooks, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX) {
+				mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
+				w.EXPECT().Create(tx, gomock.Any()).DoAndReturn(func(_ repo.Querier, webhook model.Webhook) (int64, error) {
+					assert.Equal(t, []int64{1, 2}, webhook.Categories)
+					return 1, nil
+				})
+				au.EXPECT().Create(tx, gomock.Any()).Return(nil)
+				tx.EXPECT().Commit(gomock.Any()).Return(nil)
+			},
+			expectErr: nil,
+		},
+		{
+			testName: "invalid url",
+			input: WebhookCreate{
+				URL:    "ftp://example.com",
+				Events: []string{NewsEventCreated},
+			},
+			mockBehaviour: func(w *repomocks.MockWebhooks, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX) {
+			},
+			expectErr: ErrInvalidWebhookURL,
+		},
+		{
+			testName: "loopback ip",
+			input: WebhookCreate{
+				URL:    "http://127.0.0.1:8080/hook",
+				Events: []string{NewsEventCreated},
+			},
+			mockBehaviour: func(w *repomocks.MockWebhooks, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX) {
+			},
+			expectErr: ErrWebhookURLNotPublic,
+		},
+		{
+			testName: "metadata ip",
+			input: WebhookCreate{
+				URL:    "http://169.254.169.254/latest/meta-data",
+				Events: []string{NewsEventCreated},
+			},
+			mockBehaviour: func(w *repomocks.MockWebhooks, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX) {
+			},
+			expectErr: ErrWebhookURLNotPublic,
+		},
+		{
+			testName: "host resolves to private ip",
+			input: WebhookCreate{
+				URL:    "https://internal.example.com/hook",
+				Events: []string{NewsEventCreated},
+			},
+			mockBehaviour: func(w *repomocks.MockWebhooks, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX) {
+			},
+			expectErr: ErrWebhookURLNotPublic,
+		},
+		{
+			testName: "unknown host",
+			input: WebhookCreate{
+				URL:    "https://unknown.example.com/hook",
+				Events: []string{NewsEventCreated},
+			},
+			mockBehaviour: func(w *repomocks.MockWebhooks, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX) {
+			},
+			expectErr: ErrInvalidWebhookURL,
+		},
+		{
+			testName: "unknown event",
+			input: WebhookCreate{
+				URL:    "https://example.com/hook",
+				Events: []string{"news.published"},
+			},
+			mockBehaviour: func(w *repomocks.MockWebhooks, au *repomocks.MockAudit, mgr *txmocks.MockManager, tx *txmocks.MockTX) {
+			},
+			expectErr: ErrUnknownWebhookEvent,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			w := repomocks.NewMockWebhooks(ctrl)
+			au := repomocks.NewMockAudit(ctrl)
+			mgr := txmocks.NewMockManager(ctrl)
+			tx := txmocks.NewMockTX(ctrl)
+
+			tc.mockBehaviour(w, au, mgr, tx)
+
+			s := newWebhooksService(mgr, w, au, WebhookConfig{})
+			s.lookup = func(_ context.Context, host string) ([]netip.Addr, error) {
+				hosts := map[string][]netip.Addr{
+					"example.com":          {netip.MustParseAddr("93.184.215.14")},
+					"internal.example.com": {netip.MustParseAddr("93.184.215.14"), netip.MustParseAddr("10.0.0.5")},
+				}
+				if addrs, ok := hosts[host]; ok {
+					return addrs, nil
+				}
+				return nil, errors.New("no such host")
+			}
+
+			webhook, err := s.Create(context.Background(), tc.input)
+
+			assert.ErrorIs(t, err, tc.expectErr)
+
+			if tc.expectErr == nil {
+				assert.Equal(t, int64(1), webhook.Id)
+				assert.True(t, strings.HasPrefix(webhook.Secret, WebhookSecretPrefix))
+			}
+		})
+	}
+}
+
+func TestWebhooksService_deliver(t *testing.T) {
+	const secret = "whsec_test"
+	payload := []byte(`{"Event":"created"}`)
+
+	testCases := []struct {
+		testName     string
+		status       int
+		attempts     int
+		expectStatus string
+		expectDelay  time.Duration
+	}{
+		{
+			testName:     "delivered",
+			status:       http.StatusNoContent,
+			expectStatus: repo.WebhookDeliveryDelivered,
+		},
+		{
+			testName:     "retry with backoff",
+			status:       http.StatusInternalServerError,
+			attempts:     2,
+			expectStatus: repo.WebhookDeliveryPending,
+			expectDelay:  4 * time.Second,
+		},
+		{
+			testName:     "dead after max attempts",
+			status:       http.StatusBadGateway,
+			attempts:     2,
+			expectStatus: repo.WebhookDeliveryDead,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			w := repomocks.NewMockWebhooks(ctrl)
+			mgr := txmocks.NewMockManager(ctrl)
+			tx := txmocks.NewMockTX(ctrl)
+
+			receiver := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				ts, err := strconv.ParseInt(r.Header.Get(HeaderWebhookTimestamp), 10, 64)
+				assert.NoError(t, err)
+				assert.Equal(t, SignWebhook(secret, ts, body), r.Header.Get(HeaderWebhookSignature))
+				assert.Equal(t, NewsEventCreated, r.Header.Get(HeaderWebhookEvent))
+				assert.Equal(t, "1", r.Header.Get(HeaderWebhookId))
+				assert.JSONEq(t, string(payload), string(body))
+				rw.WriteHeader(tc.status)
+			}))
+			defer receiver.Close()
+
+			maxAttempts := 10
+			if tc.expectStatus == repo.WebhookDeliveryDead {
+				maxAttempts = tc.attempts + 1
+			}
+			s := newWebhooksService(mgr, w, nil, WebhookConfig{
+				MaxAttempts: maxAttempts,
+				BackoffBase: time.Second,
+				BackoffMax:  time.Minute,
+				Timeout:     time.Second,
+			})
+			// httptest слушает loopback, который клиент доставки отвергает
+			s.client = receiver.Client()
+
+			mgr.EXPECT().TxFunc(gomock.Any(), gomock.Any()).DoAndReturn(mockTX(tx))
+			w.EXPECT().RecordAttempt(tx, gomock.Any()).DoAndReturn(func(_ repo.Querier, attempt model.WebhookAttempt) error {
+				assert.Equal(t, tc.status, attempt.StatusCode)
+				assert.Equal(t, tc.expectStatus == repo.WebhookDeliveryDelivered, attempt.Error == "")
+				return nil
+			})
+			start := time.Now()
+			w.EXPECT().UpdateDelivery(tx, int64(1), tc.expectStatus, tc.attempts+1, gomock.Any()).
+				DoAndReturn(func(_ repo.Querier, _ int64, _ string, _ int, next time.Time) error {
+					assert.WithinDuration(t, start.Add(tc.expectDelay), next, time.Second)
+					return nil
+				})
+			tx.EXPECT().Commit(gomock.Any()).Return(nil)
+
+			err := s.deliver(context.Background(), model.WebhookDelivery{
+				Id:        1,
+				WebhookId: int64(i),
+				Event:     NewsEventCreated,
+				Payload:   json.RawMessage(payload),
+				Attempts:  tc.attempts,
+				URL:       receiver.URL,
+				Secret:    secret,
+			})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPublicAddr(t *testing.T) {
+	testCases := []struct {
+		addr   string
+		expect bool
+	}{
+		{addr: "93.184.215.14", expect: true},
+		{addr: "2606:2800:21f:cb07:6820:80da:af6b:8b2c", expect: true},
+		{addr: "127.0.0.1", expect: false},
+		{addr: "::1", expect: false},
+		{addr: "10.1.2.3", expect: false},
+		{addr: "172.16.0.1", expect: false},
+		{addr: "192.168.1.1", expect: false},
+		{addr: "169.254.169.254", expect: false},
+		{addr: "::ffff:169.254.169.254", expect: false},
+		{addr: "fd00:ec2::254", expect: false},
+		{addr: "fe80::1", expect: false},
+		{addr: "0.0.0.0", expect: false},
+		{addr: "100.64.0.1", expect: false},
+		{addr: "224.0.0.1", expect: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.addr, func(t *testing.T) {
+			assert.Equal(t, tc.expect, publicAddr(netip.MustParseAddr(tc.addr)))
+		})
+	}
+}
+
+func TestWebhookClient_rejectsPrivateAddress(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		t.Error("request to loopback must not be sent")
+	}))
+	defer receiver.Close()
+
+	_, err := newWebhookClient(time.Second).Post(receiver.URL, "application/json", strings.NewReader("{}"))
+	assert.ErrorIs(t, err, errWebhookAddressNotPublic)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, retryBackoff(time.Second, time.Minute, 1))
+	assert.Equal(t, 8*time.Second, retryBackoff(time.Second, time.Minute, 4))
+	assert.Equal(t, time.Minute, retryBackoff(time.Second, time.Minute, 20))
+}
@@ -0,0 +1,3 @@
+-- состояние новости до удаления для событий Watch берется из outbox
+create index if not exists idx_outbox_aggregate
+    on outbox (aggregate, aggregate_id, event_type);
@@ -0,0 +1,12 @@
+create table if not exists api_keys
+(
+    id           bigserial primary key,
+    name         varchar     not null,
+    prefix       varchar     not null unique,
+    hash         varchar     not null,
+    scopes       varchar[]   not null default '{}',
+    expires_at   timestamptz,
+    last_used_at timestamptz,
+    revoked_at   timestamptz,
+    created_at   timestamptz not null default now()
+);
@@ -0,0 +1,7 @@
+create unlogged table if not exists rate_limits
+(
+    key        varchar primary key,
+    tokens     double precision not null,
+    allowed    boolean          not null,
+    updated_at timestamptz      not null
+);
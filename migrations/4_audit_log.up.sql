@@ -0,0 +1,32 @@
+create table if not exists audit_log
+(
+    id         bigserial primary key,
+    entity     varchar     not null,
+    entity_id  bigint      not null,
+    action     varchar     not null,
+    actor      varchar     not null,
+    ip         varchar     not null,
+    route      varchar     not null,
+    before     jsonb,
+    after      jsonb,
+    created_at timestamptz not null default now()
+);
+
+create index if not exists idx_audit_log_entity
+    on audit_log (entity, entity_id, created_at);
+
+create index if not exists idx_audit_log_actor
+    on audit_log (actor, created_at);
+
+create or replace function audit_log_append_only() returns trigger as
+$$
+begin
+    raise exception 'audit_log is append-only';
+end;
+$$ language plpgsql;
+
+create trigger audit_log_append_only
+    before update or delete
+    on audit_log
+    for each row
+execute function audit_log_append_only();
@@ -0,0 +1,5 @@
+drop index if exists idx_news_categories_category_id;
+
+alter table news
+    drop column if exists updated_at,
+    drop column if exists created_at;
@@ -0,0 +1,6 @@
+alter table news
+    add column if not exists created_at timestamptz not null default now(),
+    add column if not exists updated_at timestamptz not null default now();
+
+create index if not exists idx_news_categories_category_id
+    on news_categories (category_id, news_id);
@@ -0,0 +1 @@
+drop sequence if exists news_events_id_seq;
@@ -0,0 +1,2 @@
+-- id событий LISTEN/NOTIFY общие для всех реплик, по ним клиенты SSE продолжают поток (Last-Event-ID)
+create sequence if not exists news_events_id_seq;
@@ -0,0 +1,43 @@
+create table if not exists webhooks
+(
+    id         bigserial primary key,
+    url        varchar     not null,
+    secret     varchar     not null,
+    events     varchar[]   not null,
+    categories bigint[]    not null default '{}',
+    created_at timestamptz not null default now()
+);
+
+-- outbox доставок: строки пишутся в той же транзакции, что и изменение новости
+create table if not exists webhook_deliveries
+(
+    id              bigserial primary key,
+    webhook_id      bigint      not null references webhooks (id) on delete cascade,
+    event           varchar     not null,
+    payload         jsonb       not null,
+    status          varchar     not null default 'pending',
+    attempts        int         not null default 0,
+    next_attempt_at timestamptz not null default now(),
+    delivered_at    timestamptz,
+    created_at      timestamptz not null default now()
+);
+
+create index if not exists idx_webhook_deliveries_pending
+    on webhook_deliveries (next_attempt_at) where status = 'pending';
+
+create index if not exists idx_webhook_deliveries_webhook_id
+    on webhook_deliveries (webhook_id);
+
+-- журнал всех попыток доставки
+create table if not exists webhook_attempts
+(
+    id          bigserial primary key,
+    delivery_id bigint      not null references webhook_deliveries (id) on delete cascade,
+    status_code int         not null default 0,
+    error       varchar     not null default '',
+    duration_ms bigint      not null,
+    created_at  timestamptz not null default now()
+);
+
+create index if not exists idx_webhook_attempts_delivery_id
+    on webhook_attempts (delivery_id);
@@ -0,0 +1,19 @@
+-- outbox событий изменений: строки пишутся в той же транзакции, что и изменение, relay публикует их по порядку id
+create table if not exists outbox
+(
+    id           bigserial primary key,
+    aggregate    varchar     not null,
+    aggregate_id bigint      not null,
+    event_type   varchar     not null,
+    payload      jsonb       not null,
+    attempts     int         not null default 0,
+    last_error   varchar     not null default '',
+    created_at   timestamptz not null default now(),
+    published_at timestamptz
+);
+
+create index if not exists idx_outbox_unpublished
+    on outbox (aggregate, aggregate_id, id) where published_at is null;
+
+create index if not exists idx_outbox_published_at
+    on outbox (published_at) where published_at is not null;
@@ -0,0 +1,10 @@
+drop index if exists idx_outbox_dead_at;
+
+drop index if exists idx_outbox_unpublished;
+
+create index if not exists idx_outbox_unpublished
+    on outbox (aggregate, aggregate_id, id) where published_at is null;
+
+alter table outbox
+    drop column if exists dead_at,
+    drop column if exists next_attempt_at;
@@ -0,0 +1,12 @@
+-- повторы публикации с задержкой; после OUTBOX_MAX_ATTEMPTS неудач событие откладывается в dead letter (dead_at)
+alter table outbox
+    add column if not exists next_attempt_at timestamptz not null default now(),
+    add column if not exists dead_at         timestamptz;
+
+drop index if exists idx_outbox_unpublished;
+
+create index if not exists idx_outbox_unpublished
+    on outbox (aggregate, aggregate_id, id) where published_at is null and dead_at is null;
+
+create index if not exists idx_outbox_dead_at
+    on outbox (dead_at) where dead_at is not null;
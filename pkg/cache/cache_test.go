@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"container/list"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	c := NewLRU[string, int](2, 0)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	_, _ = c.Get("a") // b становится давно не использованной
+	c.Set("c", 3)
+
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	c.DeleteFunc(func(key string, value int) bool { return value == 3 })
+	_, ok = c.Get("c")
+	assert.False(t, ok)
+
+	assert.Equal(t, Stats{Hits: 2, Misses: 2, Evictions: 1, Size: 1}, c.Stats())
+
+	c.Purge()
+	assert.Equal(t, 0, c.Stats().Size)
+}
+
+func TestLRU_ttl(t *testing.T) {
+	now := time.Now()
+	c := &lru[string, int]{
+		size:  10,
+		ttl:   time.Minute,
+		items: make(map[string]*list.Element),
+		order: list.New(),
+		now:   func() time.Time { return now },
+	}
+
+	c.Set("a", 1)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Stats().Size)
+}
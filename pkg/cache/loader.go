@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"fmt"
+	"golang.org/x/sync/singleflight"
+	"sync"
+)
+
+// Loader - read-through поверх Cache: при промахе значение загружается через load, одновременные промахи
+// по одному ключу выполняют load один раз
+type Loader[K comparable, V any] struct {
+	cache Cache[K, V]
+	group singleflight.Group
+
+	mu sync.Mutex
+	// generation растет при каждой инвалидации: результат загрузки, начатой до нее, не сохраняется
+	generation uint64
+}
+
+func NewLoader[K comparable, V any](cache Cache[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{cache: cache}
+}
+
+// Get возвращает значение из кэша или загружает его; ошибки load не кэшируются
+func (l *Loader[K, V]) Get(key K, load func() (V, error)) (V, error) {
+	if v, ok := l.cache.Get(key); ok {
+		return v, nil
+	}
+
+	// после инвалидации новые промахи не присоединяются к загрузке, начатой до нее
+	generation := l.currentGeneration()
+	v, err, _ := l.group.Do(fmt.Sprint(generation, "/", key), func() (any, error) {
+		v, err := load()
+		if err != nil {
+			return v, err
+		}
+
+		l.mu.Lock()
+		if generation == l.generation {
+			l.cache.Set(key, v)
+		}
+		l.mu.Unlock()
+		return v, nil
+	})
+	result, _ := v.(V)
+	return result, err
+}
+
+func (l *Loader[K, V]) Delete(key K) {
+	l.invalidate(func() { l.cache.Delete(key) })
+}
+
+func (l *Loader[K, V]) DeleteFunc(fn func(key K, value V) bool) {
+	l.invalidate(func() { l.cache.DeleteFunc(fn) })
+}
+
+func (l *Loader[K, V]) Purge() {
+	l.invalidate(l.cache.Purge)
+}
+
+func (l *Loader[K, V]) Stats() Stats {
+	return l.cache.Stats()
+}
+
+func (l *Loader[K, V]) invalidate(fn func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.generation++
+	fn()
+}
+
+func (l *Loader[K, V]) currentGeneration() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.generation
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoader_singleflight(t *testing.T) {
+	l := NewLoader(NewLRU[int, string](10, 0))
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	load := func() (string, error) {
+		calls.Add(1)
+		<-release
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := l.Get(1, load)
+			assert.NoError(t, err)
+			assert.Equal(t, "value", v)
+		}()
+	}
+	// даем горутинам дождаться общей загрузки
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+
+	// значение уже в кэше
+	v, err := l.Get(1, func() (string, error) { return "", errors.New("unexpected load") })
+	assert.NoError(t, err)
+	assert.Equal(t, "value", v)
+}
+
+func TestLoader_invalidateDuringLoad(t *testing.T) {
+	l := NewLoader(NewLRU[int, string](10, 0))
+
+	v, err := l.Get(1, func() (string, error) {
+		// изменение закоммичено, пока шла загрузка: прочитанное значение могло устареть
+		l.Delete(1)
+		return "stale", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "stale", v)
+
+	v, err = l.Get(1, func() (string, error) { return "fresh", nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "fresh", v)
+}
+
+func TestLoader_errorNotCached(t *testing.T) {
+	l := NewLoader(NewLRU[int, string](10, 0))
+
+	errLoad := errors.New("load error")
+	_, err := l.Get(1, func() (string, error) { return "", errLoad })
+	assert.ErrorIs(t, err, errLoad)
+	assert.Equal(t, 0, l.Stats().Size)
+}
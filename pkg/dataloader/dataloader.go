@@ -0,0 +1,63 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchFunc загружает значения для всех ключей за один вызов; отсутствующий в ответе ключ получает нулевое значение
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader откладывает загрузку: Load только запоминает ключ, а первый вызов возвращенного thunk
+// загружает все накопленные ключи одним BatchFunc. Результаты кешируются на время жизни Loader,
+// поэтому он создается на один запрос
+type Loader[K comparable, V any] struct {
+	mu      sync.Mutex
+	batch   BatchFunc[K, V]
+	pending []K
+	queued  map[K]struct{}
+	values  map[K]V
+	errs    map[K]error
+}
+
+func New[K comparable, V any](batch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{
+		batch:  batch,
+		queued: make(map[K]struct{}),
+		values: make(map[K]V),
+		errs:   make(map[K]error),
+	}
+}
+
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	if _, ok := l.queued[key]; !ok {
+		l.queued[key] = struct{}{}
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if len(l.pending) != 0 {
+			l.flush(ctx)
+		}
+		return l.values[key], l.errs[key]
+	}
+}
+
+func (l *Loader[K, V]) flush(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+
+	values, err := l.batch(ctx, keys)
+	for _, key := range keys {
+		if err != nil {
+			l.errs[key] = err
+			continue
+		}
+		l.values[key] = values[key]
+	}
+}
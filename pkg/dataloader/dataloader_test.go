@@ -0,0 +1,54 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLoader(t *testing.T) {
+	var calls [][]int
+	l := New(func(ctx context.Context, keys []int) (map[int]string, error) {
+		calls = append(calls, keys)
+		return map[int]string{1: "one", 2: "two"}, nil
+	})
+
+	one := l.Load(context.Background(), 1)
+	two := l.Load(context.Background(), 2)
+	three := l.Load(context.Background(), 3)
+	again := l.Load(context.Background(), 1)
+
+	v, err := one()
+	assert.NoError(t, err)
+	assert.Equal(t, "one", v)
+
+	v, _ = two()
+	assert.Equal(t, "two", v)
+	v, _ = three()
+	assert.Equal(t, "", v)
+	v, _ = again()
+	assert.Equal(t, "one", v)
+
+	// уже загруженный ключ берется из кеша, новый грузится отдельным батчем
+	v, _ = l.Load(context.Background(), 2)()
+	assert.Equal(t, "two", v)
+	_, _ = l.Load(context.Background(), 4)()
+
+	assert.Equal(t, [][]int{{1, 2, 3}, {4}}, calls)
+}
+
+func TestLoader_error(t *testing.T) {
+	errBatch := errors.New("batch error")
+	l := New(func(ctx context.Context, keys []int) (map[int]string, error) {
+		return nil, errBatch
+	})
+
+	first := l.Load(context.Background(), 1)
+	second := l.Load(context.Background(), 2)
+
+	_, err := first()
+	assert.ErrorIs(t, err, errBatch)
+	_, err = second()
+	assert.ErrorIs(t, err, errBatch)
+}
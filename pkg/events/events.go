@@ -0,0 +1,24 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Event - изменение агрегата (например, новости), сохраненное в outbox.
+// Id растет в порядке записи, поэтому по нему получатель может отбрасывать повторы
+type Event struct {
+	Id          int64           `json:"Id"`
+	Aggregate   string          `json:"Aggregate"`
+	AggregateId int64           `json:"AggregateId"`
+	Type        string          `json:"Type"`
+	Payload     json.RawMessage `json:"Payload"`
+	CreatedAt   time.Time       `json:"CreatedAt"`
+}
+
+// Publisher передает события во внешнюю систему. Доставка at-least-once: одно событие может прийти
+// повторно, но события одного агрегата приходят в порядке записи
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
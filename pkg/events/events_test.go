@@ -0,0 +1,40 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMemoryPublisher(t *testing.T) {
+	p := NewMemoryPublisher()
+
+	assert.NoError(t, p.Publish(context.Background(), Event{Id: 1, Aggregate: "news", AggregateId: 10}))
+	assert.NoError(t, p.Publish(context.Background(), Event{Id: 2, Aggregate: "news", AggregateId: 10}))
+
+	events := p.Events()
+	assert.Len(t, events, 2)
+	assert.Equal(t, int64(1), events[0].Id)
+
+	// копия не меняет сохраненные события
+	events[0].Id = 100
+	assert.Equal(t, int64(1), p.Events()[0].Id)
+}
+
+func TestLogPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	p := NewLogPublisher(zerolog.New(&buf))
+
+	err := p.Publish(context.Background(), Event{
+		Id:          1,
+		Aggregate:   "news",
+		AggregateId: 10,
+		Type:        "created",
+		Payload:     json.RawMessage(`{"Id":10}`),
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"level":"info","event_id":1,"aggregate":"news","aggregate_id":10,"type":"created","payload":{"Id":10},"message":"event published"}`, buf.String())
+}
@@ -0,0 +1,26 @@
+package events
+
+import (
+	"context"
+	"github.com/rs/zerolog"
+)
+
+type logPublisher struct {
+	log zerolog.Logger
+}
+
+// NewLogPublisher пишет каждое событие в лог с уровнем info
+func NewLogPublisher(log zerolog.Logger) Publisher {
+	return &logPublisher{log: log}
+}
+
+func (p *logPublisher) Publish(_ context.Context, event Event) error {
+	p.log.Info().
+		Int64("event_id", event.Id).
+		Str("aggregate", event.Aggregate).
+		Int64("aggregate_id", event.AggregateId).
+		Str("type", event.Type).
+		RawJSON("payload", event.Payload).
+		Msg("event published")
+	return nil
+}
@@ -0,0 +1,66 @@
+package feed
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func testFeed() Feed {
+	published := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	return Feed{
+		Id:          "urn:test_news:feed",
+		Title:       "test_news",
+		Description: "latest news",
+		Link:        "http://localhost/api/v2/news",
+		Self:        "http://localhost/feeds/news.rss",
+		Updated:     published.Add(time.Hour),
+		Items: []Item{
+			{
+				Id:        "urn:test_news:news:1",
+				Title:     "Hello & bye",
+				Content:   "<b>content</b>",
+				Link:      "http://localhost/api/v2/news/1",
+				Published: published,
+				Updated:   published.Add(time.Hour),
+			},
+		},
+	}
+}
+
+func TestRSS(t *testing.T) {
+	b, err := RSS(testFeed())
+	assert.NoError(t, err)
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom"><channel>` +
+		`<title>test_news</title><link>http://localhost/api/v2/news</link><description>latest news</description>` +
+		`<atom:link href="http://localhost/feeds/news.rss" rel="self" type="application/rss+xml"></atom:link>` +
+		`<lastBuildDate>Wed, 01 Oct 2025 13:00:00 +0000</lastBuildDate>` +
+		`<item><title>Hello &amp; bye</title><link>http://localhost/api/v2/news/1</link>` +
+		`<description>&lt;b&gt;content&lt;/b&gt;</description>` +
+		`<guid isPermaLink="false">urn:test_news:news:1</guid><pubDate>Wed, 01 Oct 2025 12:00:00 +0000</pubDate></item>` +
+		`</channel></rss>`
+	assert.Equal(t, expect, string(b))
+}
+
+func TestAtom(t *testing.T) {
+	f := testFeed()
+	f.Self = "http://localhost/feeds/news.atom"
+
+	b, err := Atom(f)
+	assert.NoError(t, err)
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<feed xmlns="http://www.w3.org/2005/Atom"><id>urn:test_news:feed</id><title>test_news</title>` +
+		`<updated>2025-10-01T13:00:00Z</updated>` +
+		`<link href="http://localhost/feeds/news.atom" rel="self" type="application/atom+xml"></link>` +
+		`<link href="http://localhost/api/v2/news" rel="alternate"></link>` +
+		`<author><name>test_news</name></author>` +
+		`<entry><id>urn:test_news:news:1</id><title>Hello &amp; bye</title>` +
+		`<updated>2025-10-01T13:00:00Z</updated><published>2025-10-01T12:00:00Z</published>` +
+		`<link href="http://localhost/api/v2/news/1" rel="alternate"></link>` +
+		`<content type="text">&lt;b&gt;content&lt;/b&gt;</content></entry>` +
+		`</feed>`
+	assert.Equal(t, expect, string(b))
+}
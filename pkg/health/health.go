@@ -0,0 +1,116 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOK       = "ok"
+	StatusFail     = "fail"
+	StatusDraining = "draining"
+)
+
+// Check возвращает ошибку, если компонент недоступен; ctx ограничен таймаутом Checker'а
+type Check func(ctx context.Context) error
+
+type ComponentReport struct {
+	Status string `json:"status"`
+	// Optional - отказ компонента не делает сервис неготовым (например, реплика: чтение уйдет в primary)
+	Optional  bool    `json:"optional,omitempty"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentReport `json:"components,omitempty"`
+}
+
+func (r Report) Ready() bool {
+	return r.Status == StatusOK
+}
+
+type component struct {
+	name     string
+	check    Check
+	optional bool
+}
+
+// Checker проверяет готовность сервиса принимать запросы. Компоненты добавляются до запуска сервера
+type Checker struct {
+	timeout    time.Duration
+	components []component
+	draining   atomic.Bool
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+	}
+}
+
+// Add - отказ компонента делает сервис неготовым
+func (c *Checker) Add(name string, check Check) {
+	c.components = append(c.components, component{name: name, check: check})
+}
+
+// AddOptional - компонент попадает в отчет, но на готовность не влияет
+func (c *Checker) AddOptional(name string, check Check) {
+	c.components = append(c.components, component{name: name, check: check, optional: true})
+}
+
+// Drain переводит сервис в неготовность навсегда: балансировщик перестает слать запросы до остановки сервера
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Check запускает проверки всех компонентов параллельно, каждую не дольше timeout
+func (c *Checker) Check(ctx context.Context) Report {
+	if c.draining.Load() {
+		return Report{Status: StatusDraining}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	reports := make([]ComponentReport, len(c.components))
+	var wg sync.WaitGroup
+	for i, comp := range c.components {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reports[i] = run(ctx, comp)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:     StatusOK,
+		Components: make(map[string]ComponentReport, len(c.components)),
+	}
+	for i, comp := range c.components {
+		report.Components[comp.name] = reports[i]
+		if reports[i].Status != StatusOK && !comp.optional {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func run(ctx context.Context, comp component) ComponentReport {
+	start := time.Now()
+	err := comp.check(ctx)
+	report := ComponentReport{
+		Status:    StatusOK,
+		Optional:  comp.optional,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		report.Status = StatusFail
+		report.Error = err.Error()
+	}
+	return report
+}
@@ -0,0 +1,93 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestChecker_Check(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	fail := func(context.Context) error { return errors.New("connection refused") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	testCases := []struct {
+		testName     string
+		setup        func(c *Checker)
+		expectStatus string
+		expectErrors map[string]string
+	}{
+		{
+			testName: "all ok",
+			setup: func(c *Checker) {
+				c.Add("postgres", ok)
+				c.Add("migrations", ok)
+			},
+			expectStatus: StatusOK,
+			expectErrors: map[string]string{"postgres": "", "migrations": ""},
+		},
+		{
+			testName: "required component failed",
+			setup: func(c *Checker) {
+				c.Add("postgres", fail)
+				c.Add("migrations", ok)
+			},
+			expectStatus: StatusFail,
+			expectErrors: map[string]string{"postgres": "connection refused", "migrations": ""},
+		},
+		{
+			testName: "optional component failed",
+			setup: func(c *Checker) {
+				c.Add("postgres", ok)
+				c.AddOptional("replica", fail)
+			},
+			expectStatus: StatusOK,
+			expectErrors: map[string]string{"postgres": "", "replica": "connection refused"},
+		},
+		{
+			testName: "timeout",
+			setup: func(c *Checker) {
+				c.Add("postgres", slow)
+			},
+			expectStatus: StatusFail,
+			expectErrors: map[string]string{"postgres": context.DeadlineExceeded.Error()},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			c := NewChecker(10 * time.Millisecond)
+			tc.setup(c)
+
+			report := c.Check(context.Background())
+			assert.Equal(t, tc.expectStatus, report.Status)
+			assert.Equal(t, tc.expectStatus == StatusOK, report.Ready())
+			assert.Len(t, report.Components, len(tc.expectErrors))
+			for name, expectErr := range tc.expectErrors {
+				assert.Equal(t, expectErr, report.Components[name].Error, name)
+			}
+		})
+	}
+}
+
+func TestChecker_Drain(t *testing.T) {
+	called := false
+	c := NewChecker(time.Second)
+	c.Add("postgres", func(context.Context) error {
+		called = true
+		return nil
+	})
+	assert.True(t, c.Check(context.Background()).Ready())
+
+	called = false
+	c.Drain()
+	report := c.Check(context.Background())
+	assert.Equal(t, StatusDraining, report.Status)
+	assert.False(t, report.Ready())
+	assert.False(t, called)
+}
@@ -0,0 +1,254 @@
+package openapi
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const Version = "3.1.0"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+	Patch  *Operation `json:"patch,omitempty"`
+}
+
+type Operation struct {
+	OperationId string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Route описывает один обработчик; по нему строится Operation
+type Route struct {
+	Method      string
+	Path        string
+	Summary     string
+	Tags        []string
+	Security    []string
+	Query       any
+	Body        any
+	ContentType string
+	Status      int // код успешного ответа, по умолчанию 200
+	Response    any
+	// ResponseType - тип успешного ответа, по умолчанию application/json (text/plain без Response)
+	ResponseType string
+	Errors       []int
+}
+
+var fiberParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// ToOpenAPIPath переводит путь fiber (/news/:id) в формат OpenAPI (/news/{id})
+func ToOpenAPIPath(path string) string {
+	return fiberParam.ReplaceAllString(path, "{$1}")
+}
+
+func NewDocument(info Info) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas: map[string]*Schema{
+				"Error": {Type: "string", Description: "error message or http status text"},
+			},
+			SecuritySchemes: make(map[string]*SecurityScheme),
+		},
+	}
+}
+
+func (d *Document) AddSecurityScheme(name string, scheme *SecurityScheme) {
+	d.Components.SecuritySchemes[name] = scheme
+}
+
+func (d *Document) Add(routes ...Route) {
+	for _, r := range routes {
+		d.add(r)
+	}
+}
+
+func (d *Document) add(r Route) {
+	path := ToOpenAPIPath(r.Path)
+	op := &Operation{
+		OperationId: operationId(r.Method, path),
+		Summary:     r.Summary,
+		Tags:        r.Tags,
+		Responses:   make(map[string]*Response),
+	}
+
+	for _, name := range fiberParam.FindAllStringSubmatch(r.Path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:     name[1],
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "integer", Format: "int64"},
+		})
+	}
+	if r.Query != nil {
+		op.Parameters = append(op.Parameters, d.queryParameters(r.Query)...)
+	}
+	if r.Body != nil {
+		contentType := r.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{
+				contentType: {Schema: d.SchemaOf(r.Body)},
+			},
+		}
+	}
+
+	status := r.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	switch {
+	case r.Response != nil:
+		responseType := r.ResponseType
+		if responseType == "" {
+			responseType = "application/json"
+		}
+		op.Responses[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status),
+			Content: map[string]*MediaType{
+				responseType: {Schema: d.SchemaOf(r.Response)},
+			},
+		}
+	case status == http.StatusNoContent || status == http.StatusSwitchingProtocols:
+		op.Responses[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status),
+		}
+	default:
+		responseType := r.ResponseType
+		if responseType == "" {
+			responseType = "text/plain"
+		}
+		op.Responses[strconv.Itoa(status)] = &Response{
+			Description: http.StatusText(status),
+			Content: map[string]*MediaType{
+				responseType: {Schema: &Schema{Type: "string"}},
+			},
+		}
+	}
+	for _, code := range r.Errors {
+		op.Responses[strconv.Itoa(code)] = &Response{
+			Description: http.StatusText(code),
+			Content: map[string]*MediaType{
+				"text/plain": {Schema: &Schema{Ref: "#/components/schemas/Error"}},
+			},
+		}
+	}
+
+	if len(r.Security) != 0 {
+		// любая из схем подходит
+		for _, name := range r.Security {
+			op.Security = append(op.Security, map[string][]string{name: {}})
+		}
+	}
+
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	switch r.Method {
+	case http.MethodGet:
+		item.Get = op
+	case http.MethodPut:
+		item.Put = op
+	case http.MethodPost:
+		item.Post = op
+	case http.MethodDelete:
+		item.Delete = op
+	case http.MethodPatch:
+		item.Patch = op
+	}
+}
+
+// Operations возвращает пары "METHOD path" всех операций документа
+func (d *Document) Operations() []string {
+	var result []string
+	for path, item := range d.Paths {
+		for method, op := range map[string]*Operation{
+			http.MethodGet:    item.Get,
+			http.MethodPut:    item.Put,
+			http.MethodPost:   item.Post,
+			http.MethodDelete: item.Delete,
+			http.MethodPatch:  item.Patch,
+		} {
+			if op != nil {
+				result = append(result, method+" "+path)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func operationId(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '{' || r == '}' || r == '.' || r == '-' || r == '_'
+	}) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
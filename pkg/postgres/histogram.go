@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultQueryBuckets - границы гистограммы длительности запросов в секундах
+var DefaultQueryBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+type QueryStats struct {
+	Count  uint64
+	Errors uint64
+	// Sum - суммарная длительность в секундах
+	Sum float64
+	// Buckets - накопленное число запросов не дольше Bounds[i] секунд, как в prometheus
+	Bounds  []float64
+	Buckets []uint64
+}
+
+type queryHistogram struct {
+	mu     sync.Mutex
+	bounds []float64
+	ops    map[string]*QueryStats
+}
+
+func newQueryHistogram(bounds []float64) *queryHistogram {
+	bounds = slices.Clone(bounds)
+	slices.Sort(bounds)
+	return &queryHistogram{
+		bounds: bounds,
+		ops:    make(map[string]*QueryStats),
+	}
+}
+
+func (h *queryHistogram) observe(op string, d time.Duration, err error) {
+	seconds := d.Seconds()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.ops[op]
+	if !ok {
+		s = &QueryStats{Bounds: h.bounds, Buckets: make([]uint64, len(h.bounds))}
+		h.ops[op] = s
+	}
+	s.Count++
+	s.Sum += seconds
+	if err != nil {
+		s.Errors++
+	}
+	for i := len(h.bounds) - 1; i >= 0 && seconds <= h.bounds[i]; i-- {
+		s.Buckets[i]++
+	}
+}
+
+func (h *queryHistogram) snapshot() map[string]QueryStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	result := make(map[string]QueryStats, len(h.ops))
+	for op, s := range h.ops {
+		c := *s
+		c.Buckets = slices.Clone(s.Buckets)
+		result[op] = c
+	}
+	return result
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultConnAttempts = 10
+	defaultConnTimeout  = 2 * time.Second
+)
+
+const PoolPrimary = "primary"
+
+// PoolConfig - настройки пулов primary и реплик; нулевые значения - значения pgxpool по умолчанию
+type PoolConfig struct {
+	MinConns          int32
+	MaxConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+	// ConnAttempts попыток подключения к primary с паузой ConnTimeout; ConnTimeout же ограничивает Ping
+	ConnAttempts int
+	ConnTimeout  time.Duration
+	// StatementTimeout - statement_timeout сессии: сервер прерывает запрос, даже если ctx вызова
+	// без дедлайна. Более короткий дедлайн ctx по-прежнему отменяет запрос раньше
+	StatementTimeout time.Duration
+
+	tracer pgx.QueryTracer
+}
+
+func defaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		MaxConns:     int32(runtime.NumCPU()),
+		ConnAttempts: defaultConnAttempts,
+		ConnTimeout:  defaultConnTimeout,
+	}
+}
+
+// WithPoolConfig - нулевые MaxConns, ConnAttempts и ConnTimeout остаются значениями по умолчанию
+func WithPoolConfig(cfg PoolConfig) Option {
+	return func(p *postgres) {
+		def := defaultPoolConfig()
+		if cfg.MaxConns <= 0 {
+			cfg.MaxConns = def.MaxConns
+		}
+		if cfg.ConnAttempts <= 0 {
+			cfg.ConnAttempts = def.ConnAttempts
+		}
+		if cfg.ConnTimeout <= 0 {
+			cfg.ConnTimeout = def.ConnTimeout
+		}
+		p.poolCfg = cfg
+	}
+}
+
+func (c PoolConfig) parse(url string) (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	poolConfig.MaxConns = c.MaxConns
+	poolConfig.MinConns = min(c.MinConns, c.MaxConns)
+	if c.MaxConnLifetime > 0 {
+		poolConfig.MaxConnLifetime = c.MaxConnLifetime
+	}
+	if c.MaxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = c.MaxConnIdleTime
+	}
+	if c.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = c.HealthCheckPeriod
+	}
+	if c.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10)
+	}
+	if c.tracer != nil {
+		poolConfig.ConnConfig.Tracer = c.tracer
+	}
+	return poolConfig, nil
+}
+
+type PoolStat struct {
+	// Name - PoolPrimary или host:port реплики
+	Name    string
+	Healthy bool
+	Stat    *pgxpool.Stat
+}
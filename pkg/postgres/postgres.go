@@ -7,59 +7,87 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"log"
-	"runtime"
 	"time"
 )
 
-const (
-	defaultConnAttempts = 10
-	defaultConnTimeout  = 2 * time.Second
-)
-
 type Postgres interface {
 	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 	Begin(ctx context.Context) (pgx.Tx, error)
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
 	Close()
 	GetPool() *pgxpool.Pool
+	// GetReplicaPool возвращает здоровую реплику по кругу, если реплик нет или все нездоровы - primary
+	GetReplicaPool() *pgxpool.Pool
+	// Stats - состояние пулов primary и реплик для метрик
+	Stats() []PoolStat
 }
 
 type postgres struct {
 	*pgxpool.Pool
-	maxPoolSize  int
-	connAttempts int
-	connTimeout  time.Duration
+	poolCfg    PoolConfig
+	tracer     pgx.QueryTracer
+	replicaCfg ReplicaConfig
+	replicas   *replicaSet
 }
 
-func NewPG(url string) (Postgres, error) {
+func NewPG(url string, opts ...Option) (Postgres, error) {
 	pg := &postgres{
-		maxPoolSize:  runtime.NumCPU(),
-		connAttempts: defaultConnAttempts,
-		connTimeout:  defaultConnTimeout,
+		poolCfg: defaultPoolConfig(),
+	}
+	for _, opt := range opts {
+		opt(pg)
 	}
 
-	poolConfig, err := pgxpool.ParseConfig(url)
+	pg.poolCfg.tracer = pg.tracer
+	poolConfig, err := pg.poolCfg.parse(url)
 	if err != nil {
 		return nil, err
 	}
-	poolConfig.MaxConns = int32(pg.maxPoolSize)
-	for pg.connAttempts > 0 {
-		pg.Pool, err = pgxpool.NewWithConfig(context.Background(), poolConfig)
-		if err == nil {
-			break
-		}
-		log.Printf("Postgres trying to connect, attemps left: %d", pg.connAttempts)
-		time.Sleep(pg.connTimeout)
-		pg.connAttempts--
-	}
+	pg.Pool, err = pg.poolCfg.connect(poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("error connect to postgres, %w", err)
 	}
+
+	if len(pg.replicaCfg.URLs) != 0 {
+		pg.replicas, err = newReplicaSet(pg.replicaCfg, pg.poolCfg)
+		if err != nil {
+			pg.Pool.Close()
+			return nil, fmt.Errorf("error connect to postgres replicas, %w", err)
+		}
+	}
 	return pg, err
 }
 
+// connect создает пул и проверяет соединение: пул подключается лениво, без Ping недоступная БД
+// обнаружилась бы только на первом запросе
+func (c PoolConfig) connect(poolConfig *pgxpool.Config) (*pgxpool.Pool, error) {
+	var err error
+	for attempts := c.ConnAttempts; attempts > 0; attempts-- {
+		var pool *pgxpool.Pool
+		pool, err = pgxpool.NewWithConfig(context.Background(), poolConfig)
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), c.ConnTimeout)
+			err = pool.Ping(ctx)
+			cancel()
+			if err == nil {
+				return pool, nil
+			}
+			pool.Close()
+		}
+		if attempts > 1 {
+			log.Printf("Postgres trying to connect, attemps left: %d", attempts-1)
+			time.Sleep(c.ConnTimeout)
+		}
+	}
+	return nil, err
+}
+
 func (p *postgres) Close() {
+	if p.replicas != nil {
+		p.replicas.close()
+	}
 	if p.Pool != nil {
 		p.Pool.Close()
 	}
@@ -68,3 +96,20 @@ func (p *postgres) Close() {
 func (p *postgres) GetPool() *pgxpool.Pool {
 	return p.Pool
 }
+
+func (p *postgres) GetReplicaPool() *pgxpool.Pool {
+	if p.replicas != nil {
+		if pool := p.replicas.pick(); pool != nil {
+			return pool
+		}
+	}
+	return p.Pool
+}
+
+func (p *postgres) Stats() []PoolStat {
+	stats := []PoolStat{{Name: PoolPrimary, Healthy: true, Stat: p.Pool.Stat()}}
+	if p.replicas != nil {
+		stats = append(stats, p.replicas.stats()...)
+	}
+	return stats
+}
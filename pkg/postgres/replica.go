@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultReplicaMaxLag        = 5 * time.Second
+	defaultReplicaCheckInterval = 5 * time.Second
+)
+
+// отставание считается нулевым, если реплика применила все полученные WAL: иначе на простаивающем
+// primary pg_last_xact_replay_timestamp стареет и здоровая реплика выглядела бы отставшей
+const replicaLagSQL = `
+	select case
+	           when pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() then 0
+	           else coalesce(extract(epoch from now() - pg_last_xact_replay_timestamp()), 0)
+	       end`
+
+type ReplicaConfig struct {
+	URLs []string
+	// MaxLag - реплика с большим отставанием не получает запросов до следующей проверки
+	MaxLag        time.Duration
+	CheckInterval time.Duration
+}
+
+type Option func(p *postgres)
+
+// WithReplicas добавляет пулы реплик для чтения; пока ни одна реплика не здорова, чтение идет в primary
+func WithReplicas(cfg ReplicaConfig) Option {
+	return func(p *postgres) {
+		if cfg.MaxLag <= 0 {
+			cfg.MaxLag = defaultReplicaMaxLag
+		}
+		if cfg.CheckInterval <= 0 {
+			cfg.CheckInterval = defaultReplicaCheckInterval
+		}
+		cfg.URLs = slices.DeleteFunc(slices.Clone(cfg.URLs), func(url string) bool {
+			return strings.TrimSpace(url) == ""
+		})
+		p.replicaCfg = cfg
+	}
+}
+
+type replica struct {
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+type replicaSet struct {
+	replicas []*replica
+	next     atomic.Uint64
+	maxLag   time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newReplicaSet(cfg ReplicaConfig, poolCfg PoolConfig) (*replicaSet, error) {
+	s := &replicaSet{
+		maxLag: cfg.MaxLag,
+		stop:   make(chan struct{}),
+	}
+	for _, url := range cfg.URLs {
+		poolConfig, err := poolCfg.parse(url)
+		if err != nil {
+			s.close()
+			return nil, err
+		}
+		// реплика может быть недоступна при старте - ее подключит проверка здоровья
+		pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+		if err != nil {
+			s.close()
+			return nil, err
+		}
+		s.replicas = append(s.replicas, &replica{pool: pool})
+	}
+
+	s.checkAll(cfg.CheckInterval)
+	s.wg.Add(1)
+	go s.run(cfg.CheckInterval)
+	return s, nil
+}
+
+// pick - round-robin по здоровым репликам; nil, если здоровых нет
+func (s *replicaSet) pick() *pgxpool.Pool {
+	n := len(s.replicas)
+	start := s.next.Add(1)
+	for i := range n {
+		r := s.replicas[(start+uint64(i))%uint64(n)]
+		if r.healthy.Load() {
+			return r.pool
+		}
+	}
+	return nil
+}
+
+func (s *replicaSet) run(interval time.Duration) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.checkAll(interval)
+		}
+	}
+}
+
+func (s *replicaSet) checkAll(timeout time.Duration) {
+	for _, r := range s.replicas {
+		ctx, cancel := context.WithTimeout(WithOperation(context.Background(), "postgres.replicaLag"), timeout)
+		var lag float64
+		err := r.pool.QueryRow(ctx, replicaLagSQL).Scan(&lag)
+		cancel()
+
+		healthy := s.healthy(lag, err)
+		if r.healthy.Swap(healthy) != healthy {
+			log.Printf("Postgres replica %s healthy: %t, lag: %.1fs, error: %v", r.name(), healthy, lag, err)
+		}
+	}
+}
+
+func (r *replica) name() string {
+	cfg := r.pool.Config().ConnConfig
+	return net.JoinHostPort(cfg.Host, strconv.Itoa(int(cfg.Port)))
+}
+
+func (s *replicaSet) stats() []PoolStat {
+	stats := make([]PoolStat, 0, len(s.replicas))
+	for _, r := range s.replicas {
+		stats = append(stats, PoolStat{Name: r.name(), Healthy: r.healthy.Load(), Stat: r.pool.Stat()})
+	}
+	return stats
+}
+
+func (s *replicaSet) healthy(lagSeconds float64, err error) bool {
+	return err == nil && time.Duration(lagSeconds*float64(time.Second)) <= s.maxLag
+}
+
+func (s *replicaSet) close() {
+	close(s.stop)
+	s.wg.Wait()
+	for _, r := range s.replicas {
+		r.pool.Close()
+	}
+}
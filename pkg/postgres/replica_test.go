@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"errors"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newTestReplicaSet(healthy ...bool) (*replicaSet, []*pgxpool.Pool) {
+	s := &replicaSet{maxLag: time.Second}
+	pools := make([]*pgxpool.Pool, len(healthy))
+	for i, h := range healthy {
+		pools[i] = &pgxpool.Pool{}
+		r := &replica{pool: pools[i]}
+		r.healthy.Store(h)
+		s.replicas = append(s.replicas, r)
+	}
+	return s, pools
+}
+
+func TestReplicaSet_pick(t *testing.T) {
+	t.Run("round robin", func(t *testing.T) {
+		s, pools := newTestReplicaSet(true, true, true)
+		seen := make(map[*pgxpool.Pool]int)
+		for range 30 {
+			seen[s.pick()]++
+		}
+		for _, p := range pools {
+			assert.Equal(t, 10, seen[p])
+		}
+	})
+
+	t.Run("skip unhealthy", func(t *testing.T) {
+		s, pools := newTestReplicaSet(false, true, false)
+		for range 10 {
+			assert.Same(t, pools[1], s.pick())
+		}
+	})
+
+	t.Run("no healthy", func(t *testing.T) {
+		s, _ := newTestReplicaSet(false, false)
+		assert.Nil(t, s.pick())
+	})
+}
+
+func TestReplicaSet_healthy(t *testing.T) {
+	s := &replicaSet{maxLag: 5 * time.Second}
+
+	assert.True(t, s.healthy(0, nil))
+	assert.True(t, s.healthy(5, nil))
+	assert.False(t, s.healthy(5.5, nil))
+	assert.False(t, s.healthy(0, errors.New("connection refused")))
+}
+
+func TestWithReplicas(t *testing.T) {
+	p := &postgres{}
+	WithReplicas(ReplicaConfig{URLs: []string{"", "postgres://replica:5432/db", " "}})(p)
+
+	assert.Equal(t, []string{"postgres://replica:5432/db"}, p.replicaCfg.URLs)
+	assert.Equal(t, defaultReplicaMaxLag, p.replicaCfg.MaxLag)
+	assert.Equal(t, defaultReplicaCheckInterval, p.replicaCfg.CheckInterval)
+}
@@ -0,0 +1,205 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	unknownOperation = "unknown"
+	otelTracerName   = "test_news/pkg/postgres"
+)
+
+// пакеты, чьи кадры стека пропускаются при поиске операции: pgx, runtime и сам postgres
+var tracerSkipPackages = []string{"github.com/jackc/", "runtime.", reflect.TypeOf(QueryTracer{}).PkgPath() + "."}
+
+type TracerConfig struct {
+	// SlowThreshold - запросы дольше пишутся в лог с уровнем warn; 0 - не писать
+	SlowThreshold time.Duration
+	// SkipPackages - префиксы пакетов-оберток над пулом (например, txmanager), которые не считаются операцией
+	SkipPackages []string
+	// Buckets - границы гистограммы в секундах; пусто - DefaultQueryBuckets
+	Buckets []float64
+}
+
+// QueryTracer пишет медленные запросы в лог (логгером из ctx, если он там есть), считает гистограмму
+// длительности по операциям и создает OpenTelemetry span на запрос, если в ctx уже есть span
+// (фоновые запросы без родителя трасс не порождают).
+// Операция - функция, вызвавшая пул (например, repo.newsRepo.FindById), или имя из WithOperation
+type QueryTracer struct {
+	logger    zerolog.Logger
+	cfg       TracerConfig
+	histogram *queryHistogram
+}
+
+func NewQueryTracer(logger zerolog.Logger, cfg TracerConfig) *QueryTracer {
+	if len(cfg.Buckets) == 0 {
+		cfg.Buckets = DefaultQueryBuckets
+	}
+	cfg.SkipPackages = append(cfg.SkipPackages, tracerSkipPackages...)
+	return &QueryTracer{
+		logger:    logger,
+		cfg:       cfg,
+		histogram: newQueryHistogram(cfg.Buckets),
+	}
+}
+
+// WithTracer подключает tracer к пулам primary и реплик
+func WithTracer(tracer pgx.QueryTracer) Option {
+	return func(p *postgres) {
+		p.tracer = tracer
+	}
+}
+
+type operationKey struct{}
+
+// WithOperation задает имя операции для запросов с этим ctx вместо имени вызывающей функции
+func WithOperation(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationKey{}, name)
+}
+
+type traceKey struct{}
+
+type traceData struct {
+	operation string
+	sql       string
+	args      []any
+	start     time.Time
+	span      trace.Span
+}
+
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	op, ok := ctx.Value(operationKey{}).(string)
+	if !ok {
+		op = t.callerOperation()
+	}
+	td := &traceData{
+		operation: op,
+		sql:       data.SQL,
+		args:      data.Args,
+	}
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		ctx, td.span = otel.Tracer(otelTracerName).Start(ctx, op,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNamePostgreSQL,
+				semconv.DBOperationName(sqlOperation(data.SQL)),
+				semconv.DBQueryText(compactSQL(data.SQL)),
+			),
+		)
+	}
+	td.start = time.Now()
+	return context.WithValue(ctx, traceKey{}, td)
+}
+
+// для Query вызывается при закрытии rows, поэтому длительность включает чтение строк
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	td, ok := ctx.Value(traceKey{}).(*traceData)
+	if !ok {
+		return
+	}
+	duration := time.Since(td.start)
+	t.histogram.observe(td.operation, duration, data.Err)
+	if td.span != nil {
+		if data.Err != nil {
+			td.span.RecordError(data.Err)
+			td.span.SetStatus(codes.Error, data.Err.Error())
+		}
+		td.span.End()
+	}
+
+	if t.cfg.SlowThreshold <= 0 || duration < t.cfg.SlowThreshold {
+		return
+	}
+	t.ctxLogger(ctx).Warn().
+		Err(data.Err).
+		Str("operation", td.operation).
+		Dur("duration", duration).
+		Int64("rows", data.CommandTag.RowsAffected()).
+		Str("sql", compactSQL(td.sql)).
+		Strs("args", redactArgs(td.args)).
+		Msg("slow query")
+}
+
+// ctxLogger - логгер из ctx запроса (zerolog.Ctx), например с request_id http запроса; без него - logger tracer'а
+func (t *QueryTracer) ctxLogger(ctx context.Context) *zerolog.Logger {
+	if l := zerolog.Ctx(ctx); l != zerolog.DefaultContextLogger && l.GetLevel() != zerolog.Disabled {
+		return l
+	}
+	return &t.logger
+}
+
+// Snapshot - гистограммы по операциям на текущий момент
+func (t *QueryTracer) Snapshot() map[string]QueryStats {
+	return t.histogram.snapshot()
+}
+
+// callerOperation - первая функция в стеке вне pgx и оберток; runtime.Callers стоит единицы микросекунд,
+// что незаметно на фоне запроса в БД
+func (t *QueryTracer) callerOperation() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !t.skip(frame.Function) {
+			return operationName(frame.Function)
+		}
+		if !more {
+			return unknownOperation
+		}
+	}
+}
+
+func (t *QueryTracer) skip(function string) bool {
+	for _, prefix := range t.cfg.SkipPackages {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// test_news/internal/repo.(*newsRepo).FindById -> repo.newsRepo.FindById;
+// замыкания (FindById.func1) относятся к своей функции
+func operationName(function string) string {
+	if i := strings.LastIndexByte(function, '/'); i >= 0 {
+		function = function[i+1:]
+	}
+	function = strings.NewReplacer("(*", "", ")", "").Replace(function)
+	if i := strings.Index(function, ".func"); i >= 0 {
+		function = function[:i]
+	}
+	return function
+}
+
+// sqlOperation - первое слово запроса в верхнем регистре: SELECT, INSERT, BEGIN
+func sqlOperation(sql string) string {
+	if fields := strings.Fields(sql); len(fields) > 0 {
+		return strings.ToUpper(fields[0])
+	}
+	return ""
+}
+
+func compactSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
+
+// значения аргументов не пишутся в лог: в них могут быть секреты и персональные данные, только типы
+func redactArgs(args []any) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		redacted[i] = fmt.Sprintf("%T", arg)
+	}
+	return redacted
+}
@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+	"time"
+)
+
+func TestOperationName(t *testing.T) {
+	testCases := []struct {
+		function string
+		expect   string
+	}{
+		{"test_news/internal/repo.(*newsRepo).FindById", "repo.newsRepo.FindById"},
+		{"test_news/internal/repo.(*newsRepo).StreamSitemap.func1", "repo.newsRepo.StreamSitemap"},
+		{"test_news/pkg/ratelimit.(*postgresStore).Take", "ratelimit.postgresStore.Take"},
+		{"main.main", "main.main"},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expect, operationName(tc.function))
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	assert.Equal(t, []string{"string", "int64", "<nil>", "[]int64"}, redactArgs([]any{"secret", int64(1), nil, []int64{1}}))
+}
+
+func TestQueryTracer_callerOperation(t *testing.T) {
+	tracer := NewQueryTracer(zerolog.Nop(), TracerConfig{})
+	// кадры самого пакета postgres пропускаются, первым остается testing
+	assert.Equal(t, "testing.tRunner", tracer.callerOperation())
+
+	tracer = NewQueryTracer(zerolog.Nop(), TracerConfig{SkipPackages: []string{"testing."}})
+	assert.Equal(t, unknownOperation, tracer.callerOperation())
+}
+
+func TestQueryTracer(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewQueryTracer(zerolog.New(&buf), TracerConfig{
+		SlowThreshold: 10 * time.Millisecond,
+		Buckets:       []float64{0.001, 1},
+	})
+
+	query := func(op string, d time.Duration, err error) {
+		ctx := tracer.TraceQueryStart(WithOperation(context.Background(), op), nil, pgx.TraceQueryStartData{
+			SQL:  "SELECT id\n\tFROM news\n\tWHERE title = $1",
+			Args: []any{"secret title"},
+		})
+		ctx.Value(traceKey{}).(*traceData).start = time.Now().Add(-d)
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 3"), Err: err})
+	}
+
+	query("repo.newsRepo.Find", 0, nil)
+	assert.Empty(t, buf.String())
+
+	query("repo.newsRepo.Find", 20*time.Millisecond, errors.New("some error"))
+	query("repo.newsRepo.FindById", 2*time.Second, nil)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+	assert.NotContains(t, buf.String(), "secret title")
+
+	var entry map[string]any
+	if err := json.Unmarshal(lines[0], &entry); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "warn", entry["level"])
+	assert.Equal(t, "slow query", entry["message"])
+	assert.Equal(t, "repo.newsRepo.Find", entry["operation"])
+	assert.Equal(t, "SELECT id FROM news WHERE title = $1", entry["sql"])
+	assert.Equal(t, []any{"string"}, entry["args"])
+	assert.Equal(t, float64(3), entry["rows"])
+	assert.Equal(t, "some error", entry["error"])
+
+	stats := tracer.Snapshot()
+	find := stats["repo.newsRepo.Find"]
+	assert.Equal(t, uint64(2), find.Count)
+	assert.Equal(t, uint64(1), find.Errors)
+	assert.Equal(t, []float64{0.001, 1}, find.Bounds)
+	assert.Equal(t, []uint64{1, 2}, find.Buckets)
+
+	byId := stats["repo.newsRepo.FindById"]
+	assert.Equal(t, uint64(1), byId.Count)
+	assert.Equal(t, []uint64{0, 0}, byId.Buckets)
+	assert.InDelta(t, 2, byId.Sum, 0.1)
+}
+
+func TestQueryTracer_slowLogDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewQueryTracer(zerolog.New(&buf), TracerConfig{})
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	ctx.Value(traceKey{}).(*traceData).start = time.Now().Add(-time.Hour)
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	assert.Empty(t, buf.String())
+	assert.Equal(t, uint64(1), tracer.Snapshot()["testing.tRunner"].Count)
+}
+
+func TestQueryTracer_span(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	tracer := NewQueryTracer(zerolog.Nop(), TracerConfig{})
+	query := func(ctx context.Context, err error) {
+		ctx = tracer.TraceQueryStart(WithOperation(ctx, "repo.newsRepo.Create"), nil, pgx.TraceQueryStartData{
+			SQL: "insert into news (title)\n\tvalues ($1)",
+		})
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: err})
+	}
+
+	// фоновый запрос без родительского span'а трассу не начинает
+	query(context.Background(), nil)
+	assert.Empty(t, exporter.GetSpans())
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "service.news.Create")
+	query(ctx, nil)
+	query(ctx, errors.New("some error"))
+	parent.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	for _, s := range spans[:2] {
+		assert.Equal(t, "repo.newsRepo.Create", s.Name)
+		assert.Equal(t, trace.SpanKindClient, s.SpanKind)
+		assert.Equal(t, parent.SpanContext().SpanID(), s.Parent.SpanID())
+		assert.Contains(t, s.Attributes, semconv.DBSystemNamePostgreSQL)
+		assert.Contains(t, s.Attributes, semconv.DBOperationName("INSERT"))
+		assert.Contains(t, s.Attributes, semconv.DBQueryText("insert into news (title) values ($1)"))
+	}
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+	assert.Equal(t, codes.Error, spans[1].Status.Code)
+	assert.Equal(t, "some error", spans[1].Status.Description)
+}
+
+func TestQueryTracer_ctxLogger(t *testing.T) {
+	var tracerBuf, ctxBuf bytes.Buffer
+	tracer := NewQueryTracer(zerolog.New(&tracerBuf), TracerConfig{SlowThreshold: time.Millisecond})
+
+	slow := func(ctx context.Context) {
+		ctx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+		ctx.Value(traceKey{}).(*traceData).start = time.Now().Add(-time.Second)
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	}
+
+	slow(context.Background())
+	ctxLogger := zerolog.New(&ctxBuf).With().Str("request_id", "req-1").Logger()
+	slow(ctxLogger.WithContext(context.Background()))
+
+	assert.Equal(t, 1, bytes.Count(tracerBuf.Bytes(), []byte("\n")))
+	assert.NotContains(t, tracerBuf.String(), "req-1")
+	assert.Contains(t, ctxBuf.String(), `"request_id":"req-1"`)
+	assert.Contains(t, ctxBuf.String(), "slow query")
+}
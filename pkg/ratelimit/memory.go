@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultSweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	limit   Limit
+	updated time.Time
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() Store {
+	return &memoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (s *memoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst)}
+		s.buckets[key] = b
+	} else {
+		b.tokens = refill(b.tokens, limit, now.Sub(b.updated))
+	}
+	b.limit = limit
+	b.updated = now
+
+	if b.tokens < 1 {
+		return newResult(limit, b.tokens, false), nil
+	}
+	b.tokens--
+	return newResult(limit, b.tokens, true), nil
+}
+
+// полные бакеты ничем не отличаются от отсутствующих, поэтому их можно удалять
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < defaultSweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if refill(b.tokens, b.limit, now.Sub(b.updated)) >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func refill(tokens float64, limit Limit, elapsed time.Duration) float64 {
+	return min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.Rate)
+}
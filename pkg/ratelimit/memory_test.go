@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &memoryStore{
+		buckets: make(map[string]*bucket),
+		now:     func() time.Time { return now },
+	}
+	limit := Limit{Rate: 1, Burst: 2}
+
+	testCases := []struct {
+		testName     string
+		key          string
+		elapsed      time.Duration
+		expectOutput Result
+	}{
+		{
+			testName: "first request",
+			key:      "client",
+			expectOutput: Result{
+				Allowed:   true,
+				Limit:     2,
+				Remaining: 1,
+				Reset:     time.Second,
+			},
+		},
+		{
+			testName: "second request",
+			key:      "client",
+			expectOutput: Result{
+				Allowed:   true,
+				Limit:     2,
+				Remaining: 0,
+				Reset:     2 * time.Second,
+			},
+		},
+		{
+			testName: "bucket is empty",
+			key:      "client",
+			expectOutput: Result{
+				Allowed:    false,
+				Limit:      2,
+				Remaining:  0,
+				Reset:      2 * time.Second,
+				RetryAfter: time.Second,
+			},
+		},
+		{
+			testName: "another client",
+			key:      "another client",
+			expectOutput: Result{
+				Allowed:   true,
+				Limit:     2,
+				Remaining: 1,
+				Reset:     time.Second,
+			},
+		},
+		{
+			testName: "bucket refilled",
+			key:      "client",
+			elapsed:  1500 * time.Millisecond,
+			expectOutput: Result{
+				Allowed:   true,
+				Limit:     2,
+				Remaining: 0,
+				Reset:     1500 * time.Millisecond,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.testName, func(t *testing.T) {
+			now = now.Add(tc.elapsed)
+
+			actual, err := s.Take(context.Background(), tc.key, limit)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectOutput, actual)
+		})
+	}
+}
+
+func TestMemoryStore_sweep(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &memoryStore{
+		buckets: make(map[string]*bucket),
+		now:     func() time.Time { return now },
+	}
+	limit := Limit{Rate: 1, Burst: 2}
+
+	_, _ = s.Take(context.Background(), "client", limit)
+	assert.Len(t, s.buckets, 1)
+
+	now = now.Add(defaultSweepInterval)
+	_, _ = s.Take(context.Background(), "another client", limit)
+
+	assert.Len(t, s.buckets, 1)
+	assert.Contains(t, s.buckets, "another client")
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"test_news/pkg/postgres"
+	"time"
+)
+
+const defaultPGSweepInterval = 10 * time.Minute
+
+const refillExpr = "LEAST($2::float8, rl.tokens + EXTRACT(EPOCH FROM now() - rl.updated_at) * $3::float8)"
+
+// бакет пересчитывается и списывается одним upsert'ом, поэтому реплики делят счетчики без гонок
+var takeSQL = fmt.Sprintf(`
+	INSERT INTO rate_limits AS rl (key, tokens, allowed, updated_at)
+	VALUES ($1, $2::float8 - 1, true, now())
+	ON CONFLICT (key) DO UPDATE
+	SET tokens     = CASE WHEN %[1]s >= 1 THEN %[1]s - 1 ELSE %[1]s END,
+	    allowed    = %[1]s >= 1,
+	    updated_at = now()
+	RETURNING tokens, allowed
+`, refillExpr)
+
+type postgresStore struct {
+	pg postgres.Postgres
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewPostgresStore(pg postgres.Postgres) Store {
+	return &postgresStore{
+		pg: pg,
+	}
+}
+
+func (s *postgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	const op = "ratelimit.postgres.Take"
+
+	if err := s.sweep(ctx); err != nil {
+		return Result{}, fmt.Errorf("%s sweep error: %w", op, err)
+	}
+
+	var (
+		tokens  float64
+		allowed bool
+	)
+	if err := s.pg.QueryRow(ctx, takeSQL, key, float64(limit.Burst), limit.Rate).Scan(&tokens, &allowed); err != nil {
+		return Result{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return newResult(limit, tokens, allowed), nil
+}
+
+func (s *postgresStore) sweep(ctx context.Context) error {
+	s.mu.Lock()
+	if time.Since(s.lastSweep) < defaultPGSweepInterval {
+		s.mu.Unlock()
+		return nil
+	}
+	s.lastSweep = time.Now()
+	s.mu.Unlock()
+
+	sql := "DELETE FROM rate_limits WHERE updated_at < now() - interval '1 hour'"
+	_, err := s.pg.Exec(ctx, sql)
+	return err
+}
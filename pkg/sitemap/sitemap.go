@@ -0,0 +1,88 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"time"
+)
+
+const (
+	// MaxURLs - ограничение протокола sitemaps.org на число url в одном файле (и файлов в индексе)
+	MaxURLs = 50000
+
+	MIMEXML = "application/xml; charset=utf-8"
+
+	namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+)
+
+var ErrTooManyURLs = errors.New("sitemap: too many urls")
+
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type entry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Writer пишет sitemap по одному url, не держа весь документ в памяти
+type Writer struct {
+	enc  *xml.Encoder
+	item string
+	root xml.StartElement
+	n    int
+}
+
+// NewURLSet начинает sitemap со списком страниц (<urlset>)
+func NewURLSet(w io.Writer) (*Writer, error) {
+	return newWriter(w, "urlset", "url")
+}
+
+// NewIndex начинает индекс sitemap'ов (<sitemapindex>)
+func NewIndex(w io.Writer) (*Writer, error) {
+	return newWriter(w, "sitemapindex", "sitemap")
+}
+
+func newWriter(w io.Writer, root, item string) (*Writer, error) {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return nil, err
+	}
+	sw := &Writer{
+		enc:  xml.NewEncoder(w),
+		item: item,
+		root: xml.StartElement{
+			Name: xml.Name{Local: root},
+			Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: namespace}},
+		},
+	}
+	if err := sw.enc.EncodeToken(sw.root); err != nil {
+		return nil, err
+	}
+	return sw, nil
+}
+
+func (w *Writer) Add(u URL) error {
+	if w.n >= MaxURLs {
+		return ErrTooManyURLs
+	}
+	e := entry{Loc: u.Loc}
+	if !u.LastMod.IsZero() {
+		e.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+	}
+	if err := w.enc.EncodeElement(e, xml.StartElement{Name: xml.Name{Local: w.item}}); err != nil {
+		return err
+	}
+	w.n++
+	return nil
+}
+
+// Close закрывает корневой элемент и сбрасывает буфер энкодера
+func (w *Writer) Close() error {
+	if err := w.enc.EncodeToken(w.root.End()); err != nil {
+		return err
+	}
+	return w.enc.Flush()
+}
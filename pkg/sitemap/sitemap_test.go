@@ -0,0 +1,51 @@
+package sitemap
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestURLSet(t *testing.T) {
+	var b bytes.Buffer
+
+	w, err := NewURLSet(&b)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Add(URL{
+		Loc:     "http://localhost/api/v2/news/1?a=1&b=2",
+		LastMod: time.Date(2025, 10, 1, 15, 0, 0, 0, time.FixedZone("MSK", 3*60*60)),
+	}))
+	assert.NoError(t, w.Add(URL{Loc: "http://localhost/api/v2/news/2"}))
+	assert.NoError(t, w.Close())
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">` +
+		`<url><loc>http://localhost/api/v2/news/1?a=1&amp;b=2</loc><lastmod>2025-10-01T12:00:00Z</lastmod></url>` +
+		`<url><loc>http://localhost/api/v2/news/2</loc></url>` +
+		`</urlset>`
+	assert.Equal(t, expect, b.String())
+}
+
+func TestIndex(t *testing.T) {
+	var b bytes.Buffer
+
+	w, err := NewIndex(&b)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+
+	expect := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"></sitemapindex>`
+	assert.Equal(t, expect, b.String())
+}
+
+func TestWriter_tooManyURLs(t *testing.T) {
+	w, err := NewURLSet(io.Discard)
+	assert.NoError(t, err)
+
+	for i := 0; i < MaxURLs; i++ {
+		assert.NoError(t, w.Add(URL{Loc: "http://localhost/"}))
+	}
+	assert.ErrorIs(t, w.Add(URL{Loc: "http://localhost/"}), ErrTooManyURLs)
+}